@@ -0,0 +1,198 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	// Package imports
+	httpresponse "github.com/mutablelogic/go-server/pkg/httpresponse"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// BatchRequest is a single request within a batch. The response is decoded
+// into Out, and the options apply to this request only.
+type BatchRequest struct {
+	Payload Payload
+	Out     any
+	Opts    []RequestOpt
+}
+
+// BatchResult is the result of a single request within a batch
+type BatchResult struct {
+	Out any   // The Out value of the request
+	Err error // The error returned by the request, or nil on success
+}
+
+// BatchOpt is an option which changes how a batch is executed
+type BatchOpt func(*batchOpts) error
+
+type batchOpts struct {
+	workers  int     // OptBatchWorkers
+	rate     float32 // OptBatchRateLimit
+	failFast bool    // OptBatchFailFast
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// GLOBALS
+
+const (
+	DefaultBatchWorkers = 10
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// OptBatchWorkers sets the maximum number of requests in flight at once
+func OptBatchWorkers(n int) BatchOpt {
+	return func(o *batchOpts) error {
+		if n < 1 {
+			return httpresponse.ErrBadRequest.Withf("OptBatchWorkers: %d", n)
+		}
+		o.workers = n
+		return nil
+	}
+}
+
+// OptBatchRateLimit sets the limit on the number of requests started per
+// second, shared by all workers in the batch. This is in addition to any
+// rate limit set on the client with OptRateLimit.
+func OptBatchRateLimit(value float32) BatchOpt {
+	return func(o *batchOpts) error {
+		if value < 0.0 {
+			return httpresponse.ErrBadRequest.With("OptBatchRateLimit")
+		}
+		o.rate = value
+		return nil
+	}
+}
+
+// OptBatchFailFast stops the batch on the first error. Requests in flight are
+// cancelled, and requests which have not started are not made.
+func OptBatchFailFast() BatchOpt {
+	return func(o *batchOpts) error {
+		o.failFast = true
+		return nil
+	}
+}
+
+// Batch makes the requests concurrently with a bounded number of workers, and
+// returns a result for each request in the same order as the requests.
+//
+// By default every request is made, and the returned error joins the errors of
+// all failed requests. With OptBatchFailFast the first error is returned, and
+// any request which was cancelled or not started has the context error as its
+// result.
+func (client *Client) Batch(ctx context.Context, requests []BatchRequest, opts ...BatchOpt) ([]BatchResult, error) {
+	o := batchOpts{workers: DefaultBatchWorkers}
+	for _, opt := range opts {
+		if err := opt(&o); err != nil {
+			return nil, err
+		}
+	}
+
+	// Set the outputs in request order
+	results := make([]BatchResult, len(requests))
+	for i, req := range requests {
+		results[i].Out = req.Out
+	}
+
+	// Cancel outstanding requests on the first error when failing fast
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	// Start the workers, which each write only their own results
+	var wg sync.WaitGroup
+	var once sync.Once
+	var first error
+	queue := make(chan int)
+	for range min(o.workers, len(requests)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				req := requests[i]
+				err := client.DoWithContext(ctx, req.Payload, req.Out, req.Opts...)
+				results[i].Err = err
+				if err != nil && o.failFast {
+					once.Do(func() {
+						first = fmt.Errorf("batch[%d]: %w", i, err)
+						cancel(first)
+					})
+				}
+			}
+		}()
+	}
+
+	// Dispatch the requests in order, at the rate limit if set
+	var next int
+	var ts time.Time
+dispatch:
+	for ; next < len(requests) && ctx.Err() == nil; next++ {
+		if o.rate > 0 && next > 0 {
+			if !sleep(ctx, time.Until(ts.Add(time.Duration(float32(time.Second)/o.rate)))) {
+				break
+			}
+		}
+		ts = time.Now()
+		select {
+		case <-ctx.Done():
+			break dispatch
+		case queue <- next:
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	// Close any streaming payloads which were not sent
+	for _, req := range requests[next:] {
+		if closer, ok := req.Payload.(io.Closer); ok {
+			closer.Close()
+		}
+	}
+
+	// Requests which did not start fail with the context error
+	for i := next; i < len(requests); i++ {
+		results[i].Err = ctx.Err()
+	}
+
+	// Return the results
+	if o.failFast {
+		if first != nil {
+			return results, first
+		} else if next < len(requests) {
+			return results, ctx.Err()
+		}
+		return results, nil
+	}
+	var errs []error
+	for i, result := range results {
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("batch[%d]: %w", i, result.Err))
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// sleep waits for the duration, and returns false if the context is done first
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,161 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	client "github.com/mutablelogic/go-client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// HELPERS
+
+// newBatchServer returns the value of the "n" query parameter as JSON, or an
+// error when n is negative, and records the maximum number of requests in
+// flight at once
+func newBatchServer(t *testing.T, delay time.Duration) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var inflight, peak atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inflight.Add(1)
+		defer inflight.Add(-1)
+		for {
+			if p := peak.Load(); n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(delay)
+		value, _ := strconv.Atoi(r.URL.Query().Get("n"))
+		if value < 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(value)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &peak
+}
+
+// batchRequests returns a request for each value, which is decoded into an int
+func batchRequests(values ...int) []client.BatchRequest {
+	requests := make([]client.BatchRequest, len(values))
+	for i, value := range values {
+		requests[i] = client.BatchRequest{
+			Payload: client.NewRequest(),
+			Out:     new(int),
+			Opts:    []client.RequestOpt{client.OptQuery(map[string][]string{"n": {strconv.Itoa(value)}})},
+		}
+	}
+	return requests
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// TESTS
+
+func Test_Batch_001_Ordered(t *testing.T) {
+	srv, peak := newBatchServer(t, 10*time.Millisecond)
+	c, err := client.New(client.OptEndpoint(srv.URL))
+	require.NoError(t, err)
+
+	values := make([]int, 20)
+	for i := range values {
+		values[i] = i * 10
+	}
+	results, err := c.Batch(context.Background(), batchRequests(values...), client.OptBatchWorkers(4))
+	require.NoError(t, err)
+	require.Len(t, results, len(values))
+	for i, result := range results {
+		assert.NoError(t, result.Err)
+		assert.Equal(t, values[i], *result.Out.(*int))
+	}
+	assert.LessOrEqual(t, peak.Load(), int32(4))
+	assert.Greater(t, peak.Load(), int32(1))
+}
+
+func Test_Batch_002_CollectAll(t *testing.T) {
+	srv, _ := newBatchServer(t, 0)
+	c, err := client.New(client.OptEndpoint(srv.URL))
+	require.NoError(t, err)
+
+	results, err := c.Batch(context.Background(), batchRequests(1, -1, 3, -1, 5))
+	assert.Error(t, err)
+	require.Len(t, results, 5)
+	for i, result := range results {
+		if i%2 == 1 {
+			assert.Error(t, result.Err)
+		} else {
+			assert.NoError(t, result.Err)
+			assert.Equal(t, i+1, *result.Out.(*int))
+		}
+	}
+}
+
+func Test_Batch_003_FailFast(t *testing.T) {
+	srv, _ := newBatchServer(t, 5*time.Millisecond)
+	c, err := client.New(client.OptEndpoint(srv.URL))
+	require.NoError(t, err)
+
+	values := make([]int, 50)
+	values[0] = -1
+	results, err := c.Batch(context.Background(), batchRequests(values...), client.OptBatchWorkers(1), client.OptBatchFailFast())
+	assert.Error(t, err)
+	require.Len(t, results, len(values))
+	assert.Error(t, results[0].Err)
+
+	// The remaining requests are not made
+	for _, result := range results[1:] {
+		assert.True(t, errors.Is(result.Err, context.Canceled))
+	}
+}
+
+func Test_Batch_004_RateLimit(t *testing.T) {
+	srv, _ := newBatchServer(t, 0)
+	c, err := client.New(client.OptEndpoint(srv.URL))
+	require.NoError(t, err)
+
+	// Five requests at 50 per second take at least 80ms
+	start := time.Now()
+	_, err = c.Batch(context.Background(), batchRequests(1, 2, 3, 4, 5), client.OptBatchRateLimit(50))
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 80*time.Millisecond)
+}
+
+func Test_Batch_005_Options(t *testing.T) {
+	srv, _ := newBatchServer(t, 0)
+	c, err := client.New(client.OptEndpoint(srv.URL))
+	require.NoError(t, err)
+
+	_, err = c.Batch(context.Background(), nil, client.OptBatchWorkers(0))
+	assert.Error(t, err)
+	_, err = c.Batch(context.Background(), nil, client.OptBatchRateLimit(-1))
+	assert.Error(t, err)
+
+	// An empty batch succeeds
+	results, err := c.Batch(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func Test_Batch_006_Cancel(t *testing.T) {
+	srv, _ := newBatchServer(t, 0)
+	c, err := client.New(client.OptEndpoint(srv.URL))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, err := c.Batch(ctx, batchRequests(1, 2, 3))
+	assert.Error(t, err)
+	for _, result := range results {
+		assert.True(t, errors.Is(result.Err, context.Canceled))
+	}
+}
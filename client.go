@@ -14,6 +14,7 @@ import (
 	"time"
 
 	// Package imports
+	multipart "github.com/mutablelogic/go-client/pkg/multipart"
 	transport "github.com/mutablelogic/go-client/pkg/transport"
 	httpresponse "github.com/mutablelogic/go-server/pkg/httpresponse"
 	types "github.com/mutablelogic/go-server/pkg/types"
@@ -39,8 +40,9 @@ type Client struct {
 	Parent any
 
 	endpoint    *url.URL
-	ua          string  // setup-only: consumed by New() into HeadersTransport
-	rate        float32 // setup-only: consumed by New() into RateLimitTransport
+	ua          string   // setup-only: consumed by New() into HeadersTransport
+	rate        float32  // setup-only: consumed by New() into RateLimitTransport
+	coalesce    []string // setup-only: consumed by New() into CoalesceTransport when non-nil
 	strict      bool
 	atomicToken atomic.Value                                // stores Token — lock-free; written by setToken, read by AccessToken
 	headers     map[string]string                           // setup-only: consumed by New() into HeadersTransport
@@ -106,6 +108,13 @@ func New(opts ...ClientOpt) (*Client, error) {
 		this.rate = 0
 	}
 
+	// Install a coalescing transport outside the rate limit, so that callers
+	// which share a round-trip do not each wait for a send-slot.
+	if this.coalesce != nil {
+		this.Client.Transport = transport.NewCoalesce(this.Client.Transport, this.coalesce...)
+		this.coalesce = nil
+	}
+
 	// Always install the token transport as the outermost layer so that tokens
 	// set via OptReqToken or updated by an OAuth flow are injected on every
 	// outbound request — including requests made directly by SDK-owned transports
@@ -214,9 +223,7 @@ func (client *Client) request(ctx context.Context, method, accept, mimetype stri
 }
 
 // Do will make a JSON request, populate an object with the response and return any errors
-func do(client *http.Client, req *http.Request, accept string, strict bool, out any, opts ...RequestOpt) (err error) {
-	const maxRedirects = 10
-
+func do(client *http.Client, req *http.Request, accept string, strict bool, out any, opts ...RequestOpt) error {
 	// Apply request options
 	reqopts := requestOpts{
 		Request: req,
@@ -227,6 +234,16 @@ func do(client *http.Client, req *http.Request, accept string, strict bool, out
 		}
 	}
 
+	// Perform the request
+	return doRequest(client, &reqopts, accept, strict, out)
+}
+
+// doRequest performs a request once the request options have been applied,
+// so that the same options can be used for several requests
+func doRequest(client *http.Client, reqopts *requestOpts, accept string, strict bool, out any) error {
+	const maxRedirects = 10
+	req := reqopts.Request
+
 	// Work on a shallow copy so we never mutate the shared *http.Client.
 	// Per-request timeout and transport changes are therefore safe without
 	// needing a mutex or deferred restoration.
@@ -430,6 +447,18 @@ func do(client *http.Client, req *http.Request, accept string, strict bool, out
 		if err := xml.NewDecoder(response.Body).Decode(out); err != nil {
 			return err
 		}
+	case mimetype == types.ContentTypeForm || strings.HasPrefix(mimetype, "multipart/"):
+		if v, ok := out.(io.Writer); ok {
+			if _, err := io.Copy(v, response.Body); err != nil {
+				return err
+			}
+			break
+		}
+		if dec, err := multipart.NewDecoder(response.Body, response.Header.Get(types.ContentTypeHeader)); err != nil {
+			return err
+		} else if err := dec.Decode(out); err != nil {
+			return err
+		}
 	case mimetype == types.ContentTypeTextPlain:
 		data, err := io.ReadAll(response.Body)
 		if err != nil {
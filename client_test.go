@@ -4,6 +4,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -187,6 +188,89 @@ func Test_OptRateLimit_throttles_requests(t *testing.T) {
 	assert.GreaterOrEqual(t, elapsed, 150*time.Millisecond)
 }
 
+///////////////////////////////////////////////////////////////////////////////
+// OptCompression
+
+func Test_OptCompression_unsupported_errors(t *testing.T) {
+	_, err := client.New(
+		client.OptEndpoint("http://example.com"),
+		client.OptCompression("deflate", 0),
+	)
+	assert.Error(t, err)
+}
+
+func Test_OptCompression_negative_threshold_errors(t *testing.T) {
+	_, err := client.New(
+		client.OptEndpoint("http://example.com"),
+		client.OptCompression("gzip", -1),
+	)
+	assert.Error(t, err)
+}
+
+func Test_OptCompression_round_trip(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Echo the compressed request body back as the response
+		assert.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		io.Copy(w, r.Body)
+	}))
+	defer srv.Close()
+
+	c, err := client.New(
+		client.OptEndpoint(srv.URL),
+		client.OptCompression("gzip", 0),
+	)
+	require.NoError(t, err)
+
+	payload, err := client.NewJSONRequest(map[string]string{"name": "value"})
+	require.NoError(t, err)
+	var out map[string]string
+	require.NoError(t, c.Do(payload, &out))
+	assert.Equal(t, "value", out["name"])
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// OptCoalesce
+
+func Test_OptCoalesce_shares_round_trip(t *testing.T) {
+	var count atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count.Add(1)
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"state":"on"}`)
+	}))
+	defer srv.Close()
+
+	c, err := client.New(
+		client.OptEndpoint(srv.URL),
+		client.OptCoalesce(),
+	)
+	require.NoError(t, err)
+
+	// Concurrent identical requests share one round-trip, and each decodes
+	// its own copy of the response
+	var wg sync.WaitGroup
+	out := make([]map[string]string, 5)
+	for i := range out {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, c.Do(client.MethodGet, &out[i], client.OptPath("states")))
+		}()
+	}
+	assert.Eventually(t, func() bool { return count.Load() == 1 }, time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), count.Load())
+	for _, v := range out {
+		assert.Equal(t, "on", v["state"])
+	}
+}
+
 ///////////////////////////////////////////////////////////////////////////////
 // OptReqToken
 
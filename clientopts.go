@@ -98,6 +98,37 @@ func OptRateLimit(value float32) ClientOpt {
 	}
 }
 
+// OptCoalesce shares a single round-trip between identical concurrent GET
+// requests, which have the same URL, token and representation headers such
+// as Accept. The headers are also compared when deciding if requests are
+// identical. Each caller receives its own copy of the response, which is
+// read into memory, so use OptNoCoalesce for large responses. Downloads are
+// never coalesced.
+func OptCoalesce(headers ...string) ClientOpt {
+	return func(client *Client) error {
+		client.coalesce = append(make([]string, 0, len(headers)), headers...)
+		return nil
+	}
+}
+
+// OptCompression compresses request bodies of at least threshold bytes (or of
+// unknown length) with encoding, which is "gzip", "br" or "zstd", and
+// transparently decompresses gzip, brotli and zstd responses. An empty encoding
+// only decompresses responses.
+func OptCompression(encoding string, threshold int64) ClientOpt {
+	return func(client *Client) error {
+		if encoding != "" && !transport.IsEncoding(encoding) {
+			return httpresponse.ErrBadRequest.Withf("OptCompression: unsupported encoding %q", encoding)
+		} else if threshold < 0 {
+			return httpresponse.ErrBadRequest.With("OptCompression: negative threshold")
+		}
+		client.transports = append(client.transports, func(parent http.RoundTripper) http.RoundTripper {
+			return transport.NewCompression(parent, encoding, threshold)
+		})
+		return nil
+	}
+}
+
 // OptReqToken sets a request token for all client requests. This can be
 // overridden by the client for individual requests using OptToken.
 func OptReqToken(value Token) ClientOpt {
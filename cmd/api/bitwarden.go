@@ -1,30 +1,77 @@
 package main
 
 import (
-	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
-	"github.com/djthorpe/go-tablewriter"
-	"github.com/mutablelogic/go-client"
-	"github.com/mutablelogic/go-client/pkg/bitwarden"
-	"github.com/mutablelogic/go-client/pkg/bitwarden/schema"
-	"golang.org/x/term"
+	// Packages
+	bitwarden "github.com/mutablelogic/go-client/pkg/bitwarden"
+	schema "github.com/mutablelogic/go-client/pkg/bitwarden/schema"
+	term "golang.org/x/term"
 
-	// Namespace import
+	// Namespace imports
 	. "github.com/djthorpe/go-errors"
 )
 
 ///////////////////////////////////////////////////////////////////////////////
 // TYPES
 
+type Bitwarden struct {
+	CommandAuth     BitwardenAuth     `cmd:"" name:"auth" help:"Authenticate with Bitwarden and sync the vault"`
+	CommandFolders  BitwardenFolders  `cmd:"" name:"folders" help:"Retrieve folders"`
+	CommandLogins   BitwardenLogins   `cmd:"" name:"logins" help:"Retrieve login items"`
+	CommandPassword BitwardenPassword `cmd:"" name:"password" help:"Print the password for a login item"`
+	CommandGet      BitwardenGet      `cmd:"" name:"get" help:"Retrieve an item"`
+	CommandSearch   BitwardenSearch   `cmd:"" name:"search" help:"Search for items by name, username or URI"`
+	CommandGenerate BitwardenGenerate `cmd:"" name:"generate" help:"Generate a password or passphrase"`
+	CommandTOTP     BitwardenTOTP     `cmd:"" name:"totp" help:"Generate a one-time password for a login item"`
+}
+
+type BitwardenClient struct {
+	ClientId     string `help:"Bitwarden API client ID" env:"BW_CLIENTID" required:""`
+	ClientSecret string `help:"Bitwarden API client secret" env:"BW_CLIENTSECRET" required:""`
+	Password     string `help:"Bitwarden master password, prompted for when not set" env:"BW_PASSWORD"`
+	Force        bool   `help:"Force login or sync, even if existing token or data is valid"`
+}
+
+type BitwardenAuth struct {
+	BitwardenClient
+}
+
+type BitwardenFolders struct {
+	BitwardenClient
+}
+
+type BitwardenLogins struct {
+	BitwardenClient
+	Folder string `help:"Only return login items in the folder with this name"`
+}
+
+type BitwardenPassword struct {
+	BitwardenClient
+	Name string `help:"Login item name or ID" arg:"" required:""`
+}
+
+type BitwardenGet struct {
+	BitwardenClient
+	Name string `help:"Item name or ID" arg:"" required:""`
+}
+
+type BitwardenSearch struct {
+	BitwardenClient
+	Query string `help:"Text to search for" arg:"" required:""`
+}
+
 type bwCipher struct {
-	Name     string `json:"name,wrap"`
-	Username string `json:"username,width:30,wrap"`
-	Password string `json:"password,width:30,wrap"`
-	URI      string `json:"uri,width:40,wrap"`
-	Folder   string `json:"folder,width:36"`
+	Name     string            `json:"name,wrap"`
+	Type     schema.CipherType `json:"type,width:8"`
+	Username string            `json:"username,width:30,wrap"`
+	Password string            `json:"password,width:30,wrap"`
+	URI      string            `json:"uri,width:40,wrap"`
+	Folder   string            `json:"folder,width:20"`
 }
 
 ///////////////////////////////////////////////////////////////////////////////
@@ -35,194 +82,274 @@ const (
 	bwDirPerm = 0700
 )
 
-var (
-	bwClient      *bitwarden.Client
-	bwPassword    string
-	bwConfigDir   string
-	bwForce       bool
-	bwInteractive bool
-)
-
 ///////////////////////////////////////////////////////////////////////////////
-// LIFECYCLE
-
-func bwRegister(flags *Flags) {
-	// Register flags required
-	flags.String(bwName, "bitwarden-client-id", "${BW_CLIENTID}", "Client ID")
-	flags.String(bwName, "bitwarden-client-secret", "${BW_CLIENTSECRET}", "Client Secret")
-	flags.String(bwName, "bitwarden-password", "${BW_PASSWORD}", "Master password")
-	flags.Bool(bwName, "force", false, "Force login or sync to Bitwarden, even if existing token or data is valid")
-	flags.Bool(bwName, "interactive", true, "Allow interactive prompts for password")
-
-	// Register commands
-	flags.Register(Cmd{
-		Name:        bwName,
-		Description: "Interact with the Bitwarden API",
-		Parse:       bwParse,
-		Fn: []Fn{
-			{Name: "auth", Description: "Authenticate with Bitwarden", Call: bwAuth},
-			{Name: "folders", Description: "Retrieve folders", Call: bwFolders},
-			{Name: "logins", Description: "Retrieve login items", Call: bwLogins, MaxArgs: 1},
-			{Name: "password", Description: "Print a password to stdout", Call: bwGetPassword, MinArgs: 1, MaxArgs: 1},
-		},
-	})
+// PUBLIC METHODS
+
+func (cmd *BitwardenAuth) Run(globals *Globals) error {
+	client, opts, err := cmd.client(globals)
+	if err != nil {
+		return err
+	}
+
+	// Sync
+	profile, err := client.Sync(opts...)
+	if err != nil {
+		return err
+	}
+	return globals.tablewriter.Write(profile)
 }
 
-func bwParse(flags *Flags, opts ...client.ClientOpt) error {
-	// Get config directory
-	if config, err := os.UserConfigDir(); err != nil {
+func (cmd *BitwardenFolders) Run(globals *Globals) error {
+	client, opts, err := cmd.vault(globals)
+	if err != nil {
 		return err
-	} else {
-		bwConfigDir = filepath.Join(config, bwName)
-		if err := os.MkdirAll(bwConfigDir, bwDirPerm); err != nil {
+	}
+
+	// Decrypt the folders
+	folders, err := client.Folders(opts...)
+	if err != nil {
+		return err
+	}
+	var result []*schema.Folder
+	for folder := folders.Next(); folder != nil; folder = folders.Next() {
+		if folder, err := folders.Decrypt(folder); err != nil {
 			return err
+		} else {
+			result = append(result, folder)
 		}
 	}
+	return globals.tablewriter.Write(result)
+}
 
-	// Set defaults
-	clientId := flags.GetString("bitwarden-client-id")
-	clientSecret := flags.GetString("bitwarden-client-secret")
-	if clientId == "" || clientSecret == "" {
-		return ErrBadParameter.With("Missing -bitwarden-client-id or -bitwarden-client-secret argument")
+func (cmd *BitwardenLogins) Run(globals *Globals) error {
+	client, opts, err := cmd.vault(globals)
+	if err != nil {
+		return err
 	}
-	bwForce = flags.GetBool("force")
-	bwInteractive = flags.GetBool("interactive")
-	bwPassword = flags.GetString("bitwarden-password")
 
-	// Create the client
-	opts = append(opts, bitwarden.OptCredentials(clientId, clientSecret))
-	opts = append(opts, bitwarden.OptFileStorage(bwConfigDir))
-	if client, err := bitwarden.New(opts...); err != nil {
+	// Return login items, optionally in a folder
+	folders, err := cmd.folders(client, opts)
+	if err != nil {
 		return err
-	} else {
-		bwClient = client
 	}
-
-	// Return success
-	return nil
+	result, err := cmd.ciphers(client, opts, folders, func(cipher *schema.Cipher) bool {
+		if cipher.Type != schema.CipherTypeLogin {
+			return false
+		}
+		return cmd.Folder == "" || strings.EqualFold(folders[cipher.FolderId], cmd.Folder)
+	})
+	if err != nil {
+		return err
+	}
+	return globals.tablewriter.Write(result)
 }
 
-///////////////////////////////////////////////////////////////////////////////
-// API METHODS
+func (cmd *BitwardenPassword) Run(globals *Globals) error {
+	client, opts, err := cmd.vault(globals)
+	if err != nil {
+		return err
+	}
 
-func bwAuth(_ context.Context, w *tablewriter.Writer, _ []string) error {
-	opts := []bitwarden.RequestOpt{}
-	if bwForce {
-		opts = append(opts, bitwarden.OptForce())
+	// Print the password for the login item
+	cipher, err := cmd.find(client, opts, cmd.Name, schema.CipherTypeLogin)
+	if err != nil {
+		return err
+	} else if cipher.Login == nil || cipher.Login.Password == "" {
+		return ErrNotFound.Withf("login item %q has no password", cipher.Name)
 	}
+	return globals.tablewriter.Writeln(cipher.Login.Password)
+}
 
-	// Login
-	if err := bwClient.Login(opts...); err != nil {
+func (cmd *BitwardenGet) Run(globals *Globals) error {
+	client, opts, err := cmd.vault(globals)
+	if err != nil {
 		return err
 	}
 
-	// Sync
-	if profile, err := bwClient.Sync(opts...); err != nil {
+	// Return the item
+	cipher, err := cmd.find(client, opts, cmd.Name, 0)
+	if err != nil {
 		return err
-	} else {
-		return w.Write(profile)
 	}
+	return globals.tablewriter.Write(cipher)
 }
 
-func bwFolders(_ context.Context, w *tablewriter.Writer, _ []string) error {
-	opts := []bitwarden.RequestOpt{}
-	if bwForce {
-		opts = append(opts, bitwarden.OptForce())
+func (cmd *BitwardenSearch) Run(globals *Globals) error {
+	client, opts, err := cmd.vault(globals)
+	if err != nil {
+		return err
 	}
-	if bwPassword == "" && bwInteractive {
-		if v, err := bwReadPasswordFromTerminal(); err != nil {
-			return err
-		} else {
-			bwPassword = v
+
+	// Return items which match the query
+	folders, err := cmd.folders(client, opts)
+	if err != nil {
+		return err
+	}
+	query := strings.ToLower(cmd.Query)
+	result, err := cmd.ciphers(client, opts, folders, func(cipher *schema.Cipher) bool {
+		values := []string{cipher.Name}
+		if cipher.Login != nil {
+			values = append(values, cipher.Login.Username, cipher.Login.URI)
+			for _, uri := range cipher.Login.URIs {
+				if uri != nil {
+					values = append(values, uri.URI)
+				}
+			}
 		}
+		return slices.ContainsFunc(values, func(value string) bool {
+			return strings.Contains(strings.ToLower(value), query)
+		})
+	})
+	if err != nil {
+		return err
+	}
+	return globals.tablewriter.Write(result)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// Return a logged in client, and the request options
+func (cmd *BitwardenClient) client(globals *Globals) (*bitwarden.Client, []bitwarden.RequestOpt, error) {
+	// Get the config directory
+	config, err := os.UserConfigDir()
+	if err != nil {
+		return nil, nil, err
 	}
-	if bwPassword != "" {
-		opts = append(opts, bitwarden.OptPassword(bwPassword))
+	path := filepath.Join(config, bwName)
+	if err := os.MkdirAll(path, bwDirPerm); err != nil {
+		return nil, nil, err
 	}
 
-	// Retrieve the folders
-	folders, err := bwClient.Folders(opts...)
+	// Create the client
+	opts := append(slices.Clone(globals.opts), bitwarden.OptCredentials(cmd.ClientId, cmd.ClientSecret), bitwarden.OptFileStorage(path))
+	client, err := bitwarden.New(opts...)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
-	// Decrypt the folders from the session
-	var result []*schema.Folder
-	for folder := folders.Next(); folder != nil; folder = folders.Next() {
-		if folders.CanCrypt() {
-			if folder, err := folders.Decrypt(folder); err == nil {
-				result = append(result, folder)
-			}
-		} else {
-			result = append(result, folder)
-		}
+	// Login
+	var reqOpts []bitwarden.RequestOpt
+	if cmd.Force {
+		reqOpts = append(reqOpts, bitwarden.OptForce())
 	}
-	return w.Write(result)
+	if err := client.Login(reqOpts...); err != nil {
+		return nil, nil, err
+	}
+
+	// Return success
+	return client, reqOpts, nil
 }
 
-func bwLogins(_ context.Context, w *tablewriter.Writer, _ []string) error {
-	opts := []bitwarden.RequestOpt{}
-	if bwForce {
-		opts = append(opts, bitwarden.OptForce())
+// Return a logged in client, and the request options for decrypting the vault
+func (cmd *BitwardenClient) vault(globals *Globals) (*bitwarden.Client, []bitwarden.RequestOpt, error) {
+	client, opts, err := cmd.client(globals)
+	if err != nil {
+		return nil, nil, err
 	}
-	if bwPassword == "" && bwInteractive {
+
+	// Set the password for decryption
+	if cmd.Password == "" {
 		if v, err := bwReadPasswordFromTerminal(); err != nil {
-			return err
+			return nil, nil, err
 		} else {
-			bwPassword = v
+			cmd.Password = v
 		}
 	}
-	if bwPassword != "" {
-		opts = append(opts, bitwarden.OptPassword(bwPassword))
-	}
-	ciphers, err := bwClient.Ciphers(opts...)
+
+	// Return success
+	return client, append(opts, bitwarden.OptPassword(cmd.Password)), nil
+}
+
+// Return folder names, keyed by folder ID
+func (cmd *BitwardenClient) folders(client *bitwarden.Client, opts []bitwarden.RequestOpt) (map[string]string, error) {
+	folders, err := client.Folders(opts...)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	result := make(map[string]string)
+	for folder := folders.Next(); folder != nil; folder = folders.Next() {
+		if folder, err := folders.Decrypt(folder); err != nil {
+			return nil, err
+		} else {
+			result[folder.Id] = folder.Name
+		}
+	}
+	return result, nil
+}
 
-	// Decrypt the ciphers from the session
+// Return decrypted items which are not deleted and match a filter
+func (cmd *BitwardenClient) ciphers(client *bitwarden.Client, opts []bitwarden.RequestOpt, folders map[string]string, fn func(*schema.Cipher) bool) ([]bwCipher, error) {
+	ciphers, err := client.Ciphers(opts...)
+	if err != nil {
+		return nil, err
+	}
 	var result []bwCipher
 	for cipher := ciphers.Next(); cipher != nil; cipher = ciphers.Next() {
-		if cipher.Type != schema.CipherTypeLogin || cipher.Login == nil {
+		if cipher.DeletedDate != nil {
 			continue
 		}
-		if ciphers.CanCrypt() {
-			if cipher, err := ciphers.Decrypt(cipher); err == nil {
-				result = append(result, bwCipher{
-					Name:     cipher.Name,
-					Username: cipher.Login.Username,
-					Password: cipher.Login.Password,
-					URI:      cipher.Login.URI,
-					Folder:   cipher.FolderId,
-				})
+		cipher, err := ciphers.Decrypt(cipher)
+		if err != nil {
+			return nil, err
+		} else if !fn(cipher) {
+			continue
+		}
+		row := bwCipher{
+			Name:   cipher.Name,
+			Type:   cipher.Type,
+			Folder: folders[cipher.FolderId],
+		}
+		if cipher.Login != nil {
+			row.Username = cipher.Login.Username
+			row.Password = cipher.Login.Password
+			row.URI = cipher.Login.URI
+			if row.URI == "" && len(cipher.Login.URIs) > 0 && cipher.Login.URIs[0] != nil {
+				row.URI = cipher.Login.URIs[0].URI
 			}
-		} else {
-			result = append(result, bwCipher{
-				Name:     cipher.Name,
-				Username: cipher.Login.Username,
-				URI:      cipher.Login.URI,
-				Folder:   cipher.FolderId,
-			})
 		}
+		result = append(result, row)
 	}
-	return w.Write(result)
+	return result, nil
 }
 
-func bwGetPassword(_ context.Context, w *tablewriter.Writer, _ []string) error {
-	return ErrNotImplemented
+// Return the decrypted item which matches a name or ID, and optionally a type.
+// It is an error if more than one item matches.
+func (cmd *BitwardenClient) find(client *bitwarden.Client, opts []bitwarden.RequestOpt, name string, kind schema.CipherType) (*schema.Cipher, error) {
+	ciphers, err := client.Ciphers(opts...)
+	if err != nil {
+		return nil, err
+	}
+	var result *schema.Cipher
+	for cipher := ciphers.Next(); cipher != nil; cipher = ciphers.Next() {
+		if cipher.DeletedDate != nil || (kind != 0 && cipher.Type != kind) {
+			continue
+		}
+		cipher, err := ciphers.Decrypt(cipher)
+		if err != nil {
+			return nil, err
+		}
+		if cipher.Id != name && !strings.EqualFold(cipher.Name, name) {
+			continue
+		} else if result != nil {
+			return nil, ErrBadParameter.Withf("more than one item named %q", name)
+		}
+		result = cipher
+	}
+	if result == nil {
+		return nil, ErrNotFound.Withf("item %q", name)
+	}
+	return result, nil
 }
 
-///////////////////////////////////////////////////////////////////////////////
-// OTHER
-
 func bwReadPasswordFromTerminal() (string, error) {
 	stdin := int(os.Stdin.Fd())
 	if !term.IsTerminal(stdin) {
 		return "", ErrBadParameter.With("No password set and not running in terminal")
 	}
-	fmt.Fprintf(os.Stdout, "Enter password: ")
+	fmt.Fprintf(os.Stderr, "Enter password: ")
 	defer func() {
-		fmt.Fprintf(os.Stdout, "\n")
+		fmt.Fprintf(os.Stderr, "\n")
 	}()
 	if value, err := term.ReadPassword(stdin); err != nil {
 		return "", err
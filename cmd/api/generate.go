@@ -0,0 +1,91 @@
+package main
+
+import (
+	// Packages
+	generator "github.com/mutablelogic/go-client/pkg/bitwarden/generator"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+type BitwardenGenerate struct {
+	Length         uint   `help:"Password length, or number of words in a passphrase" short:"l"`
+	Uppercase      bool   `help:"Include uppercase characters" default:"true" negatable:""`
+	Lowercase      bool   `help:"Include lowercase characters" default:"true" negatable:""`
+	Numbers        bool   `help:"Include number characters" default:"true" negatable:""`
+	Special        bool   `help:"Include special characters" negatable:""`
+	MinNumbers     uint   `help:"Minimum number of number characters"`
+	MinSpecial     uint   `help:"Minimum number of special characters"`
+	AvoidAmbiguous bool   `help:"Avoid characters which can be mistaken for each other"`
+	Passphrase     bool   `help:"Generate a passphrase of words instead of a password" short:"p"`
+	Separator      string `help:"Separator between words in a passphrase" default:"-"`
+	Capitalize     bool   `help:"Capitalize the first letter of each word in a passphrase"`
+	IncludeNumber  bool   `help:"Append a number to one of the words in a passphrase"`
+}
+
+type bwGenerated struct {
+	Password string  `json:"password,width:64"`
+	Strength string  `json:"strength,width:11"`
+	Entropy  float64 `json:"entropy,width:7"`
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+func (cmd *BitwardenGenerate) Run(globals *Globals) error {
+	var opts []generator.Opt
+	if cmd.Length > 0 {
+		opts = append(opts, generator.OptLength(cmd.Length))
+	}
+
+	// Generate a passphrase or password
+	var value string
+	var err error
+	if cmd.Passphrase {
+		opts = append(opts, generator.OptSeparator(cmd.Separator))
+		if cmd.Capitalize {
+			opts = append(opts, generator.OptCapitalize())
+		}
+		if cmd.IncludeNumber {
+			opts = append(opts, generator.OptIncludeNumber())
+		}
+		value, err = generator.Passphrase(opts...)
+	} else {
+		opts = append(opts, generator.OptCharset(cmd.charset()), generator.OptMinNumbers(cmd.MinNumbers), generator.OptMinSpecial(cmd.MinSpecial))
+		if cmd.AvoidAmbiguous {
+			opts = append(opts, generator.OptAvoidAmbiguous())
+		}
+		value, err = generator.Password(opts...)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Write the value with the estimated strength
+	strength := generator.Estimate(value)
+	return globals.tablewriter.Write(bwGenerated{
+		Password: value,
+		Strength: strength.String(),
+		Entropy:  strength.Entropy,
+	})
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+func (cmd *BitwardenGenerate) charset() generator.Charset {
+	charset := generator.CharsetNone
+	if cmd.Uppercase {
+		charset |= generator.CharsetUpper
+	}
+	if cmd.Lowercase {
+		charset |= generator.CharsetLower
+	}
+	if cmd.Numbers || cmd.MinNumbers > 0 {
+		charset |= generator.CharsetNumber
+	}
+	if cmd.Special || cmd.MinSpecial > 0 {
+		charset |= generator.CharsetSpecial
+	}
+	return charset
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	// Packages
+	"os"
 	"strings"
 	"time"
 
@@ -24,6 +25,8 @@ type HomeAssistant struct {
 	CommandGetDomains  HomeAssistantDomains     `cmd:"" name:"domains" help:"Get service domains"`
 	CommandGetServices HomeAssistantServices    `cmd:"" name:"services" help:"Get services for a domain"`
 	CommandCallService HomeAssistantCallService `cmd:"" name:"call" help:"Call a service for a domain"`
+	CommandGetCamera   HomeAssistantCamera      `cmd:"" name:"camera" help:"Save a camera snapshot"`
+	CommandGetCalendar HomeAssistantCalendar    `cmd:"" name:"calendar" help:"Get calendars or upcoming calendar events"`
 }
 
 type HomeAssistantHealth struct {
@@ -59,6 +62,18 @@ type HomeAssistantState struct {
 	Entity string `help:"Entity ID" arg:"" required:""`
 }
 
+type HomeAssistantCamera struct {
+	HomeAssistantEndpoint
+	Entity string `help:"Camera entity ID" arg:"" required:""`
+	File   string `help:"Image file path" arg:"" required:"" type:"path"`
+}
+
+type HomeAssistantCalendar struct {
+	HomeAssistantEndpoint
+	Entity string        `help:"Calendar entity ID" arg:"" optional:""`
+	Within time.Duration `help:"Return events starting within this duration" default:"168h"`
+}
+
 ///////////////////////////////////////////////////////////////////////////////
 // PUBLIC METHODS
 
@@ -174,3 +189,64 @@ func (cmd *HomeAssistantCallService) Run(globals *Globals) error {
 	}
 	return globals.tablewriter.Write(state)
 }
+
+func (cmd *HomeAssistantCamera) Run(globals *Globals) error {
+	client, err := homeassistant.New(cmd.Endpoint, cmd.Key, globals.opts...)
+	if err != nil {
+		return err
+	}
+
+	// Create the image file
+	w, err := os.Create(cmd.File)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	// Write the snapshot, removing the file on error
+	if err := client.CameraImage(globals.ctx, cmd.Entity, w); err != nil {
+		w.Close()
+		os.Remove(cmd.File)
+		return err
+	}
+	return w.Close()
+}
+
+func (cmd *HomeAssistantCalendar) Run(globals *Globals) error {
+	client, err := homeassistant.New(cmd.Endpoint, cmd.Key, globals.opts...)
+	if err != nil {
+		return err
+	}
+
+	// Without an entity, list the calendars
+	if cmd.Entity == "" {
+		calendars, err := client.Calendars(globals.ctx)
+		if err != nil {
+			return err
+		}
+		return globals.tablewriter.Write(calendars)
+	}
+
+	// Reformat events
+	type Event struct {
+		Summary  string    `json:"summary,wrap"`
+		Start    time.Time `json:"start,width:34"`
+		End      time.Time `json:"end,width:34"`
+		Location string    `json:"location,wrap"`
+	}
+	now := time.Now()
+	events, err := client.CalendarEvents(globals.ctx, cmd.Entity, now, now.Add(cmd.Within))
+	if err != nil {
+		return err
+	}
+	result := make([]Event, 0, len(events))
+	for _, event := range events {
+		result = append(result, Event{
+			Summary:  event.Summary,
+			Start:    event.Start.Time(),
+			End:      event.End.Time(),
+			Location: event.Location,
+		})
+	}
+	return globals.tablewriter.Write(result)
+}
@@ -25,6 +25,7 @@ type CLI struct {
 	Globals
 	IPify         `embed:"" prefix:"ipify."`
 	HomeAssistant `embed:"" prefix:"ha."`
+	Bitwarden     `embed:"" prefix:"bitwarden."`
 }
 
 type Globals struct {
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"time"
+
+	// Packages
+	schema "github.com/mutablelogic/go-client/pkg/bitwarden/schema"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+type BitwardenTOTP struct {
+	BitwardenClient
+	Name string `help:"Login item name or ID" arg:"" required:""`
+}
+
+type bwTOTP struct {
+	Name    string        `json:"name,width:30"`
+	Code    string        `json:"code,width:10"`
+	Expires time.Duration `json:"expires,width:8"`
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+func (cmd *BitwardenTOTP) Run(globals *Globals) error {
+	client, opts, err := cmd.vault(globals)
+	if err != nil {
+		return err
+	}
+
+	// Find the login item and generate the code
+	cipher, err := cmd.find(client, opts, cmd.Name, schema.CipherTypeLogin)
+	if err != nil {
+		return err
+	}
+	code, expires, err := cipher.Login.TOTPCode(time.Now())
+	if err != nil {
+		return err
+	}
+	return globals.tablewriter.Write(bwTOTP{
+		Name:    cipher.Name,
+		Code:    code,
+		Expires: expires,
+	})
+}
@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	client "github.com/mutablelogic/go-client"
+	types "github.com/mutablelogic/go-server/pkg/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -98,7 +99,7 @@ func Test_Do_StrictMode_ContentTypeMismatch(t *testing.T) {
 	c, err := client.New(client.OptEndpoint(srv.URL), client.OptStrict())
 	require.NoError(t, err)
 	var out string
-	err = c.Do(client.NewRequestEx(http.MethodGet, client.ContentTypeJson), &out)
+	err = c.Do(client.NewRequestEx(http.MethodGet, types.ContentTypeJSON), &out)
 	assert.Error(t, err)
 }
 
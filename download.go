@@ -0,0 +1,596 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	// Package imports
+	transport "github.com/mutablelogic/go-client/pkg/transport"
+	httpresponse "github.com/mutablelogic/go-server/pkg/httpresponse"
+	types "github.com/mutablelogic/go-server/pkg/types"
+	errgroup "golang.org/x/sync/errgroup"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// download is the state of a download in progress
+type download struct {
+	sync.Mutex
+	client   *http.Client
+	reqopts  requestOpts
+	w        io.WriterAt
+	offset   int64                // Number of bytes present before the download started
+	received int64                // Number of bytes present, including the offset
+	complete int64                // Length of the complete prefix after a parallel download fails, or -1
+	total    int64                // Length of the download, or -1 when unknown
+	ifRange  string               // Validator sent with ranged requests
+	validate func(string) error   // Called when the validator changes
+	modified time.Time            // Last-Modified time of the download
+	digests  map[string][]byte    // Digests returned by the server, by algorithm
+	hashes   map[string]hash.Hash // Digests computed as a sequential download is written
+	start    time.Time
+}
+
+// downloadRange writes the body of a response at an offset
+type downloadRange struct {
+	*download
+	offset int64 // Offset of the next byte to write
+	end    int64 // Offset after the last byte, or -1 for the end of the download
+	probe  bool  // Discard the body of a ranged response
+	whole  bool  // The server returned the whole download rather than a range
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// GLOBALS
+
+const (
+	digestSHA256 = "sha-256"
+	digestSHA512 = "sha-512"
+)
+
+const (
+	downloadRetries   = 3
+	downloadDelay     = time.Second
+	downloadChunkSize = 1 << 20 // Smallest part of a parallel download
+	downloadBufSize   = 32 * 1024
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Download sends a GET request and writes the response body to w. An
+// interrupted download is resumed with a Range request, and OptParallel splits
+// a download into concurrent ranged requests. The download is verified against
+// any digest returned by the server and any checksum set with OptChecksum.
+// Verifying a parallel download requires w to implement io.ReaderAt. The
+// client timeout does not apply, so use the context to cancel a download.
+func (client *Client) Download(ctx context.Context, w io.WriterAt, opts ...RequestOpt) error {
+	if w == nil {
+		return httpresponse.ErrBadRequest.With("Download: missing writer")
+	}
+	_, err := client.download(ctx, w, 0, "", nil, opts...)
+	return err
+}
+
+// DownloadFile downloads to a file at path. The download is written to a file
+// with a ".part" extension, which is renamed once the download is complete, so
+// an interrupted download is resumed by calling DownloadFile again. The ETag
+// or Last-Modified time from the server is written to a ".part.validator"
+// file as soon as the response is received, and is used to check that the
+// file has not changed on resume. A failed parallel download is truncated to
+// the end of its first incomplete range, as later ranges may be complete while
+// earlier ones are not. The modification time of the completed file is set to
+// the Last-Modified time from the server.
+func (client *Client) DownloadFile(ctx context.Context, path string, opts ...RequestOpt) error {
+	part := path + ".part"
+	validator := part + ".validator"
+	f, err := os.OpenFile(part, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	// Resume from the end of a partial download. If the file has changed on the
+	// server, then the If-Range validator does not match and the whole file is
+	// returned. Without a stored validator, the modification time is used.
+	var offset int64
+	var ifRange string
+	if info.Size() > 0 {
+		offset = info.Size()
+		if data, err := os.ReadFile(validator); err == nil && len(data) > 0 {
+			ifRange = string(data)
+		} else {
+			ifRange = info.ModTime().UTC().Format(http.TimeFormat)
+		}
+	}
+	d, err := client.download(ctx, f, offset, ifRange, func(value string) error {
+		return os.WriteFile(validator, []byte(value), 0o644)
+	}, opts...)
+
+	// A failed parallel download has ranges missing, so discard everything
+	// after the first missing range before it is resumed
+	if err != nil && d != nil && d.complete >= 0 {
+		if truncErr := f.Truncate(d.complete); truncErr != nil {
+			err = errors.Join(err, truncErr)
+		}
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	// Set the modification time, which is checked when resuming
+	if d != nil && !d.modified.IsZero() {
+		if timeErr := os.Chtimes(part, d.modified, d.modified); err == nil {
+			err = timeErr
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	// Rename the completed download
+	if err := os.Rename(part, path); err != nil {
+		return err
+	}
+	if err := os.Remove(validator); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	// Return success
+	return nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// download writes a download to w, starting at offset. The ifRange validator
+// is sent with a ranged request when the offset is not zero, and validate is
+// called when the server returns a different validator.
+func (client *Client) download(ctx context.Context, w io.WriterAt, offset int64, ifRange string, validate func(string) error, opts ...RequestOpt) (*download, error) {
+	req, err := client.request(ctx, http.MethodGet, types.ContentTypeAny, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ranges and digests apply to the encoded content, so don't compress
+	req.Header.Set("Accept-Encoding", "identity")
+
+	// Apply request options
+	d := &download{
+		client:   client.Client,
+		reqopts:  requestOpts{Request: req},
+		w:        w,
+		offset:   offset,
+		received: offset,
+		total:    -1,
+		complete: -1,
+		ifRange:  ifRange,
+		validate: validate,
+	}
+	for _, opt := range opts {
+		if err := opt(&d.reqopts); err != nil {
+			return nil, err
+		}
+	}
+	d.reqopts.noTimeout = true
+
+	// Compute digests while writing when they can't be read back from w
+	if _, ok := w.(io.ReaderAt); !ok {
+		if d.reqopts.parallel > 1 {
+			if d.reqopts.checksum != nil {
+				return nil, httpresponse.ErrBadRequest.With("Download: verifying a parallel download requires an io.ReaderAt")
+			}
+		} else {
+			d.hashes = map[string]hash.Hash{digestSHA256: sha256.New(), digestSHA512: sha512.New()}
+		}
+	}
+
+	// Download and verify
+	d.start = time.Now()
+	if d.reqopts.parallel > 1 {
+		err = d.parallel(ctx)
+	} else {
+		_, err = d.fetch(ctx, d.offset, -1)
+	}
+	if err == nil {
+		err = d.verify()
+	}
+
+	// Return the download
+	return d, err
+}
+
+// parallel requests the first byte to determine the length of the download,
+// and then downloads the remainder in ranges concurrently
+func (d *download) parallel(ctx context.Context) error {
+	probe := &downloadRange{download: d, end: 1, probe: true}
+	err := d.get(ctx, probe)
+	switch {
+	case probe.whole:
+		// The server does not support ranges, so the whole download was written
+		if err != nil && retryable(err) {
+			_, err = d.fetch(ctx, probe.offset, -1)
+		}
+		return err
+	case isStatus(err, http.StatusRequestedRangeNotSatisfiable):
+		// An empty download
+		if err := d.restart(); err != nil {
+			return err
+		}
+		_, err := d.fetch(ctx, 0, -1)
+		return err
+	case err != nil:
+		return err
+	case d.total < 0:
+		// The length is unknown
+		_, err := d.fetch(ctx, d.offset, -1)
+		return err
+	}
+
+	// Split the remainder into ranges, and record how far each range reached
+	n, total := int64(d.reqopts.parallel), d.total
+	size := max((total-d.offset+n-1)/n, downloadChunkSize)
+	var ranges [][2]int64
+	for start := d.offset; start < total; start += size {
+		ranges = append(ranges, [2]int64{start, min(start+size, total)})
+	}
+	reached := make([]int64, len(ranges))
+	g, ctx := errgroup.WithContext(ctx)
+	for i, r := range ranges {
+		g.Go(func() (err error) {
+			reached[i], err = d.fetch(ctx, r[0], r[1])
+			return err
+		})
+	}
+	if err := g.Wait(); err != nil {
+		// The ranges are written out of order, so only the ranges before the
+		// first incomplete range can be resumed
+		d.Lock()
+		d.complete = d.offset
+		for i, r := range ranges {
+			d.complete = reached[i]
+			if reached[i] < r[1] {
+				break
+			}
+		}
+		d.Unlock()
+		return err
+	}
+
+	// Return success
+	return nil
+}
+
+// fetch downloads from offset to end, or to the end of the download when end
+// is -1. After an error, the download continues from the last byte received.
+// It returns the offset after the last byte written.
+func (d *download) fetch(ctx context.Context, offset, end int64) (int64, error) {
+	for attempt := 1; ; attempt++ {
+		r := &downloadRange{download: d, offset: offset, end: end}
+		err := d.get(ctx, r)
+		offset = r.offset
+
+		// Check for a short response
+		if err == nil {
+			if target := r.target(); target >= 0 && offset < target {
+				err = io.ErrUnexpectedEOF
+			}
+		}
+
+		// A sequential download which can't be resumed starts again
+		if end < 0 && offset > 0 && isStatus(err, http.StatusRequestedRangeNotSatisfiable) {
+			if err = d.restart(); err == nil {
+				offset = 0
+				continue
+			}
+		}
+		if err == nil {
+			return offset, nil
+		} else if attempt > downloadRetries || !retryable(err) {
+			return offset, err
+		}
+
+		// Wait before retrying
+		select {
+		case <-ctx.Done():
+			return offset, ctx.Err()
+		case <-time.After(downloadDelay * time.Duration(attempt)):
+		}
+	}
+}
+
+// get requests a range of the download and writes the response
+func (d *download) get(ctx context.Context, r *downloadRange) error {
+	req := d.reqopts.Request.Clone(transport.WithSkipCoalesce(ctx))
+	if r.ranged() {
+		if r.end >= 0 {
+			req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", r.offset, r.end-1))
+		} else {
+			req.Header.Set("Range", fmt.Sprintf("bytes=%d-", r.offset))
+		}
+		d.Lock()
+		if d.ifRange != "" {
+			req.Header.Set("If-Range", d.ifRange)
+		}
+		d.Unlock()
+	}
+
+	// Perform the request with the same options
+	reqopts := d.reqopts
+	reqopts.Request = req
+	return doRequest(d.client, &reqopts, "", false, r)
+}
+
+// update sets the length, validator and digests of the download from the
+// response headers
+func (d *download) update(header http.Header, total int64, whole bool) error {
+	d.Lock()
+	defer d.Unlock()
+	if total >= 0 {
+		d.total = total
+	} else if whole {
+		if length, err := strconv.ParseInt(header.Get("Content-Length"), 10, 64); err == nil {
+			d.total = length
+		}
+	}
+	if modified, err := http.ParseTime(header.Get("Last-Modified")); err == nil {
+		d.modified = modified
+	}
+	ifRange := d.ifRange
+	if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		d.ifRange = etag
+	} else if value := header.Get("Last-Modified"); value != "" {
+		d.ifRange = value
+	}
+	if digests := parseDigests(header, whole); len(digests) > 0 {
+		d.digests = digests
+	}
+
+	// Store the validator before the body is written
+	if d.validate != nil && d.ifRange != ifRange {
+		return d.validate(d.ifRange)
+	}
+	return nil
+}
+
+// restart discards anything already written, when the whole download is
+// returned rather than the range requested
+func (d *download) restart() error {
+	d.Lock()
+	defer d.Unlock()
+	if t, ok := d.w.(interface{ Truncate(int64) error }); ok {
+		if err := t.Truncate(0); err != nil {
+			return err
+		}
+	}
+	d.offset, d.received = 0, 0
+	for _, h := range d.hashes {
+		h.Reset()
+	}
+	return nil
+}
+
+// written records data written to the download and reports progress
+func (d *download) written(data []byte) {
+	d.Lock()
+	defer d.Unlock()
+	d.received += int64(len(data))
+	for _, h := range d.hashes {
+		h.Write(data)
+	}
+	if d.reqopts.progress != nil {
+		d.reqopts.progress(Progress{
+			Sent:    d.received,
+			Total:   d.total,
+			Offset:  d.offset,
+			Elapsed: time.Since(d.start),
+		})
+	}
+}
+
+// verify checks the download against the digests from the server and the
+// checksum set with OptChecksum
+func (d *download) verify() error {
+	if len(d.digests) == 0 && d.reqopts.checksum == nil {
+		return nil
+	}
+
+	// Compute the digests, reading back the download if they weren't computed
+	// while it was written
+	hashes := d.hashes
+	if hashes == nil {
+		r, ok := d.w.(io.ReaderAt)
+		if !ok {
+			return nil
+		}
+		hashes = map[string]hash.Hash{digestSHA256: sha256.New(), digestSHA512: sha512.New()}
+		if _, err := io.Copy(io.MultiWriter(hashes[digestSHA256], hashes[digestSHA512]), io.NewSectionReader(r, 0, d.received)); err != nil {
+			return err
+		}
+	}
+
+	// Compare the digests
+	for algorithm, digest := range d.digests {
+		if !bytes.Equal(hashes[algorithm].Sum(nil), digest) {
+			return httpresponse.ErrGatewayError.Withf("Download: %s digest mismatch", algorithm)
+		}
+	}
+	if d.reqopts.checksum != nil && !bytes.Equal(hashes[digestSHA256].Sum(nil), d.reqopts.checksum) {
+		return httpresponse.ErrGatewayError.With("Download: checksum mismatch")
+	}
+
+	// Return success
+	return nil
+}
+
+// ranged returns true if the request is for a range of the download
+func (r *downloadRange) ranged() bool {
+	return r.offset > 0 || r.end >= 0
+}
+
+// target returns the offset after the last byte expected, or -1 if unknown
+func (r *downloadRange) target() int64 {
+	if r.end >= 0 && !r.whole {
+		return r.end
+	}
+	r.Lock()
+	defer r.Unlock()
+	return r.total
+}
+
+// Unmarshal writes the response body at the offset of the range
+func (r *downloadRange) Unmarshal(header http.Header, body io.Reader) error {
+	start, total, err := parseContentRange(header.Get("Content-Range"))
+	if err != nil {
+		return err
+	}
+	r.whole = start < 0
+	if err := r.update(header, total, r.whole); err != nil {
+		return err
+	}
+
+	// Check the range which was returned
+	switch {
+	case !r.whole && start != r.offset:
+		return httpresponse.ErrGatewayError.Withf("Download: unexpected Content-Range %q", header.Get("Content-Range"))
+	case r.whole && r.ranged():
+		if r.end >= 0 && !r.probe {
+			// The download changed, so the ranges already written are invalid
+			return httpresponse.ErrConflict.With("Download: the server did not return the requested range")
+		}
+		if err := r.restart(); err != nil {
+			return err
+		}
+		r.offset, r.end = 0, -1
+	case r.probe:
+		_, err := io.Copy(io.Discard, body)
+		return err
+	}
+
+	// Write the body
+	buf := make([]byte, downloadBufSize)
+	for r.end < 0 || r.offset < r.end {
+		data := buf
+		if r.end >= 0 && int64(len(data)) > r.end-r.offset {
+			data = data[:r.end-r.offset]
+		}
+		n, err := body.Read(data)
+		if n > 0 {
+			if _, err := r.w.WriteAt(data[:n], r.offset); err != nil {
+				return err
+			}
+			r.offset += int64(n)
+			r.written(data[:n])
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+
+	// Return success
+	return nil
+}
+
+// parseContentRange returns the first byte and total length from a
+// Content-Range header. The first byte is -1 when the header is empty, and
+// the total is -1 when unknown.
+func parseContentRange(value string) (int64, int64, error) {
+	if value == "" {
+		return -1, -1, nil
+	}
+	unit, rng, _ := strings.Cut(value, " ")
+	rng, size, ok := strings.Cut(rng, "/")
+	first, _, _ := strings.Cut(rng, "-")
+	start, err := strconv.ParseInt(first, 10, 64)
+	if unit != "bytes" || !ok || err != nil || start < 0 {
+		return -1, -1, httpresponse.ErrGatewayError.Withf("Download: invalid Content-Range %q", value)
+	}
+	if size == "*" {
+		return start, -1, nil
+	}
+	total, err := strconv.ParseInt(size, 10, 64)
+	if err != nil {
+		return -1, -1, httpresponse.ErrGatewayError.Withf("Download: invalid Content-Range %q", value)
+	}
+	return start, total, nil
+}
+
+// parseDigests returns the SHA-256 and SHA-512 digests of the download from
+// the Repr-Digest and Digest headers, and the Content-Digest header when the
+// whole download was returned
+func parseDigests(header http.Header, whole bool) map[string][]byte {
+	digests := make(map[string][]byte)
+	parse := func(value string) {
+		for _, item := range strings.Split(value, ",") {
+			algorithm, digest, _ := strings.Cut(strings.TrimSpace(item), "=")
+			algorithm = strings.ToLower(strings.TrimSpace(algorithm))
+			if algorithm != digestSHA256 && algorithm != digestSHA512 {
+				continue
+			}
+			// Structured field byte sequences are enclosed in colons
+			digest = strings.Trim(strings.TrimSpace(digest), ":")
+			if data, err := base64.StdEncoding.DecodeString(digest); err == nil {
+				digests[algorithm] = data
+			}
+		}
+	}
+	parse(header.Get("Digest"))
+	if whole {
+		parse(header.Get("Content-Digest"))
+	}
+	parse(header.Get("Repr-Digest"))
+	return digests
+}
+
+// isStatus returns true if the error has the HTTP status code
+func isStatus(err error, status int) bool {
+	var code httpresponse.Err
+	var resp httpresponse.ErrResponse
+	switch {
+	case errors.As(err, &code):
+		return int(code) == status
+	case errors.As(err, &resp):
+		return resp.Code == status
+	default:
+		return false
+	}
+}
+
+// retryable returns true if a download can be resumed after the error,
+// which is a network error or a server error
+func retryable(err error) bool {
+	var code httpresponse.Err
+	var resp httpresponse.ErrResponse
+	var status int
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return false
+	case errors.As(err, &code):
+		status = int(code)
+	case errors.As(err, &resp):
+		status = resp.Code
+	default:
+		return true
+	}
+	return status >= 500 || status == http.StatusRequestTimeout || status == http.StatusTooManyRequests
+}
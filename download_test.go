@@ -0,0 +1,346 @@
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	client "github.com/mutablelogic/go-client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// HELPERS
+
+// downloadServer serves content with support for ranges, and records the
+// Range and If-Range headers of each request
+type downloadServer struct {
+	sync.Mutex
+	content  []byte
+	modified time.Time
+	digest   string // Repr-Digest header value
+	abort    bool   // Abort the next response half way through
+	ranges   []string
+	ifRanges []string
+}
+
+func newDownloadServer(t *testing.T, size int) (*httptest.Server, *downloadServer) {
+	t.Helper()
+	content := make([]byte, size)
+	for i := range content {
+		content[i] = byte(i * 7)
+	}
+	sum := sha256.Sum256(content)
+	s := &downloadServer{
+		content:  content,
+		modified: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		digest:   "sha-256=:" + base64.StdEncoding.EncodeToString(sum[:]) + ":",
+	}
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+	return srv, s
+}
+
+func (s *downloadServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	s.ranges = append(s.ranges, r.Header.Get("Range"))
+	s.ifRanges = append(s.ifRanges, r.Header.Get("If-Range"))
+	abort := s.abort
+	s.abort = false
+	s.Unlock()
+
+	w.Header().Set("Repr-Digest", s.digest)
+	if abort {
+		w.Header().Set("Content-Length", strconv.Itoa(len(s.content)))
+		w.Header().Set("Last-Modified", s.modified.Format(http.TimeFormat))
+		w.Write(s.content[:len(s.content)/2])
+		w.(http.Flusher).Flush()
+		panic(http.ErrAbortHandler)
+	}
+	http.ServeContent(w, r, "", s.modified, bytes.NewReader(s.content))
+}
+
+// writerAt implements io.WriterAt but not io.ReaderAt
+type writerAt struct {
+	sync.Mutex
+	data []byte
+}
+
+func (w *writerAt) WriteAt(p []byte, off int64) (int, error) {
+	w.Lock()
+	defer w.Unlock()
+	if end := int(off) + len(p); end > len(w.data) {
+		w.data = append(w.data, make([]byte, end-len(w.data))...)
+	}
+	return copy(w.data[off:], p), nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// TESTS
+
+func Test_Download_001_Sequential(t *testing.T) {
+	srv, s := newDownloadServer(t, 100*1024)
+	c, err := client.New(client.OptEndpoint(srv.URL))
+	require.NoError(t, err)
+
+	sum := sha256.Sum256(s.content)
+	var last client.Progress
+	w := new(writerAt)
+	require.NoError(t, c.Download(context.Background(), w, client.OptChecksum(hex.EncodeToString(sum[:])), client.OptProgress(func(p client.Progress) {
+		assert.GreaterOrEqual(t, p.Sent, last.Sent)
+		last = p
+	})))
+	assert.Equal(t, s.content, w.data)
+	assert.Equal(t, int64(len(s.content)), last.Sent)
+	assert.Equal(t, int64(len(s.content)), last.Total)
+}
+
+func Test_Download_002_ChecksumMismatch(t *testing.T) {
+	srv, _ := newDownloadServer(t, 1024)
+	c, err := client.New(client.OptEndpoint(srv.URL))
+	require.NoError(t, err)
+
+	sum := sha256.Sum256([]byte("other"))
+	err = c.Download(context.Background(), new(writerAt), client.OptChecksum(hex.EncodeToString(sum[:])))
+	assert.Error(t, err)
+
+	// Invalid checksum
+	err = c.Download(context.Background(), new(writerAt), client.OptChecksum("abc"))
+	assert.Error(t, err)
+}
+
+func Test_Download_003_DigestMismatch(t *testing.T) {
+	srv, s := newDownloadServer(t, 1024)
+	s.digest = "sha-256=:" + base64.StdEncoding.EncodeToString(make([]byte, 32)) + ":"
+	c, err := client.New(client.OptEndpoint(srv.URL))
+	require.NoError(t, err)
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "download"))
+	require.NoError(t, err)
+	defer f.Close()
+	assert.Error(t, c.Download(context.Background(), f))
+}
+
+func Test_Download_004_Resume(t *testing.T) {
+	srv, s := newDownloadServer(t, 100*1024)
+	s.abort = true
+	c, err := client.New(client.OptEndpoint(srv.URL))
+	require.NoError(t, err)
+
+	// The first response is interrupted, and the download resumes with a range
+	w := new(writerAt)
+	require.NoError(t, c.Download(context.Background(), w))
+	assert.Equal(t, s.content, w.data)
+	require.Len(t, s.ranges, 2)
+	assert.Equal(t, "", s.ranges[0])
+	assert.Equal(t, "bytes="+strconv.Itoa(len(s.content)/2)+"-", s.ranges[1])
+	assert.Equal(t, s.modified.Format(http.TimeFormat), s.ifRanges[1])
+}
+
+func Test_Download_005_Parallel(t *testing.T) {
+	srv, s := newDownloadServer(t, 5<<20)
+	c, err := client.New(client.OptEndpoint(srv.URL))
+	require.NoError(t, err)
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "download"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	sum := sha256.Sum256(s.content)
+	var last client.Progress
+	var mu sync.Mutex
+	require.NoError(t, c.Download(context.Background(), f, client.OptParallel(4), client.OptChecksum(hex.EncodeToString(sum[:])), client.OptProgress(func(p client.Progress) {
+		mu.Lock()
+		defer mu.Unlock()
+		last = p
+	})))
+
+	data, err := os.ReadFile(f.Name())
+	require.NoError(t, err)
+	assert.Equal(t, s.content, data)
+	assert.Equal(t, int64(len(s.content)), last.Sent)
+
+	// A probe for the length, then one request for each range
+	assert.Len(t, s.ranges, 5)
+	assert.Equal(t, "bytes=0-0", s.ranges[0])
+}
+
+func Test_Download_006_ParallelRequiresReaderAt(t *testing.T) {
+	srv, s := newDownloadServer(t, 1024)
+	c, err := client.New(client.OptEndpoint(srv.URL))
+	require.NoError(t, err)
+
+	sum := sha256.Sum256(s.content)
+	err = c.Download(context.Background(), new(writerAt), client.OptParallel(2), client.OptChecksum(hex.EncodeToString(sum[:])))
+	assert.Error(t, err)
+	assert.Empty(t, s.ranges)
+}
+
+func Test_Download_007_FileResume(t *testing.T) {
+	srv, s := newDownloadServer(t, 100*1024)
+	c, err := client.New(client.OptEndpoint(srv.URL))
+	require.NoError(t, err)
+
+	// A partial download with the modification time of the file
+	path := filepath.Join(t.TempDir(), "download")
+	require.NoError(t, os.WriteFile(path+".part", s.content[:1000], 0o644))
+	require.NoError(t, os.Chtimes(path+".part", s.modified, s.modified))
+
+	require.NoError(t, c.DownloadFile(context.Background(), path))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, s.content, data)
+	assert.Equal(t, []string{"bytes=1000-"}, s.ranges)
+
+	// The part file is renamed, and has the modification time from the server
+	_, err = os.Stat(path + ".part")
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(path + ".part.validator")
+	assert.True(t, os.IsNotExist(err))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.True(t, info.ModTime().Equal(s.modified))
+}
+
+func Test_Download_008_FileChanged(t *testing.T) {
+	srv, s := newDownloadServer(t, 10*1024)
+	c, err := client.New(client.OptEndpoint(srv.URL))
+	require.NoError(t, err)
+
+	// A partial download which does not match the file on the server, so the
+	// whole file is downloaded again
+	path := filepath.Join(t.TempDir(), "download")
+	require.NoError(t, os.WriteFile(path+".part", bytes.Repeat([]byte("x"), 20*1024), 0o644))
+
+	require.NoError(t, c.DownloadFile(context.Background(), path))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, s.content, data)
+}
+
+func Test_Download_009_NotCoalesced(t *testing.T) {
+	srv, s := newDownloadServer(t, 10*1024)
+
+	// Count the requests which are made by the coalescing transport, and delay
+	// them so that the downloads overlap
+	var count atomic.Int32
+	c, err := client.New(client.OptEndpoint(srv.URL), client.OptCoalesce(), client.OptTransport(func(parent http.RoundTripper) http.RoundTripper {
+		return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			count.Add(1)
+			time.Sleep(50 * time.Millisecond)
+			return parent.RoundTrip(req)
+		})
+	}))
+	require.NoError(t, err)
+
+	// Identical downloads each make their own request
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := new(writerAt)
+			if assert.NoError(t, c.Download(context.Background(), w)) {
+				assert.Equal(t, s.content, w.data)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(2), count.Load())
+}
+
+func Test_Download_010_FileResumeValidator(t *testing.T) {
+	srv, s := newDownloadServer(t, 4*1024*1024)
+	c, err := client.New(client.OptEndpoint(srv.URL))
+	require.NoError(t, err)
+
+	// Interrupt the download once some of it has been written
+	path := filepath.Join(t.TempDir(), "download")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err = c.DownloadFile(ctx, path, client.OptProgress(func(p client.Progress) {
+		if p.Sent > 0 {
+			cancel()
+		}
+	}))
+	require.Error(t, err)
+	info, err := os.Stat(path + ".part")
+	require.NoError(t, err)
+	require.Less(t, info.Size(), int64(len(s.content)))
+
+	// The validator is stored as soon as the response is received
+	validator, err := os.ReadFile(path + ".part.validator")
+	require.NoError(t, err)
+	assert.Equal(t, s.modified.Format(http.TimeFormat), string(validator))
+
+	// The stored validator is used on resume, whatever the modification time
+	require.NoError(t, os.Chtimes(path+".part", time.Now(), time.Now()))
+	require.NoError(t, c.DownloadFile(context.Background(), path))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, s.content, data)
+	assert.Equal(t, "bytes="+strconv.FormatInt(info.Size(), 10)+"-", s.ranges[len(s.ranges)-1])
+	assert.Equal(t, string(validator), s.ifRanges[len(s.ifRanges)-1])
+}
+
+func Test_Download_011_FileResumeParallel(t *testing.T) {
+	_, s := newDownloadServer(t, 4<<20)
+
+	// The third range fails once the last range has been interrupted half way
+	// through, so the part file ends beyond a missing range
+	var failing atomic.Bool
+	failing.Store(true)
+	interrupted := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			switch r.Header.Get("Range") {
+			case "bytes=2097152-3145727":
+				select {
+				case <-interrupted:
+					time.Sleep(200 * time.Millisecond)
+				case <-time.After(5 * time.Second):
+				}
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			case "bytes=3145728-4194303":
+				w.Header().Set("Content-Range", "bytes 3145728-4194303/4194304")
+				w.Header().Set("Content-Length", "1048576")
+				w.WriteHeader(http.StatusPartialContent)
+				w.Write(s.content[3<<20 : 3<<20+1<<19])
+				w.(http.Flusher).Flush()
+				close(interrupted)
+				panic(http.ErrAbortHandler)
+			}
+		}
+		s.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	c, err := client.New(client.OptEndpoint(srv.URL))
+	require.NoError(t, err)
+
+	// The part file is truncated to the ranges which are complete
+	path := filepath.Join(t.TempDir(), "download")
+	require.Error(t, c.DownloadFile(context.Background(), path, client.OptParallel(4)))
+	info, err := os.Stat(path + ".part")
+	require.NoError(t, err)
+	assert.LessOrEqual(t, info.Size(), int64(2<<20))
+
+	// Resuming downloads the missing ranges
+	failing.Store(false)
+	require.NoError(t, c.DownloadFile(context.Background(), path))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, s.content, data)
+}
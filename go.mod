@@ -5,8 +5,10 @@ go 1.25.0
 require (
 	github.com/alecthomas/kong v1.15.0
 	github.com/andreburgaud/crypt2go v1.8.0
+	github.com/andybalholm/brotli v1.2.0
 	github.com/djthorpe/go-errors v1.0.3
 	github.com/djthorpe/go-tablewriter v0.0.11
+	github.com/klauspost/compress v1.18.0
 	github.com/mutablelogic/go-server v1.6.24
 	github.com/stretchr/testify v1.11.1
 	github.com/xdg-go/pbkdf2 v1.0.0
@@ -0,0 +1,271 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"maps"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	// Package imports
+	httpresponse "github.com/mutablelogic/go-server/pkg/httpresponse"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// PatchOp is an operation in a JSON Patch document (RFC 6902). The path and
+// from members are JSON Pointers (RFC 6901), and the value is included for
+// the add, replace and test operations.
+type PatchOp struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	From  string `json:"from,omitempty"`
+	Value any    `json:"value,omitempty"`
+}
+
+// Resource decodes a JSON response into Value, and captures the ETag of the
+// response so that a later update can be made conditional with OptIfMatch
+type Resource struct {
+	Value any
+	ETag  string
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// GLOBALS
+
+const (
+	ContentTypeJSONPatch  = "application/json-patch+json"
+	ContentTypeMergePatch = "application/merge-patch+json"
+)
+
+const (
+	PatchAdd     = "add"
+	PatchRemove  = "remove"
+	PatchReplace = "replace"
+	PatchMove    = "move"
+	PatchCopy    = "copy"
+	PatchTest    = "test"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// LIFECYCLE
+
+// Return a new PATCH request with a JSON Patch (RFC 6902) payload, which is a
+// list of operations applied in order
+func NewJSONPatchRequest(patch []PatchOp) (Payload, error) {
+	for _, op := range patch {
+		if err := op.validate(); err != nil {
+			return nil, err
+		}
+	}
+	if patch == nil {
+		patch = []PatchOp{}
+	}
+	return newPatchRequest(ContentTypeJSONPatch, patch)
+}
+
+// Return a new PATCH request with a JSON Merge Patch (RFC 7396) payload. The
+// members of the patch replace the members of the resource, and a null member
+// removes it.
+func NewMergePatchRequest(patch any) (Payload, error) {
+	if patch == nil {
+		return nil, httpresponse.ErrBadRequest.With("NewMergePatchRequest: nil patch")
+	}
+	return newPatchRequest(ContentTypeMergePatch, patch)
+}
+
+func newPatchRequest(mimetype string, patch any) (Payload, error) {
+	this := new(request)
+	this.method = http.MethodPatch
+	this.mimetype = mimetype
+	this.buffer = new(bytes.Buffer)
+	if err := json.NewEncoder(this.buffer).Encode(patch); err != nil {
+		return nil, err
+	}
+	return this, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// DiffJSONPatch returns the JSON Patch operations which transform the JSON
+// encoding of from into the JSON encoding of to. Arrays of the same length are
+// compared element by element, otherwise the whole array is replaced.
+func DiffJSONPatch(from, to any) ([]PatchOp, error) {
+	a, err := normalize(from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := normalize(to)
+	if err != nil {
+		return nil, err
+	}
+	return diffJSONPatch(nil, "", a, b), nil
+}
+
+// DiffMergePatch returns the JSON Merge Patch which transforms the JSON
+// encoding of from into the JSON encoding of to. A merge patch cannot set a
+// member to null, as null removes the member, and arrays are always replaced.
+func DiffMergePatch(from, to any) (json.RawMessage, error) {
+	a, err := normalize(from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := normalize(to)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(diffMergePatch(a, b))
+}
+
+// MarshalJSON includes the from member of move and copy operations, and the
+// value of add, replace and test operations, even when the value is null
+func (op PatchOp) MarshalJSON() ([]byte, error) {
+	value := struct {
+		Op    string          `json:"op"`
+		Path  string          `json:"path"`
+		From  *string         `json:"from,omitempty"`
+		Value json.RawMessage `json:"value,omitempty"`
+	}{Op: op.Op, Path: op.Path}
+	switch op.Op {
+	case PatchMove, PatchCopy:
+		value.From = &op.From
+	case PatchAdd, PatchReplace, PatchTest:
+		data, err := json.Marshal(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		value.Value = data
+	}
+	return json.Marshal(value)
+}
+
+// Unmarshal captures the ETag of the response and decodes the JSON body into
+// the value of the resource
+func (r *Resource) Unmarshal(header http.Header, body io.Reader) error {
+	r.ETag = header.Get("ETag")
+	if r.Value == nil {
+		_, err := io.Copy(io.Discard, body)
+		return err
+	}
+	if v, ok := r.Value.(Unmarshaler); ok {
+		return v.Unmarshal(header, body)
+	}
+	if err := json.NewDecoder(body).Decode(r.Value); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+func (op PatchOp) validate() error {
+	if op.Path != "" && !strings.HasPrefix(op.Path, "/") {
+		return httpresponse.ErrBadRequest.Withf("patch: invalid path %q", op.Path)
+	}
+	switch op.Op {
+	case PatchAdd, PatchRemove, PatchReplace, PatchTest:
+		return nil
+	case PatchMove, PatchCopy:
+		if op.From != "" && !strings.HasPrefix(op.From, "/") {
+			return httpresponse.ErrBadRequest.Withf("patch: invalid from %q", op.From)
+		}
+		return nil
+	default:
+		return httpresponse.ErrBadRequest.Withf("patch: invalid op %q", op.Op)
+	}
+}
+
+// normalize returns the value decoded from its JSON encoding, so that structs,
+// maps and raw JSON compare equally
+func normalize(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var result any
+	if err := dec.Decode(&result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// diffJSONPatch appends the operations which transform a into b at path
+func diffJSONPatch(ops []PatchOp, path string, a, b any) []PatchOp {
+	switch a := a.(type) {
+	case map[string]any:
+		if b, ok := b.(map[string]any); ok {
+			for _, key := range slices.Sorted(maps.Keys(a)) {
+				if _, exists := b[key]; !exists {
+					ops = append(ops, PatchOp{Op: PatchRemove, Path: path + "/" + escapePointer(key)})
+				}
+			}
+			for _, key := range slices.Sorted(maps.Keys(b)) {
+				if value, exists := a[key]; !exists {
+					ops = append(ops, PatchOp{Op: PatchAdd, Path: path + "/" + escapePointer(key), Value: b[key]})
+				} else {
+					ops = diffJSONPatch(ops, path+"/"+escapePointer(key), value, b[key])
+				}
+			}
+			return ops
+		}
+	case []any:
+		if b, ok := b.([]any); ok && len(a) == len(b) {
+			for i := range a {
+				ops = diffJSONPatch(ops, path+"/"+strconv.Itoa(i), a[i], b[i])
+			}
+			return ops
+		}
+	}
+	if !reflect.DeepEqual(a, b) {
+		ops = append(ops, PatchOp{Op: PatchReplace, Path: path, Value: b})
+	}
+	return ops
+}
+
+// diffMergePatch returns the merge patch which transforms a into b
+func diffMergePatch(a, b any) any {
+	from, ok := a.(map[string]any)
+	if !ok {
+		return b
+	}
+	to, ok := b.(map[string]any)
+	if !ok {
+		return b
+	}
+	patch := make(map[string]any)
+	for key := range from {
+		if _, exists := to[key]; !exists {
+			patch[key] = nil
+		}
+	}
+	for key, value := range to {
+		prev, exists := from[key]
+		if _, isMap := prev.(map[string]any); isMap {
+			if _, isMap := value.(map[string]any); isMap {
+				if diff := diffMergePatch(prev, value).(map[string]any); len(diff) > 0 {
+					patch[key] = diff
+				}
+				continue
+			}
+		}
+		if !exists || !reflect.DeepEqual(prev, value) {
+			patch[key] = value
+		}
+	}
+	return patch
+}
+
+// escapePointer escapes a reference token in a JSON Pointer
+func escapePointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,176 @@
+package client_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	client "github.com/mutablelogic/go-client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// HELPERS
+
+type light struct {
+	Name       string            `json:"name"`
+	State      string            `json:"state"`
+	Brightness int               `json:"brightness,omitempty"`
+	Tags       []string          `json:"tags,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// TESTS
+
+func Test_patch_001_JSONPatchRequest(t *testing.T) {
+	payload, err := client.NewJSONPatchRequest([]client.PatchOp{
+		{Op: client.PatchReplace, Path: "/state", Value: "on"},
+		{Op: client.PatchAdd, Path: "/color", Value: nil},
+		{Op: client.PatchRemove, Path: "/brightness"},
+		{Op: client.PatchMove, Path: "/name", From: ""},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, http.MethodPatch, payload.Method())
+	assert.Equal(t, client.ContentTypeJSONPatch, payload.Type())
+
+	data, err := io.ReadAll(payload)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"op":"replace","path":"/state","value":"on"},
+		{"op":"add","path":"/color","value":null},
+		{"op":"remove","path":"/brightness"},
+		{"op":"move","path":"/name","from":""}
+	]`, string(data))
+
+	// Invalid operations
+	_, err = client.NewJSONPatchRequest([]client.PatchOp{{Op: "delete", Path: "/state"}})
+	assert.Error(t, err)
+	_, err = client.NewJSONPatchRequest([]client.PatchOp{{Op: client.PatchRemove, Path: "state"}})
+	assert.Error(t, err)
+	_, err = client.NewJSONPatchRequest([]client.PatchOp{{Op: client.PatchCopy, Path: "/a", From: "b"}})
+	assert.Error(t, err)
+}
+
+func Test_patch_002_MergePatchRequest(t *testing.T) {
+	payload, err := client.NewMergePatchRequest(map[string]any{"state": "on", "color": nil})
+	require.NoError(t, err)
+	assert.Equal(t, http.MethodPatch, payload.Method())
+	assert.Equal(t, client.ContentTypeMergePatch, payload.Type())
+
+	data, err := io.ReadAll(payload)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"state":"on","color":null}`, string(data))
+
+	_, err = client.NewMergePatchRequest(nil)
+	assert.Error(t, err)
+}
+
+func Test_patch_003_DiffJSONPatch(t *testing.T) {
+	from := light{Name: "lamp", State: "off", Brightness: 10, Tags: []string{"a", "b"}, Attributes: map[string]string{"a/b": "1", "c~d": "2"}}
+	to := light{Name: "lamp", State: "on", Tags: []string{"a", "c"}, Attributes: map[string]string{"a/b": "1", "e": "3"}}
+
+	ops, err := client.DiffJSONPatch(from, to)
+	require.NoError(t, err)
+	data, err := json.Marshal(ops)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"op":"remove","path":"/brightness"},
+		{"op":"remove","path":"/attributes/c~0d"},
+		{"op":"add","path":"/attributes/e","value":"3"},
+		{"op":"replace","path":"/state","value":"on"},
+		{"op":"replace","path":"/tags/1","value":"c"}
+	]`, string(data))
+
+	// Arrays of a different length are replaced
+	ops, err = client.DiffJSONPatch([]int{1, 2}, []int{1, 2, 3})
+	require.NoError(t, err)
+	data, err = json.Marshal(ops)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"op":"replace","path":"","value":[1,2,3]}]`, string(data))
+
+	// Identical values have no operations
+	ops, err = client.DiffJSONPatch(from, from)
+	require.NoError(t, err)
+	assert.Empty(t, ops)
+}
+
+func Test_patch_004_DiffMergePatch(t *testing.T) {
+	from := light{Name: "lamp", State: "off", Brightness: 10, Tags: []string{"a"}, Attributes: map[string]string{"a": "1", "b": "2"}}
+	to := light{Name: "lamp", State: "on", Tags: []string{"a", "b"}, Attributes: map[string]string{"a": "1", "c": "3"}}
+
+	patch, err := client.DiffMergePatch(from, to)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"state":"on",
+		"brightness":null,
+		"tags":["a","b"],
+		"attributes":{"b":null,"c":"3"}
+	}`, string(patch))
+
+	// The patch can be used as a payload
+	payload, err := client.NewMergePatchRequest(patch)
+	require.NoError(t, err)
+	data, err := io.ReadAll(payload)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(patch), string(data))
+
+	// Identical values have an empty patch
+	patch, err = client.DiffMergePatch(from, from)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{}`, string(patch))
+}
+
+func Test_patch_005_IfMatch(t *testing.T) {
+	current := light{Name: "lamp", State: "off"}
+	etag := `"1"`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("ETag", etag)
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(current)
+		case http.MethodPatch:
+			if r.Header.Get("If-Match") != etag {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				return
+			}
+			assert.Equal(t, client.ContentTypeMergePatch, r.Header.Get("Content-Type"))
+			json.NewDecoder(r.Body).Decode(&current)
+			etag = `"2"`
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := client.New(client.OptEndpoint(srv.URL))
+	require.NoError(t, err)
+
+	// Fetch the resource and its ETag
+	var value light
+	resource := client.Resource{Value: &value}
+	require.NoError(t, c.Do(nil, &resource))
+	assert.Equal(t, `"1"`, resource.ETag)
+	assert.Equal(t, current, value)
+
+	// Update the resource with a conditional request
+	updated := value
+	updated.State = "on"
+	patch, err := client.DiffMergePatch(value, updated)
+	require.NoError(t, err)
+	payload, err := client.NewMergePatchRequest(patch)
+	require.NoError(t, err)
+	require.NoError(t, c.Do(payload, nil, client.OptIfMatch(resource.ETag)))
+	assert.Equal(t, "on", current.State)
+
+	// The same update fails as the resource has changed
+	payload, err = client.NewMergePatchRequest(patch)
+	require.NoError(t, err)
+	assert.Error(t, c.Do(payload, nil, client.OptIfMatch(resource.ETag)))
+
+	// An empty ETag is an error
+	assert.Error(t, c.Do(nil, nil, client.OptIfMatch("")))
+}
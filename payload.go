@@ -77,15 +77,16 @@ func NewJSONRequestEx(method string, payload any, accept string) (Payload, error
 }
 
 // Return a new request with a Multipart Form data payload which defaults to POST. The accept
-// parameter is the accepted mime-type of the response.
-func NewMultipartRequest(payload any, accept string) (Payload, error) {
+// parameter is the accepted mime-type of the response, and options change how
+// nested fields are named.
+func NewMultipartRequest(payload any, accept string, opts ...multipart.EncoderOpt) (Payload, error) {
 	this := new(request)
 	this.method = http.MethodPost
 	this.accept = accept
 	this.buffer = new(bytes.Buffer)
 
 	// Encode the payload
-	enc := multipart.NewMultipartEncoder(this.buffer)
+	enc := multipart.NewMultipartEncoder(this.buffer, opts...)
 	defer enc.Close()
 	if err := enc.Encode(payload); err != nil {
 		return nil, err
@@ -98,15 +99,16 @@ func NewMultipartRequest(payload any, accept string) (Payload, error) {
 }
 
 // Return a new request with a Form data payload which defaults to POST. The accept
-// parameter is the accepted mime-type of the response.
-func NewFormRequest(payload any, accept string) (Payload, error) {
+// parameter is the accepted mime-type of the response, and options change how
+// nested fields are named.
+func NewFormRequest(payload any, accept string, opts ...multipart.EncoderOpt) (Payload, error) {
 	this := new(request)
 	this.method = http.MethodPost
 	this.accept = accept
 	this.buffer = new(bytes.Buffer)
 
 	// Encode the payload
-	enc := multipart.NewFormEncoder(this.buffer)
+	enc := multipart.NewFormEncoder(this.buffer, opts...)
 	defer enc.Close()
 	if err := enc.Encode(payload); err != nil {
 		return nil, err
@@ -2,14 +2,20 @@ package client_test
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	// Packages
 	"github.com/mutablelogic/go-client"
 	"github.com/mutablelogic/go-client/pkg/multipart"
+	types "github.com/mutablelogic/go-server/pkg/types"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -18,7 +24,7 @@ func Test_payload_001(t *testing.T) {
 	payload := client.NewRequest()
 	assert.NotNil(payload)
 	assert.Equal("GET", payload.Method())
-	assert.Equal(client.ContentTypeAny, payload.Accept())
+	assert.Equal(types.ContentTypeAny, payload.Accept())
 }
 
 func Test_payload_002_JSONRequest(t *testing.T) {
@@ -33,7 +39,7 @@ func Test_payload_002_JSONRequest(t *testing.T) {
 	assert.NoError(err)
 	assert.NotNil(payload)
 	assert.Equal("POST", payload.Method())
-	assert.Equal(client.ContentTypeJson, payload.Type())
+	assert.Equal(types.ContentTypeJSON, payload.Type())
 
 	// Read the body
 	body, err := io.ReadAll(payload)
@@ -50,7 +56,7 @@ func Test_payload_003_MultipartRequest(t *testing.T) {
 		Value int    `json:"value"`
 	}{"test", 42}
 
-	payload, err := client.NewMultipartRequest(data, client.ContentTypeAny)
+	payload, err := client.NewMultipartRequest(data, types.ContentTypeAny)
 	assert.NoError(err)
 	assert.NotNil(payload)
 	assert.Equal("POST", payload.Method())
@@ -80,7 +86,7 @@ func Test_payload_004_MultipartWithFile(t *testing.T) {
 		},
 	}
 
-	payload, err := client.NewMultipartRequest(data, client.ContentTypeAny)
+	payload, err := client.NewMultipartRequest(data, types.ContentTypeAny)
 	assert.NoError(err)
 	assert.NotNil(payload)
 
@@ -102,12 +108,12 @@ func Test_streaming_payload_001(t *testing.T) {
 		Value int    `json:"value"`
 	}{"streaming", 123}
 
-	payload, err := client.NewStreamingMultipartRequest(data, client.ContentTypeAny)
+	payload, err := client.NewStreamingMultipartRequest(data, types.ContentTypeAny)
 	assert.NoError(err)
 	assert.NotNil(payload)
 	assert.Equal("POST", payload.Method())
 	assert.Contains(payload.Type(), "multipart/form-data")
-	assert.Equal(client.ContentTypeAny, payload.Accept())
+	assert.Equal(types.ContentTypeAny, payload.Accept())
 
 	// Read the body
 	body, err := io.ReadAll(payload)
@@ -138,7 +144,7 @@ func Test_streaming_payload_002_WithFile(t *testing.T) {
 		},
 	}
 
-	payload, err := client.NewStreamingMultipartRequest(data, client.ContentTypeAny)
+	payload, err := client.NewStreamingMultipartRequest(data, types.ContentTypeAny)
 	assert.NoError(err)
 	assert.NotNil(payload)
 
@@ -168,7 +174,7 @@ func Test_streaming_payload_003_LargeFile(t *testing.T) {
 		},
 	}
 
-	payload, err := client.NewStreamingMultipartRequest(data, client.ContentTypeAny)
+	payload, err := client.NewStreamingMultipartRequest(data, types.ContentTypeAny)
 	assert.NoError(err)
 	assert.NotNil(payload)
 
@@ -192,7 +198,7 @@ func Test_streaming_payload_004_CloseBeforeRead(t *testing.T) {
 		Name string `json:"name"`
 	}{"test"}
 
-	payload, err := client.NewStreamingMultipartRequest(data, client.ContentTypeAny)
+	payload, err := client.NewStreamingMultipartRequest(data, types.ContentTypeAny)
 	assert.NoError(err)
 	assert.NotNil(payload)
 
@@ -215,7 +221,7 @@ func Test_streaming_payload_005_PartialRead(t *testing.T) {
 		},
 	}
 
-	payload, err := client.NewStreamingMultipartRequest(data, client.ContentTypeAny)
+	payload, err := client.NewStreamingMultipartRequest(data, types.ContentTypeAny)
 	assert.NoError(err)
 
 	// Read only a small portion (may return less than buffer size, that's valid)
@@ -230,6 +236,134 @@ func Test_streaming_payload_005_PartialRead(t *testing.T) {
 	}
 }
 
+func Test_streaming_payload_006_JSONRequest(t *testing.T) {
+	assert := assert.New(t)
+
+	data := map[string]any{"name": "test", "count": 42}
+	payload, err := client.NewStreamingJSONRequest(data, types.ContentTypeAny)
+	assert.NoError(err)
+	assert.Equal("POST", payload.Method())
+	assert.Equal("application/json", payload.Type())
+
+	body, err := io.ReadAll(payload)
+	assert.NoError(err)
+	assert.JSONEq(`{"name":"test","count":42}`, string(body))
+	assert.NoError(payload.(io.Closer).Close())
+}
+
+func Test_streaming_payload_007_JSONRequestError(t *testing.T) {
+	assert := assert.New(t)
+
+	payload, err := client.NewStreamingJSONRequestEx("PUT", make(chan int), types.ContentTypeAny)
+	assert.NoError(err)
+	assert.Equal("PUT", payload.Method())
+
+	// Encoding errors are returned from the reader
+	_, err = io.ReadAll(payload)
+	assert.Error(err)
+	assert.NoError(payload.(io.Closer).Close())
+}
+
+func Test_streaming_payload_008_NDJSONRequest(t *testing.T) {
+	assert := assert.New(t)
+
+	payload, err := client.NewNDJSONRequest(slices.Values([]int{1, 2, 3}), types.ContentTypeAny)
+	assert.NoError(err)
+	assert.Equal("POST", payload.Method())
+	assert.Equal("application/ndjson", payload.Type())
+
+	body, err := io.ReadAll(payload)
+	assert.NoError(err)
+	assert.Equal("1\n2\n3\n", string(body))
+	assert.NoError(payload.(io.Closer).Close())
+}
+
+func Test_streaming_payload_009_NDJSONChannelRequest(t *testing.T) {
+	assert := assert.New(t)
+
+	ch := make(chan string)
+	go func() {
+		defer close(ch)
+		for _, value := range []string{"a", "b"} {
+			ch <- value
+		}
+	}()
+
+	payload, err := client.NewNDJSONChannelRequest(ch, types.ContentTypeAny)
+	assert.NoError(err)
+
+	body, err := io.ReadAll(payload)
+	assert.NoError(err)
+	assert.Equal("\"a\"\n\"b\"\n", string(body))
+	assert.NoError(payload.(io.Closer).Close())
+}
+
+func Test_streaming_payload_010_NDJSONStopsOnClose(t *testing.T) {
+	assert := assert.New(t)
+
+	// An unbounded iterator should stop when the payload is closed
+	seq := func(yield func(int) bool) {
+		for i := 0; ; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+	payload, err := client.NewNDJSONRequest(seq, types.ContentTypeAny)
+	assert.NoError(err)
+
+	buf := make([]byte, 16)
+	_, err = payload.Read(buf)
+	assert.NoError(err)
+	assert.NoError(payload.(io.Closer).Close())
+}
+
+func Test_streaming_payload_011_NDJSONChannelStopsOnClose(t *testing.T) {
+	assert := assert.New(t)
+
+	// A channel which never sends should not block closing the payload
+	ch := make(chan int)
+	payload, err := client.NewNDJSONChannelRequest(ch, types.ContentTypeAny)
+	assert.NoError(err)
+
+	done := make(chan error)
+	go func() {
+		done <- payload.(io.Closer).Close()
+	}()
+	select {
+	case err := <-done:
+		assert.NoError(err)
+	case <-time.After(time.Second):
+		t.Fatal("Close blocked on the channel")
+	}
+}
+
+func Test_streaming_payload_012_NDJSONChannelFailedRequest(t *testing.T) {
+	assert := assert.New(t)
+
+	// A request which fails before the body is read should not wait for the
+	// channel
+	srv := httptest.NewServer(http.NotFoundHandler())
+	c, err := client.New(client.OptEndpoint(srv.URL))
+	assert.NoError(err)
+	srv.Close()
+
+	ch := make(chan int)
+	payload, err := client.NewNDJSONChannelRequest(ch, types.ContentTypeAny)
+	assert.NoError(err)
+
+	done := make(chan error)
+	go func() {
+		done <- c.DoWithContext(context.Background(), payload, nil)
+	}()
+	select {
+	case err := <-done:
+		assert.Error(err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("DoWithContext blocked on the channel")
+	}
+}
+
 ///////////////////////////////////////////////////////////////////////////////
 // NewFormRequest
 
@@ -239,12 +373,12 @@ func Test_payload_form_001_IsPost(t *testing.T) {
 		Name  string `json:"name"`
 		Value int    `json:"value"`
 	}{"alice", 7}
-	payload, err := client.NewFormRequest(data, client.ContentTypeAny)
+	payload, err := client.NewFormRequest(data, types.ContentTypeAny)
 	assert.NoError(err)
 	assert.NotNil(payload)
 	assert.Equal("POST", payload.Method())
 	assert.Contains(payload.Type(), "application/x-www-form-urlencoded")
-	assert.Equal(client.ContentTypeAny, payload.Accept())
+	assert.Equal(types.ContentTypeAny, payload.Accept())
 }
 
 func Test_payload_form_002_BodyContainsFields(t *testing.T) {
@@ -253,7 +387,7 @@ func Test_payload_form_002_BodyContainsFields(t *testing.T) {
 		Name  string `json:"name"`
 		Value int    `json:"value"`
 	}{"bob", 99}
-	payload, err := client.NewFormRequest(data, client.ContentTypeAny)
+	payload, err := client.NewFormRequest(data, types.ContentTypeAny)
 	assert.NoError(err)
 	body, err := io.ReadAll(payload)
 	assert.NoError(err)
@@ -262,7 +396,7 @@ func Test_payload_form_002_BodyContainsFields(t *testing.T) {
 }
 
 func Test_payload_form_003_InvalidInputErrors(t *testing.T) {
-	_, err := client.NewFormRequest("not a struct", client.ContentTypeAny)
+	_, err := client.NewFormRequest("not a struct", types.ContentTypeAny)
 	assert.Error(t, err)
 }
 
@@ -271,7 +405,7 @@ func Test_payload_form_003_InvalidInputErrors(t *testing.T) {
 
 func Test_payload_string_001_IncludesMethod(t *testing.T) {
 	assert := assert.New(t)
-	payload := client.NewRequestEx("DELETE", client.ContentTypeJson)
+	payload := client.NewRequestEx("DELETE", types.ContentTypeJSON)
 	str := payload.(fmt.Stringer).String()
 	assert.Contains(str, "DELETE")
 }
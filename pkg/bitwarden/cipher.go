@@ -0,0 +1,181 @@
+package bitwarden
+
+import (
+	"net/http"
+	"time"
+
+	// Packages
+	client "github.com/mutablelogic/go-client"
+	schema "github.com/mutablelogic/go-client/pkg/bitwarden/schema"
+	types "github.com/mutablelogic/go-server/pkg/types"
+
+	// Namespace imports
+	. "github.com/djthorpe/go-errors"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+type reqCipher struct {
+	*schema.Cipher
+	LastKnownRevisionDate *time.Time `json:"lastKnownRevisionDate,omitempty"`
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// CreateCipher encrypts a cipher and creates it in the vault, returning the
+// decrypted cipher as stored on the server. Requires the OptPassword option
+// to encrypt the cipher.
+func (c *Client) CreateCipher(cipher *schema.Cipher, opts ...RequestOpt) (*schema.Cipher, error) {
+	if cipher == nil {
+		return nil, ErrBadParameter.With("CreateCipher")
+	} else if cipher.Id != "" {
+		return nil, ErrBadParameter.Withf("CreateCipher: cipher already has an id %q", cipher.Id)
+	}
+	return c.writeCipher(http.MethodPost, cipher, nil, opts...)
+}
+
+// UpdateCipher encrypts a cipher and updates it in the vault, returning the
+// decrypted cipher as stored on the server. Requires the OptPassword option
+// to encrypt the cipher. The update fails if the cipher has been modified on
+// the server since it was last synced.
+func (c *Client) UpdateCipher(cipher *schema.Cipher, opts ...RequestOpt) (*schema.Cipher, error) {
+	if cipher == nil || cipher.Id == "" {
+		return nil, ErrBadParameter.With("UpdateCipher")
+	}
+	var revisionDate *time.Time
+	if !cipher.RevisionDate.IsZero() {
+		revisionDate = &cipher.RevisionDate
+	}
+	return c.writeCipher(http.MethodPut, cipher, revisionDate, opts...)
+}
+
+// DeleteCipher moves a cipher to the trash, or permanently deletes the cipher
+// when the OptPermanent option is used
+func (c *Client) DeleteCipher(id string, opts ...RequestOpt) error {
+	if id == "" {
+		return ErrBadParameter.With("DeleteCipher")
+	}
+	reqOpt, err := c.requestOpts(opts...)
+	if err != nil {
+		return err
+	}
+
+	// Permanent deletion removes the cipher from the cache
+	if reqOpt.permanent {
+		if err := c.client.Do(client.NewRequestEx(http.MethodDelete, types.ContentTypeAny), nil, client.OptPath("ciphers", id), c.token()); err != nil {
+			return err
+		}
+		return c.cacheCipher(id, nil)
+	}
+
+	// Otherwise mark the cached cipher as deleted
+	if err := c.client.Do(client.NewRequestEx(http.MethodPut, types.ContentTypeAny), nil, client.OptPath("ciphers", id, "delete"), c.token()); err != nil {
+		return err
+	}
+	return c.cacheCipherDeleted(id, time.Now())
+}
+
+// RestoreCipher restores a cipher from the trash
+func (c *Client) RestoreCipher(id string, opts ...RequestOpt) error {
+	var response schema.Cipher
+	if id == "" {
+		return ErrBadParameter.With("RestoreCipher")
+	} else if _, err := c.requestOpts(opts...); err != nil {
+		return err
+	}
+
+	// Restore and update the cache
+	if err := c.client.Do(client.NewRequestEx(http.MethodPut, types.ContentTypeJSON), &response, client.OptPath("ciphers", id, "restore"), c.token()); err != nil {
+		return err
+	}
+	return c.cacheCipher(id, &response)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// Encrypt and write a cipher, then update the cache and return the decrypted
+// response
+func (c *Client) writeCipher(method string, cipher *schema.Cipher, revisionDate *time.Time, opts ...RequestOpt) (*schema.Cipher, error) {
+	var response schema.Cipher
+
+	// Apply options
+	reqOpt, err := c.requestOpts(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Encrypt the cipher
+	key, err := c.cryptKey(reqOpt, cipher.OrganizationId)
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := cipher.Encrypt(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// Request -> Response
+	path := []any{"ciphers"}
+	if method == http.MethodPut {
+		path = append(path, cipher.Id)
+	}
+	if payload, err := client.NewJSONRequestEx(method, reqCipher{encrypted.(*schema.Cipher), revisionDate}, types.ContentTypeJSON); err != nil {
+		return nil, err
+	} else if err := c.client.Do(payload, &response, client.OptPath(path...), c.token()); err != nil {
+		return nil, err
+	}
+
+	// Update the cache
+	if err := c.cacheCipher(response.Id, &response); err != nil {
+		return nil, err
+	}
+
+	// Return the decrypted cipher
+	if decrypted, err := response.Decrypt(key); err != nil {
+		return nil, err
+	} else {
+		return decrypted.(*schema.Cipher), nil
+	}
+}
+
+// Replace or remove (when the cipher is nil) a cipher in storage
+func (c *Client) cacheCipher(id string, cipher *schema.Cipher) error {
+	if c.storage == nil {
+		return nil
+	} else if profile, err := c.storage.ReadProfile(); err != nil || profile == nil {
+		// If the vault has not been synced, then the next sync will populate
+		// the cache
+		return err
+	}
+	iterator, err := c.storage.ReadCiphers()
+	if err != nil {
+		return err
+	}
+	return c.storage.WriteCiphers(replaceValue(iterator, func(v *schema.Cipher) string {
+		return v.Id
+	}, id, cipher))
+}
+
+// Mark a cached cipher as deleted
+func (c *Client) cacheCipherDeleted(id string, when time.Time) error {
+	if c.storage == nil {
+		return nil
+	} else if profile, err := c.storage.ReadProfile(); err != nil || profile == nil {
+		return err
+	}
+	iterator, err := c.storage.ReadCiphers()
+	if err != nil {
+		return err
+	}
+	var ciphers schema.Ciphers
+	for cipher := iterator.Next(); cipher != nil; cipher = iterator.Next() {
+		if cipher.Id == id {
+			cipher.DeletedDate = &when
+		}
+		ciphers = append(ciphers, cipher)
+	}
+	return c.storage.WriteCiphers(ciphers)
+}
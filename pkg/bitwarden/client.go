@@ -8,8 +8,8 @@ import (
 
 	// Packages
 	client "github.com/mutablelogic/go-client"
+	crypto "github.com/mutablelogic/go-client/pkg/bitwarden/crypto"
 	schema "github.com/mutablelogic/go-client/pkg/bitwarden/schema"
-	"github.com/mutablelogic/go-server/pkg/types"
 
 	// Namespace imports
 	. "github.com/djthorpe/go-errors"
@@ -23,22 +23,31 @@ type Client struct {
 	session schema.Session
 	storage Storage
 	login   Login
+	email   string
+	profile *schema.Profile // Profile for the session, used to make the encryption key
 }
 
 type Login struct {
 	GrantType    string `json:"grant_type"`
-	Scope        string `json:"scope"`
+	Scope        string `json:"scope,omitempty"`
 	ClientId     string `json:"client_id"`
-	ClientSecret string `json:"client_secret"`
+	ClientSecret string `json:"client_secret,omitempty"`
 }
 
 type reqToken struct {
 	Login
 	*schema.Device
 
+	// Password grant
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+
+	// Refresh token grant
+	RefreshToken string `json:"refresh_token,omitempty"`
+
 	// Two-factor authentication
 	TwoFactorToken    string `json:"twoFactorToken,omitempty"`
-	TwoFactorProvider int    `json:"twoFactorProvider,omitempty"`
+	TwoFactorProvider *int   `json:"twoFactorProvider,omitempty"`
 	TwoFactorRemember int    `json:"twoFactorRemember,omitempty"`
 }
 
@@ -46,7 +55,7 @@ type respToken struct {
 	Scope                string `json:"scope"`
 	Key                  string `json:"key"`
 	PrivateKey           string `json:"PrivateKey,omitempty"`
-	RefreshToken         string `json:"refresh_token,omitempty"`
+	TwoFactorToken       string `json:"TwoFactorToken,omitempty"`
 	MasterPasswordPolicy string `json:"master_password_policy,omitempty"`
 	ForcePasswordReset   bool   `json:"force_password_reset,omitempty"`
 	ResetMasterPassword  bool   `json:"reset_master_password,omitempty"`
@@ -66,6 +75,10 @@ const (
 const (
 	defaultScope               = "api"
 	defaultGrantType           = "client_credentials"
+	defaultPasswordScope       = "api offline_access"
+	defaultPasswordGrantType   = "password"
+	defaultPasswordClientId    = "cli"
+	defaultRefreshGrantType    = "refresh_token"
 	defaultDeviceName          = "github.com/mutablelogic/go-client/pkg/bitwarden"
 	defaultClientVersionHeader = "2026.4.0"
 )
@@ -92,7 +105,7 @@ func New(opts ...client.ClientOpt) (*Client, error) {
 	}
 
 	// Check for missing parameters
-	if parent.login.ClientId == "" || parent.login.ClientSecret == "" {
+	if parent.email == "" && (parent.login.ClientId == "" || parent.login.ClientSecret == "") {
 		return nil, ErrBadParameter.With("missing credentials")
 	}
 
@@ -123,15 +136,14 @@ func New(opts ...client.ClientOpt) (*Client, error) {
 // PUBLIC METHODS
 
 // Login sets the session token. Use OptForce to request token
-// even if there is a valid token
+// even if there is a valid token. An expired token is renewed with the
+// refresh token when there is one, or else a new token is requested using
+// the API key credentials, or the email and the password set with
+// OptPassword. Use OptTwoFactor to provide a two-factor authentication code
+// when logging in with a password.
 func (c *Client) Login(opts ...RequestOpt) error {
-	var request reqToken
-	var response respToken
 	var reqOpt opt
 
-	// Set defaults
-	request.Login = c.login
-
 	// Apply options
 	for _, opt := range opts {
 		if err := opt(&reqOpt); err != nil {
@@ -144,27 +156,30 @@ func (c *Client) Login(opts ...RequestOpt) error {
 		c.session.Token = nil
 	}
 
-	// Request token if not valid
-	if !c.session.IsValid() {
-		// Request -> Response
-		request.Device = c.session.Device
-		if payload, err := client.NewFormRequest(request, types.ContentTypeJSON); err != nil {
-			return err
-		} else if err := c.client.Do(payload, &response, client.OptReqEndpoint(identityUrl), client.OptPath("connect", "token")); err != nil {
-			return err
-		}
+	// Return if the token is valid
+	if c.session.IsValid() {
+		return nil
+	}
 
-		// Update session
-		c.session.Token = &response.Token
-		c.session.Token.CreatedAt = time.Now()
-		c.session.Kdf = response.Kdf
-		if err := writeSessionTo(c.storage, &c.session); err != nil {
-			return err
+	// Renew the token, or request a new token if it cannot be renewed
+	var response *respToken
+	var err error
+	if c.session.Token != nil && c.session.Token.RefreshToken != "" {
+		response, err = c.refreshToken(c.session.Token.RefreshToken)
+	}
+	if response == nil {
+		if c.email != "" {
+			response, err = c.passwordToken(reqOpt)
+		} else {
+			response, err = c.credentialsToken()
 		}
 	}
+	if err != nil {
+		return err
+	}
 
-	// Return success
-	return nil
+	// Update and write the session
+	return c.setToken(response)
 }
 
 // Session returns the copy of the current session
@@ -176,6 +191,122 @@ func (c *Client) Session() *schema.Session {
 ///////////////////////////////////////////////////////////////////////////////
 // PRIVATE METHODS
 
+// Check the session is valid and apply request options
+func (c *Client) requestOpts(opts ...RequestOpt) (opt, error) {
+	var reqOpt opt
+
+	// Check session
+	if err := c.renew(); err != nil {
+		return reqOpt, err
+	}
+
+	// Apply options
+	for _, opt := range opts {
+		if err := opt(&reqOpt); err != nil {
+			return reqOpt, err
+		}
+	}
+
+	// Return success
+	return reqOpt, nil
+}
+
+// Renew an expired session token with the refresh token, or return an error
+// if the session has expired and there is no refresh token
+func (c *Client) renew() error {
+	if c.session.IsValid() {
+		return nil
+	} else if c.session.Token == nil || c.session.Token.RefreshToken == "" {
+		return ErrNotAuthorized.With("session token has expired")
+	}
+	if response, err := c.refreshToken(c.session.Token.RefreshToken); err != nil {
+		return err
+	} else {
+		return c.setToken(response)
+	}
+}
+
+// Update the session with a new token, keeping the refresh token and key
+// derivation parameters when they are not returned, and write the session
+func (c *Client) setToken(response *respToken) error {
+	if response.RefreshToken == "" && c.session.Token != nil {
+		response.RefreshToken = c.session.Token.RefreshToken
+	}
+	c.session.Token = &response.Token
+	c.session.Token.CreatedAt = time.Now()
+	c.profile = nil
+	if response.Kdf.Iterations != 0 {
+		c.session.Kdf = response.Kdf
+	}
+	if response.TwoFactorToken != "" {
+		c.session.TwoFactorToken = response.TwoFactorToken
+	}
+
+	// Return any error writing the session
+	return writeSessionTo(c.storage, &c.session)
+}
+
+// Return the session token as a request option
+func (c *Client) token() client.RequestOpt {
+	return client.OptToken(client.Token{
+		Scheme: c.session.Token.TokenType,
+		Value:  c.session.Token.AccessToken,
+	})
+}
+
+// Return the encryption key for the vault, or for an organization when the
+// organization is not empty. Requires the profile and the password set with
+// OptPassword. The profile is read once for the session, and the password is
+// checked by decrypting the protected key in the profile.
+func (c *Client) cryptKey(opts opt, organization string) (*crypto.CryptoKey, error) {
+	if opts.passwd == "" {
+		return nil, ErrBadParameter.With("missing password")
+	}
+	if c.profile == nil {
+		var response respSync
+		if err := c.sync(&response, opts); err != nil {
+			return nil, err
+		} else if response.Profile == nil {
+			return nil, ErrInternalAppError.With("missing profile")
+		}
+		c.profile = response.Profile
+	}
+
+	// Make the user key
+	key, err := c.profile.MakeKey(c.session.Kdf, opts.passwd)
+	if err != nil || organization == "" {
+		return key, err
+	}
+
+	// Return the organization key
+	if keys, err := c.profile.OrganizationKeys(key); err != nil {
+		return nil, err
+	} else if key, exists := keys[organization]; !exists {
+		return nil, ErrNotFound.Withf("organization %q", organization)
+	} else {
+		return key, nil
+	}
+}
+
+// Replace a value with a matching identifier, or remove the value if the
+// replacement is nil. A value is appended if there is no existing match.
+func replaceValue[T schema.Iterable](iterator schema.Iterator[T], id func(T) string, key string, value T) []T {
+	var result []T
+	var found bool
+	for v := iterator.Next(); v != nil; v = iterator.Next() {
+		if id(v) != key {
+			result = append(result, v)
+		} else if !found && value != nil {
+			result = append(result, value)
+			found = true
+		}
+	}
+	if !found && value != nil {
+		result = append(result, value)
+	}
+	return result
+}
+
 func writeSessionTo(storage Storage, session *schema.Session) error {
 	if session == nil {
 		return ErrBadParameter.With("session")
@@ -1,14 +1,19 @@
 package bitwarden_test
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	// Packages
 	opts "github.com/mutablelogic/go-client"
 	bitwarden "github.com/mutablelogic/go-client/pkg/bitwarden"
 	crypto "github.com/mutablelogic/go-client/pkg/bitwarden/crypto"
+	filestorage "github.com/mutablelogic/go-client/pkg/bitwarden/filestorage"
 	schema "github.com/mutablelogic/go-client/pkg/bitwarden/schema"
 	assert "github.com/stretchr/testify/assert"
 )
@@ -25,7 +30,8 @@ func Test_client_004(t *testing.T) {
 	assert := assert.New(t)
 
 	// Create a master key
-	key := crypto.MakeInternalKey(strings.ToLower(GetEmail(t)), GetPassword(t), 0, 100000)
+	key, err := crypto.MakeInternalKey(strings.ToLower(GetEmail(t)), GetPassword(t), 0, 100000, 0, 0)
+	assert.NoError(err)
 	assert.NotNil(key)
 	t.Logf("MakeInternalKey salt=%q iter=%v", GetEmail(t), 100000)
 	t.Logf("  => %v", key)
@@ -46,9 +52,173 @@ func Test_client_005(t *testing.T) {
 	assert.NoError(err)
 }
 
+func Test_client_006(t *testing.T) {
+	assert := assert.New(t)
+	client, err := bitwarden.New(opts.OptTrace(os.Stderr, true), bitwarden.OptFileStorage(t.TempDir()), bitwarden.OptEmail(GetEmail(t)))
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+
+	// Login with a password, prompting for a two-factor code
+	err = client.Login(bitwarden.OptPassword(GetPassword(t)), bitwarden.OptTwoFactor(func(provider schema.TwoFactorProvider, hint string) (string, error) {
+		t.Skip("two-factor authentication required:", provider, hint)
+		return "", nil
+	}))
+	assert.NoError(err)
+	assert.True(client.Session().IsValid())
+	assert.NotEmpty(client.Session().Token.RefreshToken)
+}
+
 ///////////////////////////////////////////////////////////////////////////////
 // ENVIRONMENT
 
+func Test_client_007(t *testing.T) {
+	assert := assert.New(t)
+	path := t.TempDir()
+
+	// A mock server which renews the token, and returns the vault for the
+	// renewed token
+	srv := newMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/connect/token":
+			assert.NoError(r.ParseForm())
+			assert.Equal("refresh_token", r.PostForm.Get("grant_type"))
+			assert.Equal("refresh", r.PostForm.Get("refresh_token"))
+			json.NewEncoder(w).Encode(map[string]any{"token_type": "Bearer", "access_token": "new", "expires_in": 3600})
+		case "/accounts/revision-date":
+			json.NewEncoder(w).Encode(time.Now().UnixMilli())
+		case "/sync":
+			assert.Equal("Bearer new", r.Header.Get("Authorization"))
+			json.NewEncoder(w).Encode(map[string]any{"Profile": map[string]any{"id": "user"}, "Object": "sync"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	// Store an expired session with a refresh token
+	storage, err := filestorage.New(path)
+	assert.NoError(err)
+	assert.NoError(storage.WriteSession(&schema.Session{Token: &schema.Token{
+		TokenType:    "Bearer",
+		AccessToken:  "old",
+		ExpiresIn:    60,
+		RefreshToken: "refresh",
+		CreatedAt:    time.Now().Add(-time.Hour),
+	}}))
+
+	// The token is renewed without calling Login
+	client, err := bitwarden.New(bitwarden.OptFileStorage(path), bitwarden.OptCredentials("id", "secret"), srv)
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+	profile, err := client.Sync()
+	if assert.NoError(err) {
+		assert.Equal("user", profile.Id)
+	}
+	assert.Equal("new", client.Session().Token.AccessToken)
+	assert.Equal("refresh", client.Session().Token.RefreshToken)
+
+	// The renewed session is stored
+	session, err := storage.ReadSession()
+	assert.NoError(err)
+	assert.Equal("new", session.Token.AccessToken)
+
+	// An expired session without a refresh token is an error
+	assert.NoError(storage.WriteSession(&schema.Session{Token: &schema.Token{AccessToken: "old", ExpiresIn: 60, CreatedAt: time.Now().Add(-time.Hour)}}))
+	client, err = bitwarden.New(bitwarden.OptFileStorage(path), bitwarden.OptCredentials("id", "secret"), srv)
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+	_, err = client.Sync()
+	assert.Error(err)
+}
+
+func Test_client_008(t *testing.T) {
+	assert := assert.New(t)
+	path := t.TempDir()
+
+	// A mock server which counts the vault downloads
+	revision := time.UnixMilli(time.Now().Add(-time.Hour).UnixMilli())
+	downloads := 0
+	srv := newMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/accounts/revision-date":
+			json.NewEncoder(w).Encode(revision.UnixMilli())
+		case "/sync":
+			downloads++
+			json.NewEncoder(w).Encode(map[string]any{"Profile": map[string]any{"id": "user"}, "Object": "sync"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	// Store a valid session
+	storage, err := filestorage.New(path)
+	assert.NoError(err)
+	assert.NoError(storage.WriteSession(&schema.Session{Token: &schema.Token{
+		TokenType:   "Bearer",
+		AccessToken: "token",
+		ExpiresIn:   3600,
+		CreatedAt:   time.Now(),
+	}}))
+	client, err := bitwarden.New(bitwarden.OptFileStorage(path), bitwarden.OptCredentials("id", "secret"), srv)
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+
+	// The first sync downloads the vault, and records the revision date
+	_, err = client.Sync()
+	assert.NoError(err)
+	assert.Equal(1, downloads)
+	lastSync, err := storage.LastSync()
+	assert.NoError(err)
+	assert.True(revision.Equal(lastSync))
+
+	// The vault is not downloaded again until the account changes
+	profile, err := client.Sync()
+	if assert.NoError(err) {
+		assert.Equal("user", profile.Id)
+	}
+	assert.Equal(1, downloads)
+
+	// OptForce always downloads the vault
+	_, err = client.Sync(bitwarden.OptForce())
+	assert.NoError(err)
+	assert.Equal(2, downloads)
+
+	// A change to the account downloads the vault
+	revision = revision.Add(time.Minute)
+	_, err = client.Sync()
+	assert.NoError(err)
+	assert.Equal(3, downloads)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// HELPERS
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (fn roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return fn(req)
+}
+
+// newMockServer returns a client option which sends every request to a mock
+// server with the handler
+func newMockServer(t *testing.T, handler http.HandlerFunc) opts.ClientOpt {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return opts.OptTransport(func(parent http.RoundTripper) http.RoundTripper {
+		return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			r := req.Clone(req.Context())
+			r.URL.Scheme, r.URL.Host, r.Host = "http", srv.Listener.Addr().String(), ""
+			return parent.RoundTrip(r)
+		})
+	})
+}
+
 func GetCredentials(t *testing.T) (string, string) {
 	key := os.Getenv("BW_CLIENTID")
 	secret := os.Getenv("BW_CLIENTSECRET")
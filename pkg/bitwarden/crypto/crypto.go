@@ -74,6 +74,33 @@ func NewEncrypted(s string) (*Encrypted, error) {
 	return result, nil
 }
 
+// NewEncryptedBytes returns a new Encrypted object from the binary format
+// used for files, which is the type, IV, MAC (for type 2) and value
+func NewEncryptedBytes(data []byte) (*Encrypted, error) {
+	if len(data) < 1+aes.BlockSize {
+		return nil, ErrBadParameter.With("invalid encrypted data")
+	}
+	result := &Encrypted{Type: uint(data[0])}
+	result.Iv = base64.StdEncoding.EncodeToString(data[1 : 1+aes.BlockSize])
+	data = data[1+aes.BlockSize:]
+	switch result.Type {
+	case 0:
+		// No MAC
+	case 2:
+		if len(data) < sha256.Size {
+			return nil, ErrBadParameter.With("invalid encrypted data")
+		}
+		result.Mac = base64.StdEncoding.EncodeToString(data[:sha256.Size])
+		data = data[sha256.Size:]
+	default:
+		return nil, ErrBadParameter.With("invalid encrypted type:", result.Type)
+	}
+	result.Value = base64.StdEncoding.EncodeToString(data)
+
+	// Return success
+	return result, nil
+}
+
 ///////////////////////////////////////////////////////////////////////////////
 // STRINGIFY
 
@@ -86,6 +113,27 @@ func (k *Encrypted) String() string {
 	return result
 }
 
+// Bytes returns the encrypted value in the binary format used for files
+func (k *Encrypted) Bytes() ([]byte, error) {
+	iv, err := base64.StdEncoding.DecodeString(k.Iv)
+	if err != nil {
+		return nil, err
+	}
+	mac, err := base64.StdEncoding.DecodeString(k.Mac)
+	if err != nil {
+		return nil, err
+	}
+	value, err := base64.StdEncoding.DecodeString(k.Value)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]byte, 0, 1+len(iv)+len(mac)+len(value))
+	result = append(result, byte(k.Type))
+	result = append(result, iv...)
+	result = append(result, mac...)
+	return append(result, value...), nil
+}
+
 // Return the CryptoKey as a string
 func (k *CryptoKey) String() string {
 	str := "<key "
@@ -183,6 +231,20 @@ func (k *CryptoKey) Decrypt(data *Encrypted) ([]byte, error) {
 	return value, nil
 }
 
+// EncryptStr encrypts a string and returns the encrypted value as a string,
+// or an empty string if the data is empty
+func (k *CryptoKey) EncryptStr(data string) (string, error) {
+	if data == "" {
+		return "", nil
+	} else if encrypted, err := k.Encrypt([]byte(data)); err != nil {
+		return "", err
+	} else {
+		return encrypted.String(), nil
+	}
+}
+
+// DecryptStr decrypts an encrypted string, or returns an empty string if the
+// data is empty
 func (k *CryptoKey) DecryptStr(data string) (string, error) {
 	if data == "" {
 		return "", nil
@@ -195,6 +257,27 @@ func (k *CryptoKey) DecryptStr(data string) (string, error) {
 	}
 }
 
+// DecryptKey decrypts an encrypted symmetric key, which is either a 256-bit
+// key or a 512-bit key and MAC
+func (k *CryptoKey) DecryptKey(data string) (*CryptoKey, error) {
+	encrypted, err := NewEncrypted(data)
+	if err != nil {
+		return nil, err
+	}
+	value, err := k.Decrypt(encrypted)
+	if err != nil {
+		return nil, err
+	}
+	switch len(value) {
+	case 32:
+		return NewKey(value, nil), nil
+	case 64:
+		return NewKey(value[:32], value[32:]), nil
+	default:
+		return nil, ErrBadParameter.Withf("invalid key length: %d", len(value))
+	}
+}
+
 // Check the integrity of the data using HMAC, if the key has a MAC
 func (k *CryptoKey) Check(data *Encrypted) bool {
 	if len(k.Mac) == 0 || data.Mac == "" {
@@ -11,7 +11,8 @@ import (
 
 func Test_crypto_001(t *testing.T) {
 	assert := assert.New(t)
-	key := crypto.MakeInternalKey("nobody@example.com", "p4ssw0rd", 0, 5000)
+	key, err := crypto.MakeInternalKey("nobody@example.com", "p4ssw0rd", 0, 5000, 0, 0)
+	assert.NoError(err)
 	assert.NotNil(key)
 	assert.Len(key, 32)
 
@@ -36,3 +37,53 @@ func Test_crypto_002(t *testing.T) {
 	assert.NotNil(key)
 	assert.Equal(uint(2), key.Type)
 }
+
+func Test_crypto_003(t *testing.T) {
+	assert := assert.New(t)
+	key, err := crypto.MakeInternalKey("nobody@example.com", "p4ssw0rd", 0, 5000, 0, 0)
+	assert.NoError(err)
+	k := crypto.NewKey(key, key)
+
+	encrypted, err := k.EncryptStr("hello, world")
+	assert.NoError(err)
+	assert.NotEqual("hello, world", encrypted)
+
+	decrypted, err := k.DecryptStr(encrypted)
+	assert.NoError(err)
+	assert.Equal("hello, world", decrypted)
+
+	// Empty strings are not encrypted
+	encrypted, err = k.EncryptStr("")
+	assert.NoError(err)
+	assert.Empty(encrypted)
+}
+
+func Test_crypto_004(t *testing.T) {
+	assert := assert.New(t)
+	material, err := crypto.MakeSendKey()
+	assert.NoError(err)
+	key := crypto.SendKey(material)
+
+	// Encrypt and convert to the binary format
+	encrypted, err := key.Encrypt([]byte("hello, world"))
+	assert.NoError(err)
+	data, err := encrypted.Bytes()
+	assert.NoError(err)
+	assert.Equal(byte(2), data[0])
+
+	// Convert from the binary format and decrypt
+	other, err := crypto.NewEncryptedBytes(data)
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+	assert.Equal(encrypted, other)
+	plaintext, err := key.Decrypt(other)
+	assert.NoError(err)
+	assert.Equal("hello, world", string(plaintext))
+
+	// Invalid data
+	_, err = crypto.NewEncryptedBytes(data[:10])
+	assert.Error(err)
+	_, err = crypto.NewEncryptedBytes(append([]byte{9}, data[1:]...))
+	assert.Error(err)
+}
@@ -4,33 +4,96 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
+	"math"
 
 	// Packages
 	pbkdf2 "github.com/xdg-go/pbkdf2"
+	argon2 "golang.org/x/crypto/argon2"
+	hkdf "golang.org/x/crypto/hkdf"
+
+	// Namespace imports
+	. "github.com/djthorpe/go-errors"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// GLOBALS
+
+// Key derivation functions
+const (
+	KdfPBKDF2   = 0
+	KdfArgon2id = 1
+)
+
+const (
+	sendKeyLength          = 16
+	sendPasswordIterations = 100000
 )
 
 ///////////////////////////////////////////////////////////////////////////////
 // PUBLIC METHODS
 
-func MakeInternalKey(salt, password string, kdf, iterations int) []byte {
-	if iterations == 0 || salt == "" || password == "" {
-		return nil
+// MakeInternalKey derives the master key from the salt (email address) and
+// password. For PBKDF2 the memory (in MiB) and parallelism parameters are
+// ignored.
+func MakeInternalKey(salt, password string, kdf, iterations, memory, parallelism int) ([]byte, error) {
+	if iterations <= 0 || salt == "" || password == "" {
+		return nil, ErrBadParameter.With("MakeInternalKey requires salt, password and iterations")
 	}
 	switch kdf {
-	case 0:
-		return pbkdf2.Key([]byte(password), []byte(salt), iterations, (256 / 8), sha256.New)
+	case KdfPBKDF2:
+		return pbkdf2.Key([]byte(password), []byte(salt), iterations, (256 / 8), sha256.New), nil
+	case KdfArgon2id:
+		if memory <= 0 || memory > math.MaxUint32/1024 || parallelism <= 0 || parallelism > math.MaxUint8 {
+			return nil, ErrBadParameter.Withf("MakeInternalKey: invalid argon2id parameters memory=%v parallelism=%v", memory, parallelism)
+		}
+		hash := sha256.Sum256([]byte(salt))
+		return argon2.IDKey([]byte(password), hash[:], uint32(iterations), uint32(memory)*1024, uint8(parallelism), (256 / 8)), nil
 	}
 
 	// Unsupported
-	return nil
+	return nil, ErrNotImplemented.Withf("MakeInternalKey: unsupported kdf %v", kdf)
 }
 
-func HashedPassword(salt, password string, kdf int, iterations int) string {
-	key := MakeInternalKey(salt, password, kdf, iterations)
-	if key == nil {
-		return ""
+// HashedPassword returns the master password hash used to authenticate
+// with the server
+func HashedPassword(salt, password string, kdf, iterations, memory, parallelism int) (string, error) {
+	key, err := MakeInternalKey(salt, password, kdf, iterations, memory, parallelism)
+	if err != nil {
+		return "", err
 	}
-	return base64.StdEncoding.EncodeToString(pbkdf2.Key(key, []byte(password), 1, (256 / 8), sha256.New))
+	return base64.StdEncoding.EncodeToString(pbkdf2.Key(key, []byte(password), 1, (256 / 8), sha256.New)), nil
+}
+
+// StretchKey expands a master key into the encryption and MAC keys
+func StretchKey(key []byte) *CryptoKey {
+	value := make([]byte, 32)
+	mac := make([]byte, 32)
+	hkdf.Expand(sha256.New, key, []byte("enc")).Read(value)
+	hkdf.Expand(sha256.New, key, []byte("mac")).Read(mac)
+	return NewKey(value, mac)
+}
+
+// MakeSendKey returns new random key material for a Send
+func MakeSendKey() ([]byte, error) {
+	material := make([]byte, sendKeyLength)
+	if _, err := rand.Read(material); err != nil {
+		return nil, err
+	}
+	return material, nil
+}
+
+// SendKey derives the encryption and MAC keys for a Send from its key
+// material
+func SendKey(material []byte) *CryptoKey {
+	value := make([]byte, 64)
+	hkdf.New(sha256.New, material, []byte("bitwarden-send"), []byte("send")).Read(value)
+	return NewKey(value[:32], value[32:])
+}
+
+// HashedSendPassword returns the hash of the password used to access a Send,
+// which is salted with the key material of the Send
+func HashedSendPassword(password string, material []byte) string {
+	return base64.StdEncoding.EncodeToString(pbkdf2.Key([]byte(password), material, sendPasswordIterations, (256 / 8), sha256.New))
 }
 
 func MakeEncKey(key []byte) (*Encrypted, error) {
@@ -11,7 +11,8 @@ import (
 
 func Test_key_001(t *testing.T) {
 	assert := assert.New(t)
-	key := crypto.MakeInternalKey("nobody@example.com", "p4ssw0rd", 0, 5000)
+	key, err := crypto.MakeInternalKey("nobody@example.com", "p4ssw0rd", 0, 5000, 0, 0)
+	assert.NoError(err)
 	assert.NotNil(key)
 	assert.Len(key, 32)
 	assert.Equal(`"\x13\x88j`+"`"+`\x99m\xe3FA\x94\xee'\xf0\xb2\x1a!\xb6>\\)\xf4\xd5\xca#\xe5\x1b\xa6f5o{\xaa"`, fmt.Sprintf("%q", key))
@@ -19,15 +20,101 @@ func Test_key_001(t *testing.T) {
 
 func Test_key_002(t *testing.T) {
 	assert := assert.New(t)
-	hash := crypto.HashedPassword("nobody@example.com", "p4ssw0rd", 0, 5000)
+	hash, err := crypto.HashedPassword("nobody@example.com", "p4ssw0rd", 0, 5000, 0, 0)
+	assert.NoError(err)
 	assert.Equal(`r5CFRR+n9NQI8a525FY+0BPR0HGOjVJX0cR1KEMnIOo=`, hash)
 }
 
 func Test_key_003(t *testing.T) {
 	assert := assert.New(t)
-	key := crypto.MakeInternalKey("nobody@example.com", "p4ssw0rd", 0, 5000)
+	key, err := crypto.MakeInternalKey("nobody@example.com", "p4ssw0rd", 0, 5000, 0, 0)
+	assert.NoError(err)
 	enckey, err := crypto.MakeEncKey(key)
 	assert.NoError(err)
 	assert.NotNil(enckey)
 	t.Log(enckey)
 }
+
+func Test_key_004(t *testing.T) {
+	assert := assert.New(t)
+	key, err := crypto.MakeInternalKey("nobody@example.com", "p4ssw0rd", 0, 5000, 0, 0)
+	assert.NoError(err)
+	userKey := crypto.NewKey(key, key)
+
+	// Wrap a new 512-bit key with the user key, and unwrap it
+	enckey, err := crypto.MakeEncKey(append(key, key...))
+	assert.NoError(err)
+	itemKey, err := userKey.DecryptKey(enckey.String())
+	assert.NoError(err)
+	assert.Len(itemKey.Key, 32)
+	assert.Len(itemKey.Mac, 32)
+
+	// Invalid key
+	_, err = userKey.DecryptKey("invalid")
+	assert.Error(err)
+}
+
+func Test_key_005(t *testing.T) {
+	assert := assert.New(t)
+
+	// Argon2id requires memory and parallelism
+	key, err := crypto.MakeInternalKey("nobody@example.com", "p4ssw0rd", crypto.KdfArgon2id, 3, 16, 4)
+	assert.NoError(err)
+	assert.Len(key, 32)
+
+	// The key is different from PBKDF2 and deterministic
+	other, err := crypto.MakeInternalKey("nobody@example.com", "p4ssw0rd", crypto.KdfPBKDF2, 3, 16, 4)
+	assert.NoError(err)
+	assert.NotEqual(key, other)
+	other, err = crypto.MakeInternalKey("nobody@example.com", "p4ssw0rd", crypto.KdfArgon2id, 3, 16, 4)
+	assert.NoError(err)
+	assert.Equal(key, other)
+
+	// Hashed password
+	hash, err := crypto.HashedPassword("nobody@example.com", "p4ssw0rd", crypto.KdfArgon2id, 3, 16, 4)
+	assert.NoError(err)
+	assert.NotEmpty(hash)
+
+	// Missing parameters
+	_, err = crypto.MakeInternalKey("nobody@example.com", "p4ssw0rd", crypto.KdfArgon2id, 3, 0, 4)
+	assert.Error(err)
+	_, err = crypto.MakeInternalKey("nobody@example.com", "p4ssw0rd", crypto.KdfArgon2id, 3, 16, 0)
+	assert.Error(err)
+}
+
+func Test_key_006(t *testing.T) {
+	assert := assert.New(t)
+
+	// Unsupported KDF returns an error rather than a nil key
+	key, err := crypto.MakeInternalKey("nobody@example.com", "p4ssw0rd", 99, 5000, 0, 0)
+	assert.Error(err)
+	assert.Nil(key)
+
+	hash, err := crypto.HashedPassword("nobody@example.com", "p4ssw0rd", 99, 5000, 0, 0)
+	assert.Error(err)
+	assert.Empty(hash)
+}
+
+func Test_key_007(t *testing.T) {
+	assert := assert.New(t)
+
+	// Send key material is random
+	material, err := crypto.MakeSendKey()
+	assert.NoError(err)
+	assert.Len(material, 16)
+	other, err := crypto.MakeSendKey()
+	assert.NoError(err)
+	assert.NotEqual(material, other)
+
+	// Send keys are derived from the key material
+	key := crypto.SendKey(material)
+	assert.Len(key.Key, 32)
+	assert.Len(key.Mac, 32)
+	assert.Equal(key, crypto.SendKey(material))
+	assert.NotEqual(key, crypto.SendKey(other))
+
+	// Send passwords are salted with the key material
+	hash := crypto.HashedSendPassword("p4ssw0rd", material)
+	assert.Equal(hash, crypto.HashedSendPassword("p4ssw0rd", material))
+	assert.NotEqual(hash, crypto.HashedSendPassword("p4ssw0rd", other))
+}
@@ -0,0 +1,77 @@
+package crypto
+
+import (
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"hash"
+	"strconv"
+	"strings"
+
+	// Namespace imports
+	. "github.com/djthorpe/go-errors"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// GLOBALS
+
+// Asymmetric encryption types
+const (
+	EncTypeRsa2048OaepSha256           = 3
+	EncTypeRsa2048OaepSha1             = 4
+	EncTypeRsa2048OaepSha256HmacSha256 = 5
+	EncTypeRsa2048OaepSha1HmacSha256   = 6
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// ParsePrivateKey parses a PKCS#8 DER-encoded RSA private key
+func ParsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	key, err := x509.ParsePKCS8PrivateKey(data)
+	if err != nil {
+		return nil, err
+	} else if key, ok := key.(*rsa.PrivateKey); !ok {
+		return nil, ErrBadParameter.With("private key is not an RSA key")
+	} else {
+		return key, nil
+	}
+}
+
+// DecryptAsymmetric decrypts a value encrypted with an RSA public key, which
+// is a string in the form "<type>.<data>" or "<type>.<data>|<mac>"
+func DecryptAsymmetric(key *rsa.PrivateKey, data string) ([]byte, error) {
+	if key == nil {
+		return nil, ErrBadParameter.With("missing private key")
+	}
+
+	// Parse the type and data
+	parts := strings.SplitN(data, ".", 2)
+	if len(parts) != 2 {
+		return nil, ErrBadParameter.Withf("Invalid encrypted: %q", data)
+	}
+	typ, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil {
+		return nil, ErrBadParameter.Withf("Invalid encrypted type: %q", parts[0])
+	}
+	value, err := base64.StdEncoding.DecodeString(strings.SplitN(parts[1], "|", 2)[0])
+	if err != nil {
+		return nil, err
+	}
+
+	// Determine the hash
+	var h hash.Hash
+	switch typ {
+	case EncTypeRsa2048OaepSha256, EncTypeRsa2048OaepSha256HmacSha256:
+		h = sha256.New()
+	case EncTypeRsa2048OaepSha1, EncTypeRsa2048OaepSha1HmacSha256:
+		h = sha1.New()
+	default:
+		return nil, ErrBadParameter.With("invalid encrypted type:", typ)
+	}
+
+	// Decrypt
+	return rsa.DecryptOAEP(h, nil, key, value, nil)
+}
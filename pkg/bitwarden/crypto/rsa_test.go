@@ -0,0 +1,48 @@
+package crypto_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"testing"
+
+	// Packages
+	"github.com/mutablelogic/go-client/pkg/bitwarden/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_rsa_001(t *testing.T) {
+	assert := assert.New(t)
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+
+	// Parse a PKCS#8 private key
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(err)
+	parsed, err := crypto.ParsePrivateKey(der)
+	assert.NoError(err)
+	assert.True(key.Equal(parsed))
+
+	// Decrypt with SHA1
+	data, err := rsa.EncryptOAEP(sha1.New(), rand.Reader, &key.PublicKey, []byte("hello, world"), nil)
+	assert.NoError(err)
+	value, err := crypto.DecryptAsymmetric(parsed, "4."+base64.StdEncoding.EncodeToString(data))
+	assert.NoError(err)
+	assert.Equal("hello, world", string(value))
+
+	// Decrypt with SHA256
+	data, err = rsa.EncryptOAEP(sha256.New(), rand.Reader, &key.PublicKey, []byte("hello, world"), nil)
+	assert.NoError(err)
+	value, err = crypto.DecryptAsymmetric(parsed, "3."+base64.StdEncoding.EncodeToString(data))
+	assert.NoError(err)
+	assert.Equal("hello, world", string(value))
+
+	// Invalid type
+	_, err = crypto.DecryptAsymmetric(parsed, "2."+base64.StdEncoding.EncodeToString(data))
+	assert.Error(err)
+}
@@ -0,0 +1,321 @@
+package bitwarden
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/url"
+
+	// Packages
+	client "github.com/mutablelogic/go-client"
+	crypto "github.com/mutablelogic/go-client/pkg/bitwarden/crypto"
+	schema "github.com/mutablelogic/go-client/pkg/bitwarden/schema"
+
+	// Namespace imports
+	. "github.com/djthorpe/go-errors"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// ExportFormat is the file format for vault export and import
+type ExportFormat uint
+
+type reqImport struct {
+	Ciphers                 []*schema.Cipher  `json:"ciphers"`
+	Folders                 []reqFolder       `json:"folders,omitempty"`
+	FolderRelationships     []reqRelationship `json:"folderRelationships,omitempty"`
+	Collections             []reqCollection   `json:"collections,omitempty"`
+	CollectionRelationships []reqRelationship `json:"collectionRelationships,omitempty"`
+}
+
+type reqCollection struct {
+	Name           string `json:"name"`
+	OrganizationId string `json:"organizationId"`
+}
+
+// Relationship between a cipher (key) and a folder or collection (value) by
+// index in the import request
+type reqRelationship struct {
+	Key   int `json:"key"`
+	Value int `json:"value"`
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// GLOBALS
+
+const (
+	ExportJSON          ExportFormat = iota // Unencrypted JSON
+	ExportEncryptedJSON                     // Password-protected JSON
+	ExportCSV                               // CSV, login and secure note items only
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// STRINGIFY
+
+func (f ExportFormat) String() string {
+	switch f {
+	case ExportJSON:
+		return "json"
+	case ExportEncryptedJSON:
+		return "encrypted_json"
+	case ExportCSV:
+		return "csv"
+	default:
+		return "unknown"
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Export writes the personal vault, or the items in an organization with the
+// OptOrganization option, to a writer. Items in the trash are not exported.
+// Requires the OptPassword option to decrypt the vault, and the
+// OptExportPassword option for the ExportEncryptedJSON format.
+func (c *Client) Export(w io.Writer, format ExportFormat, opts ...RequestOpt) error {
+	reqOpt, err := c.requestOpts(opts...)
+	if err != nil {
+		return err
+	} else if reqOpt.passwd == "" {
+		return ErrBadParameter.With("missing password")
+	} else if format == ExportEncryptedJSON && reqOpt.exportPasswd == "" {
+		return ErrBadParameter.With("missing export password")
+	}
+
+	// Decrypt the vault
+	export, err := c.export(reqOpt.organization, opts...)
+	if err != nil {
+		return err
+	}
+
+	// Write the export
+	switch format {
+	case ExportJSON:
+		return export.Write(w)
+	case ExportEncryptedJSON:
+		if encrypted, err := export.Encrypt(reqOpt.exportPasswd, c.session.Kdf); err != nil {
+			return err
+		} else {
+			return encrypted.Write(w)
+		}
+	case ExportCSV:
+		return export.WriteCSV(w)
+	default:
+		return ErrBadParameter.Withf("unsupported export format: %v", format)
+	}
+}
+
+// Import reads an export and creates the folders and items in the personal
+// vault, or the collections and items in an organization with the
+// OptOrganization option. Requires the OptPassword option to encrypt the
+// items, and the OptExportPassword option to read a password-protected
+// export.
+func (c *Client) Import(r io.Reader, format ExportFormat, opts ...RequestOpt) error {
+	var export *schema.Export
+	reqOpt, err := c.requestOpts(opts...)
+	if err != nil {
+		return err
+	}
+
+	// Read the export
+	switch format {
+	case ExportJSON, ExportEncryptedJSON:
+		if v, err := readExport(r, reqOpt.exportPasswd); err != nil {
+			return err
+		} else {
+			export = v
+		}
+	case ExportCSV:
+		export = new(schema.Export)
+		if err := export.ReadCSV(r); err != nil {
+			return err
+		}
+	default:
+		return ErrBadParameter.Withf("unsupported import format: %v", format)
+	}
+
+	// Make the request with the encrypted items
+	key, err := c.cryptKey(reqOpt, reqOpt.organization)
+	if err != nil {
+		return err
+	}
+	request, err := makeImport(export, reqOpt.organization, key)
+	if err != nil {
+		return err
+	}
+
+	// Import the items
+	path := []any{"ciphers", "import"}
+	var query url.Values
+	if reqOpt.organization != "" {
+		path = []any{"ciphers", "import-organization"}
+		query = url.Values{"organizationId": []string{reqOpt.organization}}
+	}
+	if payload, err := client.NewJSONRequest(request); err != nil {
+		return err
+	} else if err := c.client.Do(payload, nil, client.OptPath(path...), client.OptQuery(query), c.token()); err != nil {
+		return err
+	}
+
+	// Update the cache with the imported items
+	if c.storage != nil {
+		return c.sync(new(respSync), opt{force: true})
+	}
+
+	// Return success
+	return nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// Return the decrypted personal vault, or the decrypted items in an
+// organization
+func (c *Client) export(organization string, opts ...RequestOpt) (*schema.Export, error) {
+	export := new(schema.Export)
+
+	// Folders for a personal export, or collections for an organization
+	if organization == "" {
+		folders, err := c.Folders(opts...)
+		if err != nil {
+			return nil, err
+		}
+		export.Folders = schema.Folders{}
+		for folder := folders.Next(); folder != nil; folder = folders.Next() {
+			if folder, err := folders.Decrypt(folder); err != nil {
+				return nil, err
+			} else {
+				export.Folders = append(export.Folders, folder)
+			}
+		}
+	} else {
+		collections, err := c.Collections(opts...)
+		if err != nil {
+			return nil, err
+		}
+		export.Collections = schema.Collections{}
+		for collection := collections.Next(); collection != nil; collection = collections.Next() {
+			if collection.OrganizationId != organization {
+				continue
+			} else if collection, err := collections.Decrypt(collection); err != nil {
+				return nil, err
+			} else {
+				export.Collections = append(export.Collections, collection)
+			}
+		}
+	}
+
+	// Items which are not in the trash
+	ciphers, err := c.Ciphers(opts...)
+	if err != nil {
+		return nil, err
+	}
+	export.Items = schema.Ciphers{}
+	for cipher := ciphers.Next(); cipher != nil; cipher = ciphers.Next() {
+		if cipher.OrganizationId != organization || cipher.DeletedDate != nil {
+			continue
+		} else if cipher, err := ciphers.Decrypt(cipher); err != nil {
+			return nil, err
+		} else {
+			export.Items = append(export.Items, cipher)
+		}
+	}
+
+	// Return success
+	return export, nil
+}
+
+// Read an unencrypted or password-protected export
+func readExport(r io.Reader, passwd string) (*schema.Export, error) {
+	var header struct {
+		Encrypted         bool `json:"encrypted"`
+		PasswordProtected bool `json:"passwordProtected"`
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	} else if err := json.Unmarshal(data, &header); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case !header.Encrypted:
+		export := new(schema.Export)
+		if err := export.Read(bytes.NewReader(data)); err != nil {
+			return nil, err
+		}
+		return export, nil
+	case header.PasswordProtected:
+		var encrypted schema.EncryptedExport
+		if passwd == "" {
+			return nil, ErrBadParameter.With("missing export password")
+		} else if err := encrypted.Read(bytes.NewReader(data)); err != nil {
+			return nil, err
+		}
+		return encrypted.Decrypt(passwd)
+	default:
+		return nil, ErrNotImplemented.With("account restricted exports cannot be imported")
+	}
+}
+
+// Make an import request, encrypting the names and items, and relating
+// the items to folders or collections by index
+func makeImport(export *schema.Export, organization string, key *crypto.CryptoKey) (*reqImport, error) {
+	request := new(reqImport)
+
+	// Folders or collections, indexed by identifier
+	folders := make(map[string]int)
+	collections := make(map[string]int)
+	if organization == "" {
+		for _, folder := range export.Folders {
+			if name, err := key.EncryptStr(folder.Name); err != nil {
+				return nil, err
+			} else {
+				folders[folder.Id] = len(request.Folders)
+				request.Folders = append(request.Folders, reqFolder{name})
+			}
+		}
+	} else {
+		for _, collection := range export.Collections {
+			if name, err := key.EncryptStr(collection.Name); err != nil {
+				return nil, err
+			} else {
+				collections[collection.Id] = len(request.Collections)
+				request.Collections = append(request.Collections, reqCollection{name, organization})
+			}
+		}
+	}
+
+	// Items, which are created without identifiers, item keys or attachments
+	request.Ciphers = make([]*schema.Cipher, 0, len(export.Items))
+	for _, item := range export.Items {
+		cipher := *item
+		cipher.Id = ""
+		cipher.Key = ""
+		cipher.FolderId = ""
+		cipher.CollectionIds = nil
+		cipher.Attachments = nil
+		cipher.DeletedDate = nil
+		cipher.OrganizationId = organization
+		encrypted, err := cipher.Encrypt(key)
+		if err != nil {
+			return nil, err
+		}
+
+		// Relate the item to folders and collections
+		index := len(request.Ciphers)
+		if folder, exists := folders[item.FolderId]; exists && item.FolderId != "" {
+			request.FolderRelationships = append(request.FolderRelationships, reqRelationship{index, folder})
+		}
+		for _, id := range item.CollectionIds {
+			if collection, exists := collections[id]; exists {
+				request.CollectionRelationships = append(request.CollectionRelationships, reqRelationship{index, collection})
+			}
+		}
+		request.Ciphers = append(request.Ciphers, encrypted.(*schema.Cipher))
+	}
+
+	// Return success
+	return request, nil
+}
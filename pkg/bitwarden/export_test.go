@@ -0,0 +1,38 @@
+package bitwarden_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	// Packages
+	opts "github.com/mutablelogic/go-client"
+	bitwarden "github.com/mutablelogic/go-client/pkg/bitwarden"
+	schema "github.com/mutablelogic/go-client/pkg/bitwarden/schema"
+	assert "github.com/stretchr/testify/assert"
+)
+
+func Test_export_001(t *testing.T) {
+	assert := assert.New(t)
+	client, err := bitwarden.New(opts.OptTrace(os.Stderr, true), bitwarden.OptFileStorage(t.TempDir()), bitwarden.OptCredentials(GetCredentials(t)), bitwarden.OptDevice(schema.Device{
+		Name:       "mydevice",
+		Identifier: GetIdentifier(t),
+	}))
+	assert.NoError(err)
+	password := GetPassword(t)
+
+	// Login
+	if err := client.Login(); !assert.NoError(err) {
+		t.SkipNow()
+	}
+
+	// Export the vault, and decrypt the encrypted export
+	var buf bytes.Buffer
+	assert.NoError(client.Export(&buf, bitwarden.ExportEncryptedJSON, bitwarden.OptPassword(password), bitwarden.OptExportPassword(t.Name())))
+	var encrypted schema.EncryptedExport
+	assert.NoError(encrypted.Read(&buf))
+	export, err := encrypted.Decrypt(t.Name())
+	if assert.NoError(err) {
+		t.Logf("Exported %d folders and %d items", len(export.Folders), len(export.Items))
+	}
+}
@@ -1,11 +1,21 @@
 package filestorage
 
 import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io"
 	"os"
 	"path/filepath"
+	"time"
 
 	// Packages
+	crypto "github.com/mutablelogic/go-client/pkg/bitwarden/crypto"
 	schema "github.com/mutablelogic/go-client/pkg/bitwarden/schema"
+
+	// Namespace imports
+	. "github.com/djthorpe/go-errors"
 )
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -13,16 +23,39 @@ import (
 
 type fileStorage struct {
 	cachePath string
+
+	// Key used to seal files, or nil if files are not encrypted
+	key *crypto.CryptoKey
+}
+
+type syncState struct {
+	LastSync time.Time `json:"lastSync"`
+}
+
+type reader interface {
+	Read(io.Reader) error
+}
+
+type writer interface {
+	Write(io.Writer) error
 }
 
 ////////////////////////////////////////////////////////////////////////////////
 // GLOBALS
 
 const (
-	fileNameSession = "session.json"
-	fileNameProfile = "profile.json"
-	fileNameFolders = "folders.json"
-	fileNameCiphers = "ciphers.json"
+	fileNameSession     = "session.json"
+	fileNameProfile     = "profile.json"
+	fileNameFolders     = "folders.json"
+	fileNameCiphers     = "ciphers.json"
+	fileNameCollections = "collections.json"
+	fileNameSync        = "sync.json"
+	fileNameSalt        = "salt"
+)
+
+const (
+	fileMode = 0600
+	saltSize = 16
 )
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -36,101 +69,97 @@ func New(path string) (*fileStorage, error) {
 	}, nil
 }
 
+// NewEncrypted creates a file storage object which encrypts files at rest,
+// with a key returned by the key provider. The cache path must be a directory
+// and exist. A random salt for key derivation is stored alongside the files.
+// Unencrypted files written by storage created with New are treated as missing,
+// and are replaced with encrypted files when next written.
+func NewEncrypted(path string, provider KeyProvider) (*fileStorage, error) {
+	if provider == nil {
+		return nil, ErrBadParameter.With("missing key provider")
+	}
+	f := &fileStorage{
+		cachePath: path,
+	}
+
+	// Read or create the salt
+	salt, err := f.salt()
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the key
+	if key, err := provider.Key(salt); err != nil {
+		return nil, err
+	} else if key == nil || len(key.Key) != 32 || len(key.Mac) != 32 {
+		return nil, ErrBadParameter.With("invalid key returned by key provider")
+	} else {
+		f.key = key
+	}
+
+	// Return success
+	return f, nil
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // PUBLIC METHODS
 
 // Read the session from storage for a session, returns nil if there is no session
 func (f *fileStorage) ReadSession() (*schema.Session, error) {
 	session := schema.NewSession()
-
-	// Read the session file - return nil if it doesn't exist
-	fileName := filepath.Join(f.cachePath, fileNameSession)
-	if _, err := os.Stat(fileName); os.IsNotExist(err) {
-		return nil, nil
-	}
-	r, err := os.Open(fileName)
-	if err != nil {
+	if exists, err := f.read(fileNameSession, session); err != nil || !exists {
 		return nil, err
 	}
-	defer r.Close()
-	if err := session.Read(r); err != nil {
-		return nil, err
-	}
-
-	// Return the session
 	return session, nil
 }
 
 // Write the session to storage
 func (f *fileStorage) WriteSession(s *schema.Session) error {
-	// Create the session file
-	fileName := filepath.Join(f.cachePath, fileNameSession)
-	w, err := os.Create(fileName)
-	if err != nil {
-		return err
-	}
-	defer w.Close()
-
-	// Write the session
-	return s.Write(w)
+	return f.write(fileNameSession, s)
 }
 
-// Read the profile from storage
+// Read the profile from storage, returns nil if there is no profile
 func (f *fileStorage) ReadProfile() (*schema.Profile, error) {
 	profile := schema.NewProfile()
-
-	// Read the profile file - return nil if it doesn't exist
-	fileName := filepath.Join(f.cachePath, fileNameProfile)
-	if _, err := os.Stat(fileName); os.IsNotExist(err) {
-		return nil, nil
-	}
-	r, err := os.Open(fileName)
-	if err != nil {
+	if exists, err := f.read(fileNameProfile, profile); err != nil || !exists {
 		return nil, err
 	}
-	defer r.Close()
-	if err := profile.Read(r); err != nil {
-		return nil, err
-	}
-
-	// Return the session
 	return profile, nil
 }
 
 // Write the profile to storage
 func (f *fileStorage) WriteProfile(p *schema.Profile) error {
-	// Create the profile
-	fileName := filepath.Join(f.cachePath, fileNameProfile)
-	w, err := os.Create(fileName)
-	if err != nil {
-		return err
-	}
-	defer w.Close()
-	return p.Write(w)
+	return f.write(fileNameProfile, p)
 }
 
 // Write the folders to storage
 func (f *fileStorage) WriteFolders(v schema.Folders) error {
-	// Create the folders
-	fileName := filepath.Join(f.cachePath, fileNameFolders)
-	w, err := os.Create(fileName)
-	if err != nil {
-		return err
-	}
-	defer w.Close()
-	return v.Write(w)
+	return f.write(fileNameFolders, &v)
 }
 
 // Write the ciphers to storage
 func (f *fileStorage) WriteCiphers(v schema.Ciphers) error {
-	// Create the ciphers
-	fileName := filepath.Join(f.cachePath, fileNameCiphers)
-	w, err := os.Create(fileName)
-	if err != nil {
-		return err
+	return f.write(fileNameCiphers, &v)
+}
+
+// Write the collections to storage
+func (f *fileStorage) WriteCollections(v schema.Collections) error {
+	return f.write(fileNameCollections, &v)
+}
+
+// Return the account revision date of the last sync, or zero time if the
+// vault has not been synced
+func (f *fileStorage) LastSync() (time.Time, error) {
+	var state syncState
+	if _, err := f.read(fileNameSync, &state); err != nil {
+		return time.Time{}, err
 	}
-	defer w.Close()
-	return v.Write(w)
+	return state.LastSync, nil
+}
+
+// Write the account revision date of the last sync
+func (f *fileStorage) WriteLastSync(v time.Time) error {
+	return f.write(fileNameSync, &syncState{LastSync: v})
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -138,36 +167,151 @@ func (f *fileStorage) WriteCiphers(v schema.Ciphers) error {
 
 // Read ciphers and return an iterator
 func (f *fileStorage) ReadCiphers() (schema.Iterator[*schema.Cipher], error) {
-	// Read the ciphers file
 	ciphers := schema.Ciphers{}
-	fileName := filepath.Join(f.cachePath, fileNameCiphers)
-	r, err := os.Open(fileName)
-	if err != nil {
-		return nil, err
-	}
-	defer r.Close()
-	if err := ciphers.Read(r); err != nil {
+	if err := f.mustRead(fileNameCiphers, &ciphers); err != nil {
 		return nil, err
 	}
-
-	// Return an iterator
 	return schema.NewIterator[*schema.Cipher](ciphers), nil
 }
 
 // Read folders and return an iterator
 func (f *fileStorage) ReadFolders() (schema.Iterator[*schema.Folder], error) {
-	// Read the folders file
 	folders := schema.Folders{}
-	fileName := filepath.Join(f.cachePath, fileNameFolders)
-	r, err := os.Open(fileName)
-	if err != nil {
+	if err := f.mustRead(fileNameFolders, &folders); err != nil {
 		return nil, err
 	}
-	defer r.Close()
-	if err := folders.Read(r); err != nil {
+	return schema.NewIterator[*schema.Folder](folders), nil
+}
+
+// Read collections and return an iterator
+func (f *fileStorage) ReadCollections() (schema.Iterator[*schema.Collection], error) {
+	collections := schema.Collections{}
+	if err := f.mustRead(fileNameCollections, &collections); err != nil {
 		return nil, err
 	}
+	return schema.NewIterator[*schema.Collection](collections), nil
+}
 
-	// Return an iterator
-	return schema.NewIterator[*schema.Folder](folders), nil
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// Read a file into v, decrypting it if the storage is encrypted. Returns
+// false if the file does not exist.
+func (f *fileStorage) read(name string, v reader) (bool, error) {
+	data, err := os.ReadFile(filepath.Join(f.cachePath, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	// Decrypt the data. A file written by unencrypted storage is not trusted,
+	// and is treated as missing so that it is replaced. Sealed files must have
+	// a MAC, so that they can't be replaced by a file without one.
+	if f.key != nil {
+		if json.Valid(data) {
+			return false, nil
+		} else if encrypted, err := crypto.NewEncrypted(string(data)); err != nil {
+			return false, ErrUnexpectedResponse.Withf("%q is not encrypted", name)
+		} else if encrypted.Type != 2 || encrypted.Mac == "" {
+			return false, ErrNotAuthorized.Withf("%q is not authenticated", name)
+		} else if data, err = f.key.Decrypt(encrypted); err != nil {
+			return false, ErrNotAuthorized.Withf("unable to decrypt %q", name)
+		}
+	}
+
+	// Return success
+	return true, v.Read(bytes.NewReader(data))
+}
+
+// Read a file into v, returning an error if the file does not exist
+func (f *fileStorage) mustRead(name string, v reader) error {
+	if exists, err := f.read(name, v); err != nil {
+		return err
+	} else if !exists {
+		return ErrNotFound.Withf("%q not found", name)
+	}
+	return nil
+}
+
+// Write v to a file, encrypting it if the storage is encrypted. The file is
+// written to a temporary file which replaces the existing file, so that the
+// file is never partially written.
+func (f *fileStorage) write(name string, v writer) error {
+	var data bytes.Buffer
+	if err := v.Write(&data); err != nil {
+		return err
+	}
+
+	// Encrypt the data
+	if f.key != nil {
+		encrypted, err := f.key.Encrypt(data.Bytes())
+		if err != nil {
+			return err
+		}
+		data.Reset()
+		data.WriteString(encrypted.String())
+	}
+
+	// Write the file
+	return writeFile(filepath.Join(f.cachePath, name), data.Bytes())
+}
+
+// Read the salt, or create it if it does not exist
+func (f *fileStorage) salt() ([]byte, error) {
+	fileName := filepath.Join(f.cachePath, fileNameSalt)
+	if salt, err := os.ReadFile(fileName); err == nil {
+		if len(salt) != saltSize {
+			return nil, ErrUnexpectedResponse.Withf("invalid salt in %q", fileName)
+		}
+		return salt, nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	// Create the salt
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	if err := writeFile(fileName, salt); err != nil {
+		return nil, err
+	}
+
+	// Return success
+	return salt, nil
+}
+
+func (s *syncState) Read(r io.Reader) error {
+	return json.NewDecoder(r).Decode(s)
+}
+
+func (s *syncState) Write(w io.Writer) error {
+	return json.NewEncoder(w).Encode(s)
+}
+
+// Atomically write a file which is only readable by the user
+func writeFile(fileName string, data []byte) error {
+	w, err := os.CreateTemp(filepath.Dir(fileName), "."+filepath.Base(fileName)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(w.Name())
+
+	// Write and sync the temporary file
+	if err := w.Chmod(fileMode); err != nil {
+		w.Close()
+		return err
+	} else if _, err := w.Write(data); err != nil {
+		w.Close()
+		return err
+	} else if err := w.Sync(); err != nil {
+		w.Close()
+		return err
+	} else if err := w.Close(); err != nil {
+		return err
+	}
+
+	// Replace the file
+	return os.Rename(w.Name(), fileName)
 }
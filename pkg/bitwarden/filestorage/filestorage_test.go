@@ -0,0 +1,177 @@
+package filestorage_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	// Packages
+	crypto "github.com/mutablelogic/go-client/pkg/bitwarden/crypto"
+	filestorage "github.com/mutablelogic/go-client/pkg/bitwarden/filestorage"
+	schema "github.com/mutablelogic/go-client/pkg/bitwarden/schema"
+	assert "github.com/stretchr/testify/assert"
+)
+
+func Test_filestorage_001(t *testing.T) {
+	assert := assert.New(t)
+	path := t.TempDir()
+	storage, err := filestorage.New(path)
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+
+	// No session
+	session, err := storage.ReadSession()
+	assert.NoError(err)
+	assert.Nil(session)
+
+	// Write and read a session
+	assert.NoError(storage.WriteSession(&schema.Session{Token: &schema.Token{AccessToken: "token"}}))
+	session, err = storage.ReadSession()
+	assert.NoError(err)
+	assert.Equal("token", session.Token.AccessToken)
+
+	// Files are only readable by the user, and there are no temporary files
+	info, err := os.Stat(filepath.Join(path, "session.json"))
+	assert.NoError(err)
+	assert.Equal(os.FileMode(0600), info.Mode().Perm())
+	entries, err := os.ReadDir(path)
+	assert.NoError(err)
+	assert.Len(entries, 1)
+
+	// Missing ciphers
+	_, err = storage.ReadCiphers()
+	assert.Error(err)
+}
+
+func Test_filestorage_002(t *testing.T) {
+	assert := assert.New(t)
+	path := t.TempDir()
+	storage, err := filestorage.NewEncrypted(path, filestorage.PasswordKey("password"))
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+
+	// Write session and folders
+	assert.NoError(storage.WriteSession(&schema.Session{Token: &schema.Token{AccessToken: "token"}}))
+	assert.NoError(storage.WriteFolders(schema.Folders{{Id: "folder", Name: "name"}}))
+
+	// The access token is not stored in plaintext
+	data, err := os.ReadFile(filepath.Join(path, "session.json"))
+	assert.NoError(err)
+	assert.NotContains(string(data), "token")
+
+	// Read with the same password
+	storage, err = filestorage.NewEncrypted(path, filestorage.PasswordKey("password"))
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+	session, err := storage.ReadSession()
+	assert.NoError(err)
+	assert.Equal("token", session.Token.AccessToken)
+	folders, err := storage.ReadFolders()
+	assert.NoError(err)
+	assert.Equal("name", folders.Next().Name)
+
+	// Read with the wrong password
+	storage, err = filestorage.NewEncrypted(path, filestorage.PasswordKey("wrong"))
+	assert.NoError(err)
+	_, err = storage.ReadSession()
+	assert.Error(err)
+
+	// Read without encryption
+	plain, err := filestorage.New(path)
+	assert.NoError(err)
+	_, err = plain.ReadSession()
+	assert.Error(err)
+}
+
+func Test_filestorage_003(t *testing.T) {
+	assert := assert.New(t)
+
+	// Invalid keys
+	_, err := filestorage.NewEncrypted(t.TempDir(), nil)
+	assert.Error(err)
+	_, err = filestorage.NewEncrypted(t.TempDir(), filestorage.StaticKey([]byte("short")))
+	assert.Error(err)
+
+	// Static key
+	key := make([]byte, 64)
+	_, err = filestorage.NewEncrypted(t.TempDir(), filestorage.StaticKey(key))
+	assert.NoError(err)
+}
+
+func Test_filestorage_004(t *testing.T) {
+	assert := assert.New(t)
+	storage, err := filestorage.New(t.TempDir())
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+
+	// Not synced
+	lastSync, err := storage.LastSync()
+	assert.NoError(err)
+	assert.True(lastSync.IsZero())
+
+	// Write and read the last sync
+	now := time.UnixMilli(time.Now().UnixMilli())
+	assert.NoError(storage.WriteLastSync(now))
+	lastSync, err = storage.LastSync()
+	assert.NoError(err)
+	assert.True(now.Equal(lastSync))
+}
+
+func Test_filestorage_005(t *testing.T) {
+	assert := assert.New(t)
+	path := t.TempDir()
+	storage, err := filestorage.NewEncrypted(path, filestorage.PasswordKey("password"))
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+	assert.NoError(storage.WriteSession(&schema.Session{Token: &schema.Token{AccessToken: "token"}}))
+
+	// A sealed file without a MAC is rejected
+	fileName := filepath.Join(path, "session.json")
+	data, err := os.ReadFile(fileName)
+	assert.NoError(err)
+	for _, typ := range []uint{0, 2} {
+		encrypted, err := crypto.NewEncrypted(string(data))
+		if !assert.NoError(err) {
+			t.FailNow()
+		}
+		encrypted.Type, encrypted.Mac = typ, ""
+		assert.NoError(os.WriteFile(fileName, []byte(encrypted.String()), 0600))
+		_, err = storage.ReadSession()
+		assert.Error(err)
+	}
+}
+
+func Test_filestorage_006(t *testing.T) {
+	assert := assert.New(t)
+	path := t.TempDir()
+
+	// Write a session without encryption
+	plain, err := filestorage.New(path)
+	assert.NoError(err)
+	assert.NoError(plain.WriteSession(&schema.Session{Token: &schema.Token{AccessToken: "token"}}))
+	assert.NoError(plain.WriteLastSync(time.Now()))
+
+	// The unencrypted files are treated as missing by encrypted storage
+	storage, err := filestorage.NewEncrypted(path, filestorage.PasswordKey("password"))
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+	session, err := storage.ReadSession()
+	assert.NoError(err)
+	assert.Nil(session)
+	lastSync, err := storage.LastSync()
+	assert.NoError(err)
+	assert.True(lastSync.IsZero())
+
+	// and are replaced when written
+	assert.NoError(storage.WriteSession(&schema.Session{Token: &schema.Token{AccessToken: "token"}}))
+	session, err = storage.ReadSession()
+	assert.NoError(err)
+	assert.Equal("token", session.Token.AccessToken)
+}
@@ -0,0 +1,75 @@
+package filestorage
+
+import (
+	// Packages
+	crypto "github.com/mutablelogic/go-client/pkg/bitwarden/crypto"
+	argon2 "golang.org/x/crypto/argon2"
+
+	// Namespace imports
+	. "github.com/djthorpe/go-errors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// KeyProvider returns the key used to encrypt files at rest. The salt is
+// random and unique to the cache path, and can be used to derive the key.
+type KeyProvider interface {
+	Key(salt []byte) (*crypto.CryptoKey, error)
+}
+
+// KeyFunc adapts a function to a KeyProvider, for example to read a key
+// from the operating system keyring
+type KeyFunc func(salt []byte) (*crypto.CryptoKey, error)
+
+type passwordKey string
+
+type staticKey []byte
+
+////////////////////////////////////////////////////////////////////////////////
+// GLOBALS
+
+// Argon2id parameters for deriving a key from a password
+const (
+	argon2Iterations  = 3
+	argon2Memory      = 64 * 1024 // KiB
+	argon2Parallelism = 4
+	keySize           = 64
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// LIFECYCLE
+
+// PasswordKey returns a key provider which derives the key from a password,
+// such as the master password, using Argon2id and the salt
+func PasswordKey(password string) KeyProvider {
+	return passwordKey(password)
+}
+
+// StaticKey returns a key provider for a 64-byte key, which is split into
+// the encryption and MAC keys. The salt is not used.
+func StaticKey(key []byte) KeyProvider {
+	return staticKey(key)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+func (fn KeyFunc) Key(salt []byte) (*crypto.CryptoKey, error) {
+	return fn(salt)
+}
+
+func (p passwordKey) Key(salt []byte) (*crypto.CryptoKey, error) {
+	if p == "" {
+		return nil, ErrBadParameter.With("missing password")
+	}
+	key := argon2.IDKey([]byte(p), salt, argon2Iterations, argon2Memory, argon2Parallelism, keySize)
+	return crypto.NewKey(key[:32], key[32:]), nil
+}
+
+func (k staticKey) Key(salt []byte) (*crypto.CryptoKey, error) {
+	if len(k) != keySize {
+		return nil, ErrBadParameter.Withf("invalid key size: %d", len(k))
+	}
+	return crypto.NewKey(k[:32], k[32:]), nil
+}
@@ -0,0 +1,125 @@
+package bitwarden
+
+import (
+	"net/http"
+
+	// Packages
+	client "github.com/mutablelogic/go-client"
+	schema "github.com/mutablelogic/go-client/pkg/bitwarden/schema"
+	types "github.com/mutablelogic/go-server/pkg/types"
+
+	// Namespace imports
+	. "github.com/djthorpe/go-errors"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+type reqFolder struct {
+	Name string `json:"name"`
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// CreateFolder encrypts a folder name and creates the folder in the vault,
+// returning the decrypted folder. Requires the OptPassword option to encrypt
+// the folder name.
+func (c *Client) CreateFolder(name string, opts ...RequestOpt) (*schema.Folder, error) {
+	if name == "" {
+		return nil, ErrBadParameter.With("CreateFolder")
+	}
+	return c.writeFolder(http.MethodPost, &schema.Folder{Name: name}, opts...)
+}
+
+// UpdateFolder encrypts a folder name and updates the folder in the vault,
+// returning the decrypted folder. Requires the OptPassword option to encrypt
+// the folder name.
+func (c *Client) UpdateFolder(folder *schema.Folder, opts ...RequestOpt) (*schema.Folder, error) {
+	if folder == nil || folder.Id == "" || folder.Name == "" {
+		return nil, ErrBadParameter.With("UpdateFolder")
+	}
+	return c.writeFolder(http.MethodPut, folder, opts...)
+}
+
+// DeleteFolder permanently deletes a folder. Any ciphers in the folder are
+// moved out of the folder.
+func (c *Client) DeleteFolder(id string, opts ...RequestOpt) error {
+	if id == "" {
+		return ErrBadParameter.With("DeleteFolder")
+	} else if _, err := c.requestOpts(opts...); err != nil {
+		return err
+	}
+
+	// Delete and update the cache
+	if err := c.client.Do(client.NewRequestEx(http.MethodDelete, types.ContentTypeAny), nil, client.OptPath("folders", id), c.token()); err != nil {
+		return err
+	}
+	return c.cacheFolder(id, nil)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// Encrypt and write a folder, then update the cache and return the decrypted
+// response
+func (c *Client) writeFolder(method string, folder *schema.Folder, opts ...RequestOpt) (*schema.Folder, error) {
+	var response schema.Folder
+
+	// Apply options
+	reqOpt, err := c.requestOpts(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Encrypt the folder
+	key, err := c.cryptKey(reqOpt, "")
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := folder.Encrypt(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// Request -> Response
+	path := []any{"folders"}
+	if method == http.MethodPut {
+		path = append(path, folder.Id)
+	}
+	if payload, err := client.NewJSONRequestEx(method, reqFolder{encrypted.(*schema.Folder).Name}, types.ContentTypeJSON); err != nil {
+		return nil, err
+	} else if err := c.client.Do(payload, &response, client.OptPath(path...), c.token()); err != nil {
+		return nil, err
+	}
+
+	// Update the cache
+	if err := c.cacheFolder(response.Id, &response); err != nil {
+		return nil, err
+	}
+
+	// Return the decrypted folder
+	if decrypted, err := response.Decrypt(key); err != nil {
+		return nil, err
+	} else {
+		return decrypted.(*schema.Folder), nil
+	}
+}
+
+// Replace or remove (when the folder is nil) a folder in storage
+func (c *Client) cacheFolder(id string, folder *schema.Folder) error {
+	if c.storage == nil {
+		return nil
+	} else if profile, err := c.storage.ReadProfile(); err != nil || profile == nil {
+		// If the vault has not been synced, then the next sync will populate
+		// the cache
+		return err
+	}
+	iterator, err := c.storage.ReadFolders()
+	if err != nil {
+		return err
+	}
+	return c.storage.WriteFolders(replaceValue(iterator, func(v *schema.Folder) string {
+		return v.Id
+	}, id, folder))
+}
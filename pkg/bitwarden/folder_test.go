@@ -0,0 +1,44 @@
+package bitwarden_test
+
+import (
+	"os"
+	"testing"
+
+	// Packages
+	opts "github.com/mutablelogic/go-client"
+	bitwarden "github.com/mutablelogic/go-client/pkg/bitwarden"
+	schema "github.com/mutablelogic/go-client/pkg/bitwarden/schema"
+	assert "github.com/stretchr/testify/assert"
+)
+
+func Test_folder_001(t *testing.T) {
+	assert := assert.New(t)
+	client, err := bitwarden.New(opts.OptTrace(os.Stderr, true), bitwarden.OptFileStorage(t.TempDir()), bitwarden.OptCredentials(GetCredentials(t)), bitwarden.OptDevice(schema.Device{
+		Name:       "mydevice",
+		Identifier: GetIdentifier(t),
+	}))
+	assert.NoError(err)
+	password := GetPassword(t)
+
+	// Login
+	if err := client.Login(); !assert.NoError(err) {
+		t.SkipNow()
+	}
+
+	// Create a folder
+	folder, err := client.CreateFolder(t.Name(), bitwarden.OptPassword(password))
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+	assert.Equal(t.Name(), folder.Name)
+
+	// Rename the folder
+	folder.Name = t.Name() + "_renamed"
+	folder, err = client.UpdateFolder(folder, bitwarden.OptPassword(password))
+	if assert.NoError(err) {
+		assert.Equal(t.Name()+"_renamed", folder.Name)
+	}
+
+	// Delete the folder
+	assert.NoError(client.DeleteFolder(folder.Id))
+}
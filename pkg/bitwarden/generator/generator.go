@@ -0,0 +1,315 @@
+/*
+generator creates random passwords and passphrases with the same options as
+the Bitwarden clients, and estimates the strength of a password.
+
+References:
+
+	https://bitwarden.com/help/generator/
+	https://www.eff.org/deeplinks/2016/07/new-wordlists-random-passphrases
+*/
+package generator
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+	"unicode"
+
+	// Namespace imports
+	. "github.com/djthorpe/go-errors"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// Charset is a set of character classes for a password
+type Charset uint
+
+type opts struct {
+	length         uint
+	charset        Charset
+	minNumbers     uint
+	minSpecial     uint
+	avoidAmbiguous bool
+	separator      string
+	capitalize     bool
+	includeNumber  bool
+}
+
+// Opt is a password or passphrase option
+type Opt func(*opts) error
+
+///////////////////////////////////////////////////////////////////////////////
+// GLOBALS
+
+const (
+	CharsetUpper Charset = 1 << iota
+	CharsetLower
+	CharsetNumber
+	CharsetSpecial
+	CharsetNone Charset = 0
+)
+
+const (
+	charsUpper   = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	charsLower   = "abcdefghijklmnopqrstuvwxyz"
+	charsNumber  = "0123456789"
+	charsSpecial = "!@#$%^&*"
+
+	// Characters which can be mistaken for each other
+	charsAmbiguous = "IOl01"
+)
+
+const (
+	defaultLength    = 14
+	defaultWords     = 6
+	defaultSeparator = "-"
+	defaultCharset   = CharsetUpper | CharsetLower | CharsetNumber
+	minLength        = 5
+	maxLength        = 128
+	minWords         = 3
+	maxWords         = 20
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Password returns a random password. By default the password is 14
+// characters with uppercase, lowercase and number characters, and there is
+// at least one character from each character class.
+func Password(opt ...Opt) (string, error) {
+	o, err := apply(defaultLength, opt...)
+	if err != nil {
+		return "", err
+	}
+
+	// Check parameters
+	if o.length < minLength || o.length > maxLength {
+		return "", ErrBadParameter.Withf("password length must be between %d and %d", minLength, maxLength)
+	} else if o.charset == CharsetNone {
+		return "", ErrBadParameter.With("no character classes")
+	}
+
+	// Determine the character class of each position, with at least one
+	// character from each class, and the minimum numbers and special
+	// characters
+	var positions []Charset
+	for _, class := range []Charset{CharsetUpper, CharsetLower, CharsetNumber, CharsetSpecial} {
+		if o.charset&class == 0 {
+			continue
+		}
+		count := uint(1)
+		if class == CharsetNumber {
+			count = max(count, o.minNumbers)
+		} else if class == CharsetSpecial {
+			count = max(count, o.minSpecial)
+		}
+		for range count {
+			positions = append(positions, class)
+		}
+	}
+	if uint(len(positions)) > o.length {
+		return "", ErrBadParameter.Withf("password length %d is less than the minimum number of characters %d", o.length, len(positions))
+	}
+	for uint(len(positions)) < o.length {
+		positions = append(positions, o.charset)
+	}
+	if err := shuffle(positions); err != nil {
+		return "", err
+	}
+
+	// Choose a character for each position
+	var result strings.Builder
+	for _, class := range positions {
+		chars := o.chars(class)
+		if n, err := randomInt(len(chars)); err != nil {
+			return "", err
+		} else {
+			result.WriteByte(chars[n])
+		}
+	}
+
+	// Return the password
+	return result.String(), nil
+}
+
+// Passphrase returns a random passphrase of words from the EFF large
+// wordlist. By default the passphrase is six words separated by a hyphen.
+func Passphrase(opt ...Opt) (string, error) {
+	o, err := apply(defaultWords, opt...)
+	if err != nil {
+		return "", err
+	}
+
+	// Check parameters
+	if o.length < minWords || o.length > maxWords {
+		return "", ErrBadParameter.Withf("number of words must be between %d and %d", minWords, maxWords)
+	}
+
+	// Choose the words
+	words := wordlist()
+	result := make([]string, o.length)
+	for i := range result {
+		if n, err := randomInt(len(words)); err != nil {
+			return "", err
+		} else {
+			result[i] = words[n]
+		}
+		if o.capitalize {
+			runes := []rune(result[i])
+			runes[0] = unicode.ToUpper(runes[0])
+			result[i] = string(runes)
+		}
+	}
+
+	// Append a number to one of the words
+	if o.includeNumber {
+		i, err := randomInt(len(result))
+		if err != nil {
+			return "", err
+		}
+		n, err := randomInt(len(charsNumber))
+		if err != nil {
+			return "", err
+		}
+		result[i] += charsNumber[n : n+1]
+	}
+
+	// Return the passphrase
+	return strings.Join(result, o.separator), nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// OPTIONS
+
+// Set the password length, or the number of words in a passphrase
+func OptLength(n uint) Opt {
+	return func(o *opts) error {
+		o.length = n
+		return nil
+	}
+}
+
+// Set the character classes for a password
+func OptCharset(v Charset) Opt {
+	return func(o *opts) error {
+		if v&^(CharsetUpper|CharsetLower|CharsetNumber|CharsetSpecial) != 0 {
+			return ErrBadParameter.With("OptCharset")
+		}
+		o.charset = v
+		return nil
+	}
+}
+
+// Set the minimum number of number characters in a password
+func OptMinNumbers(n uint) Opt {
+	return func(o *opts) error {
+		o.minNumbers = n
+		return nil
+	}
+}
+
+// Set the minimum number of special characters in a password
+func OptMinSpecial(n uint) Opt {
+	return func(o *opts) error {
+		o.minSpecial = n
+		return nil
+	}
+}
+
+// Avoid characters which can be mistaken for each other in a password
+func OptAvoidAmbiguous() Opt {
+	return func(o *opts) error {
+		o.avoidAmbiguous = true
+		return nil
+	}
+}
+
+// Set the separator between words in a passphrase
+func OptSeparator(v string) Opt {
+	return func(o *opts) error {
+		o.separator = v
+		return nil
+	}
+}
+
+// Capitalize the first letter of each word in a passphrase
+func OptCapitalize() Opt {
+	return func(o *opts) error {
+		o.capitalize = true
+		return nil
+	}
+}
+
+// Append a number to one of the words in a passphrase
+func OptIncludeNumber() Opt {
+	return func(o *opts) error {
+		o.includeNumber = true
+		return nil
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+func apply(length uint, opt ...Opt) (*opts, error) {
+	o := &opts{
+		length:    length,
+		charset:   defaultCharset,
+		separator: defaultSeparator,
+	}
+	for _, fn := range opt {
+		if err := fn(o); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// Return the characters for a set of character classes
+func (o *opts) chars(charset Charset) string {
+	var result strings.Builder
+	for _, v := range []struct {
+		class Charset
+		chars string
+	}{
+		{CharsetUpper, charsUpper},
+		{CharsetLower, charsLower},
+		{CharsetNumber, charsNumber},
+		{CharsetSpecial, charsSpecial},
+	} {
+		if charset&v.class != 0 {
+			result.WriteString(v.chars)
+		}
+	}
+	if !o.avoidAmbiguous {
+		return result.String()
+	}
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(charsAmbiguous, r) {
+			return -1
+		}
+		return r
+	}, result.String())
+}
+
+// Return a uniform random integer in [0,n)
+func randomInt(n int) (int, error) {
+	if v, err := rand.Int(rand.Reader, big.NewInt(int64(n))); err != nil {
+		return 0, err
+	} else {
+		return int(v.Int64()), nil
+	}
+}
+
+// Shuffle values in place
+func shuffle[T any](values []T) error {
+	for i := len(values) - 1; i > 0; i-- {
+		j, err := randomInt(i + 1)
+		if err != nil {
+			return err
+		}
+		values[i], values[j] = values[j], values[i]
+	}
+	return nil
+}
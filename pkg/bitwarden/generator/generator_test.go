@@ -0,0 +1,122 @@
+package generator_test
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+
+	// Packages
+	generator "github.com/mutablelogic/go-client/pkg/bitwarden/generator"
+	assert "github.com/stretchr/testify/assert"
+)
+
+func Test_generator_001(t *testing.T) {
+	assert := assert.New(t)
+
+	// Default password
+	password, err := generator.Password()
+	assert.NoError(err)
+	assert.Len(password, 14)
+	assert.True(strings.ContainsFunc(password, unicode.IsUpper))
+	assert.True(strings.ContainsFunc(password, unicode.IsLower))
+	assert.True(strings.ContainsFunc(password, unicode.IsDigit))
+	assert.False(strings.ContainsAny(password, "!@#$%^&*"))
+
+	// Invalid lengths
+	_, err = generator.Password(generator.OptLength(4))
+	assert.Error(err)
+	_, err = generator.Password(generator.OptLength(129))
+	assert.Error(err)
+
+	// No character classes
+	_, err = generator.Password(generator.OptCharset(generator.CharsetNone))
+	assert.Error(err)
+}
+
+func Test_generator_002(t *testing.T) {
+	assert := assert.New(t)
+
+	// Minimum numbers and special characters
+	for i := 0; i < 100; i++ {
+		password, err := generator.Password(
+			generator.OptLength(8),
+			generator.OptCharset(generator.CharsetLower|generator.CharsetNumber|generator.CharsetSpecial),
+			generator.OptMinNumbers(3),
+			generator.OptMinSpecial(2),
+			generator.OptAvoidAmbiguous(),
+		)
+		if !assert.NoError(err) {
+			t.FailNow()
+		}
+		assert.Len(password, 8)
+		assert.GreaterOrEqual(count(password, "0123456789"), 3)
+		assert.GreaterOrEqual(count(password, "!@#$%^&*"), 2)
+		assert.False(strings.ContainsAny(password, "IOl01"))
+		assert.False(strings.ContainsFunc(password, unicode.IsUpper))
+	}
+
+	// Minimums which do not fit in the password
+	_, err := generator.Password(generator.OptLength(5), generator.OptMinNumbers(3), generator.OptMinSpecial(3), generator.OptCharset(generator.CharsetNumber|generator.CharsetSpecial))
+	assert.Error(err)
+}
+
+func Test_generator_003(t *testing.T) {
+	assert := assert.New(t)
+
+	// Default passphrase
+	passphrase, err := generator.Passphrase()
+	assert.NoError(err)
+	assert.Len(strings.Split(passphrase, "-"), 6)
+
+	// Capitalized words with a number
+	passphrase, err = generator.Passphrase(generator.OptLength(4), generator.OptSeparator(" "), generator.OptCapitalize(), generator.OptIncludeNumber())
+	assert.NoError(err)
+	words := strings.Split(passphrase, " ")
+	assert.Len(words, 4)
+	for _, word := range words {
+		assert.True(unicode.IsUpper([]rune(word)[0]))
+	}
+	assert.Equal(1, count(passphrase, "0123456789"))
+
+	// Invalid number of words
+	_, err = generator.Passphrase(generator.OptLength(2))
+	assert.Error(err)
+	_, err = generator.Passphrase(generator.OptLength(21))
+	assert.Error(err)
+}
+
+func Test_strength_001(t *testing.T) {
+	assert := assert.New(t)
+
+	// Weak passwords
+	assert.Equal(uint(0), generator.Estimate("").Score)
+	assert.Equal(uint(0), generator.Estimate("password").Score)
+	assert.Equal(uint(0), generator.Estimate("aaaaaaaaaaaa").Score)
+	assert.Equal(uint(0), generator.Estimate("abcdefghijkl").Score)
+	assert.Equal("very weak", generator.Estimate("Password1").String())
+
+	// Dictionary words are weaker than random characters of the same length
+	assert.Less(generator.Estimate("correct-horse").Entropy, generator.Estimate("xqzvbtmwpkjrd").Entropy)
+
+	// Generated passwords and passphrases are strong
+	password, err := generator.Password()
+	assert.NoError(err)
+	assert.Equal(uint(4), generator.Estimate(password).Score)
+	passphrase, err := generator.Passphrase()
+	assert.NoError(err)
+	assert.Equal(uint(4), generator.Estimate(passphrase).Score)
+	assert.Equal("very strong", generator.Estimate(passphrase).String())
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+func count(value, chars string) int {
+	n := 0
+	for _, r := range value {
+		if strings.ContainsRune(chars, r) {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,169 @@
+package generator
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// Strength is an estimate of the strength of a password
+type Strength struct {
+	Entropy float64 `json:"entropy"` // Estimated bits of entropy
+	Score   uint    `json:"score"`   // Score from 0 (very weak) to 4 (very strong)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// GLOBALS
+
+// Commonly used passwords and words, which are guessed first
+var commonWords = []string{
+	"password", "passw0rd", "qwerty", "qwertyuiop", "asdfgh", "zxcvbn", "letmein", "welcome",
+	"admin", "administrator", "login", "iloveyou", "monkey", "dragon", "football", "baseball",
+	"sunshine", "princess", "master", "shadow", "superman", "trustno1", "secret", "hello",
+	"freedom", "whatever", "starwars", "computer", "michael", "charlie", "batman", "changeme",
+}
+
+// Common words as a set
+var commonSet = sync.OnceValue(func() map[string]bool {
+	result := make(map[string]bool, len(commonWords))
+	for _, word := range commonWords {
+		result[word] = true
+	}
+	return result
+})
+
+// Minimum entropy for each score above zero
+var scoreEntropy = []float64{25, 40, 55, 70}
+
+const (
+	// Minimum length of a dictionary word to match in a password
+	minWordLength = 4
+
+	// Size of the pool for characters which are not letters, numbers or
+	// ASCII symbols
+	otherPoolSize = 100
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Estimate returns the estimated strength of a password. The estimate
+// assumes an attacker guesses dictionary words, common passwords, repeated
+// characters and sequences before random characters.
+func Estimate(password string) Strength {
+	runes := []rune(password)
+	lower := []rune(strings.ToLower(password))
+	charBits := math.Log2(float64(poolSize(runes)))
+	wordBits := math.Log2(float64(len(wordlist())))
+	commonBits := math.Log2(float64(len(commonWords)))
+
+	// Sum the bits of entropy for each token in the password
+	var entropy float64
+	for i := 0; i < len(runes); {
+		if n := matchWord(lower[i:], commonSet()); n > 0 {
+			entropy += commonBits
+			i += n
+		} else if n := matchWord(lower[i:], wordset()); n > 0 {
+			entropy += wordBits
+			i += n
+		} else if i > 0 && runes[i] == runes[i-1] {
+			// Repeated character
+			entropy += 1
+			i++
+		} else if i > 1 && runes[i]-runes[i-1] == runes[i-1]-runes[i-2] && abs(runes[i]-runes[i-1]) == 1 {
+			// Sequence such as "abc" or "321"
+			entropy += 1
+			i++
+		} else {
+			entropy += charBits
+			i++
+		}
+	}
+
+	// Return the strength
+	result := Strength{Entropy: math.Round(entropy*10) / 10}
+	for _, threshold := range scoreEntropy {
+		if entropy >= threshold {
+			result.Score++
+		}
+	}
+	return result
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// STRINGIFY
+
+func (s Strength) String() string {
+	switch s.Score {
+	case 0:
+		return "very weak"
+	case 1:
+		return "weak"
+	case 2:
+		return "reasonable"
+	case 3:
+		return "strong"
+	default:
+		return "very strong"
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// Return the number of characters an attacker would need to try for each
+// character, based on the character classes in the password
+func poolSize(runes []rune) int {
+	var upper, lower, number, symbol, other bool
+	for _, r := range runes {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= '0' && r <= '9':
+			number = true
+		case r < unicode.MaxASCII && unicode.IsPrint(r):
+			symbol = true
+		default:
+			other = true
+		}
+	}
+	size := 0
+	for _, v := range []struct {
+		used bool
+		size int
+	}{
+		{upper, len(charsUpper)},
+		{lower, len(charsLower)},
+		{number, len(charsNumber)},
+		{symbol, 33},
+		{other, otherPoolSize},
+	} {
+		if v.used {
+			size += v.size
+		}
+	}
+	return max(size, 1)
+}
+
+// Return the length of the longest word at the start of the runes, or zero
+func matchWord(runes []rune, words map[string]bool) int {
+	for n := len(runes); n >= minWordLength; n-- {
+		if words[string(runes[:n])] {
+			return n
+		}
+	}
+	return 0
+}
+
+func abs(v rune) rune {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
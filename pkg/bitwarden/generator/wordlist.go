@@ -0,0 +1,38 @@
+package generator
+
+import (
+	_ "embed"
+	"strings"
+	"sync"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// GLOBALS
+
+// The EFF large wordlist, with a dice roll and word on each line. It is
+// licensed under CC BY 3.0 US by the Electronic Frontier Foundation.
+//
+//go:embed eff_large_wordlist.txt
+var effLargeWordlist string
+
+// Words from the wordlist, in order
+var wordlist = sync.OnceValue(func() []string {
+	lines := strings.Split(strings.TrimSpace(effLargeWordlist), "\n")
+	words := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if _, word, ok := strings.Cut(line, "\t"); ok {
+			words = append(words, strings.TrimSpace(word))
+		}
+	}
+	return words
+})
+
+// Words from the wordlist, for looking up words in a password
+var wordset = sync.OnceValue(func() map[string]bool {
+	words := wordlist()
+	result := make(map[string]bool, len(words))
+	for _, word := range words {
+		result[word] = true
+	}
+	return result
+})
@@ -0,0 +1,246 @@
+package bitwarden
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	// Packages
+	client "github.com/mutablelogic/go-client"
+	crypto "github.com/mutablelogic/go-client/pkg/bitwarden/crypto"
+	schema "github.com/mutablelogic/go-client/pkg/bitwarden/schema"
+	types "github.com/mutablelogic/go-server/pkg/types"
+
+	// Namespace imports
+	. "github.com/djthorpe/go-errors"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// TwoFactorFunc is called when a password login requires two-factor
+// authentication, and returns the code for the provider. The hint is the
+// obfuscated email address the code was sent to, for the email provider.
+type TwoFactorFunc func(provider schema.TwoFactorProvider, hint string) (string, error)
+
+type reqPrelogin struct {
+	Email string `json:"email"`
+}
+
+type reqSendEmail struct {
+	Email              string `json:"email"`
+	MasterPasswordHash string `json:"masterPasswordHash"`
+	DeviceIdentifier   string `json:"deviceIdentifier,omitempty"`
+}
+
+// errorBody is a transport middleware which captures the body of an
+// error response, so that a two-factor challenge can be decoded
+type errorBody struct {
+	http.RoundTripper
+	status int
+	body   []byte
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// GLOBALS
+
+// Two-factor providers which can be used with a callback, in order of
+// preference
+var twoFactorProviders = []schema.TwoFactorProvider{
+	schema.TwoFactorProviderAuthenticator,
+	schema.TwoFactorProviderEmail,
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// Request a token using the API key credentials
+func (c *Client) credentialsToken() (*respToken, error) {
+	response, twofactor, err := c.requestToken(reqToken{
+		Login:  c.login,
+		Device: c.session.Device,
+	})
+	if err != nil {
+		return nil, err
+	} else if twofactor != nil {
+		return nil, ErrNotAuthorized.With("two-factor authentication required")
+	}
+	return response, nil
+}
+
+// Renew a token using the refresh token
+func (c *Client) refreshToken(token string) (*respToken, error) {
+	response, _, err := c.requestToken(reqToken{
+		Login: Login{
+			GrantType: defaultRefreshGrantType,
+			ClientId:  defaultPasswordClientId,
+		},
+		RefreshToken: token,
+	})
+	return response, err
+}
+
+// Request a token using the email and master password, prompting for a
+// two-factor authentication code when required
+func (c *Client) passwordToken(opts opt) (*respToken, error) {
+	var kdf schema.Kdf
+	if opts.passwd == "" {
+		return nil, ErrBadParameter.With("missing password")
+	}
+
+	// Get the key derivation parameters for the account
+	if payload, err := client.NewJSONRequest(reqPrelogin{c.email}); err != nil {
+		return nil, err
+	} else if err := c.client.Do(payload, &kdf, client.OptReqEndpoint(identityUrl), client.OptPath("accounts", "prelogin")); err != nil {
+		return nil, err
+	}
+
+	// Hash the master password
+	hash, err := crypto.HashedPassword(strings.ToLower(c.email), opts.passwd, kdf.Type, kdf.Iterations, kdf.Memory, kdf.Parallelism)
+	if err != nil {
+		return nil, err
+	}
+
+	// Request a token, skipping two-factor authentication if it was
+	// remembered on a previous login
+	request := reqToken{
+		Login: Login{
+			GrantType: defaultPasswordGrantType,
+			Scope:     defaultPasswordScope,
+			ClientId:  defaultPasswordClientId,
+		},
+		Device:   c.session.Device,
+		Username: c.email,
+		Password: hash,
+	}
+	if c.session.TwoFactorToken != "" {
+		request.TwoFactorToken = c.session.TwoFactorToken
+		request.TwoFactorProvider = twoFactorProvider(schema.TwoFactorProviderRemember)
+	}
+	response, twofactor, err := c.requestToken(request)
+	if err != nil {
+		return nil, err
+	} else if twofactor == nil {
+		return response, nil
+	}
+
+	// Two-factor authentication is required
+	c.session.TwoFactorToken = ""
+	if opts.twofactor == nil {
+		return nil, ErrNotAuthorized.With("two-factor authentication required")
+	}
+	provider, err := selectTwoFactorProvider(twofactor)
+	if err != nil {
+		return nil, err
+	}
+
+	// The server only sends the email automatically when it is the only
+	// provider
+	if provider == schema.TwoFactorProviderEmail && len(twofactor.Enabled()) > 1 {
+		if err := c.sendTwoFactorEmail(hash); err != nil {
+			return nil, err
+		}
+	}
+
+	// Get the code and request the token again
+	code, err := opts.twofactor(provider, twofactor.Param(provider, "Email"))
+	if err != nil {
+		return nil, err
+	} else if code = strings.TrimSpace(code); code == "" {
+		return nil, ErrBadParameter.With("missing two-factor authentication code")
+	}
+	request.TwoFactorToken = code
+	request.TwoFactorProvider = twoFactorProvider(provider)
+	request.TwoFactorRemember = 1
+	if response, twofactor, err := c.requestToken(request); err != nil {
+		return nil, err
+	} else if twofactor != nil {
+		return nil, ErrNotAuthorized.With("two-factor authentication failed")
+	} else {
+		return response, nil
+	}
+}
+
+// Request a token, returning the two-factor challenge if the server
+// responds that two-factor authentication is required
+func (c *Client) requestToken(request reqToken) (*respToken, *schema.TwoFactor, error) {
+	var response respToken
+	capture := new(errorBody)
+
+	// Request -> Response
+	payload, err := client.NewFormRequest(request, types.ContentTypeJSON)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := c.client.Do(payload, &response, client.OptReqEndpoint(identityUrl), client.OptPath("connect", "token"), client.OptReqTransport(capture.wrap)); err != nil {
+		var twofactor schema.TwoFactor
+		if capture.status == http.StatusBadRequest && json.Unmarshal(capture.body, &twofactor) == nil && len(twofactor.Providers) > 0 {
+			return nil, &twofactor, nil
+		}
+		return nil, nil, err
+	}
+
+	// Return success
+	return &response, nil, nil
+}
+
+// Send the two-factor authentication code by email
+func (c *Client) sendTwoFactorEmail(hash string) error {
+	request := reqSendEmail{
+		Email:              c.email,
+		MasterPasswordHash: hash,
+	}
+	if c.session.Device != nil {
+		request.DeviceIdentifier = c.session.Device.Identifier
+	}
+	if payload, err := client.NewJSONRequestEx(http.MethodPost, request, types.ContentTypeAny); err != nil {
+		return err
+	} else {
+		return c.client.Do(payload, nil, client.OptPath("two-factor", "send-email-login"))
+	}
+}
+
+// Return the preferred provider which can be used with a callback
+func selectTwoFactorProvider(twofactor *schema.TwoFactor) (schema.TwoFactorProvider, error) {
+	enabled := twofactor.Enabled()
+	for _, provider := range twoFactorProviders {
+		for _, v := range enabled {
+			if v == provider {
+				return provider, nil
+			}
+		}
+	}
+	return 0, ErrNotImplemented.Withf("unsupported two-factor providers: %v", enabled)
+}
+
+func twoFactorProvider(provider schema.TwoFactorProvider) *int {
+	v := int(provider)
+	return &v
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// TRANSPORT
+
+func (e *errorBody) wrap(parent http.RoundTripper) http.RoundTripper {
+	e.RoundTripper = parent
+	return e
+}
+
+// RoundTrip captures the body of an error response, and replaces the body
+// so it can be read again
+func (e *errorBody) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := e.RoundTripper.RoundTrip(req)
+	if err != nil || resp.StatusCode < http.StatusBadRequest {
+		return resp, err
+	}
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	e.status, e.body = resp.StatusCode, data
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	return resp, nil
+}
@@ -14,8 +14,13 @@ import (
 // TYPES
 
 type opt struct {
-	force  bool
-	passwd string
+	force        bool
+	passwd       string
+	permanent    bool
+	twofactor    TwoFactorFunc
+	exportPasswd string
+	organization string
+	accessPasswd string
 }
 
 type RequestOpt func(*opt) error
@@ -39,6 +44,22 @@ func OptCredentials(clientId, secret string) client.ClientOpt {
 	}
 }
 
+// Login with an email address and master password rather than API key
+// credentials. The master password is set with OptPassword on Login.
+func OptEmail(email string) client.ClientOpt {
+	return func(c *client.Client) error {
+		if email == "" {
+			return ErrBadParameter.With("OptEmail")
+		}
+		if c, ok := c.Parent.(*Client); !ok {
+			return ErrBadParameter.With("OptEmail")
+		} else {
+			c.email = email
+		}
+		return nil
+	}
+}
+
 // Use a storage engine to read and write data
 func OptStorage(v Storage) client.ClientOpt {
 	return func(c *client.Client) error {
@@ -65,6 +86,21 @@ func OptFileStorage(cachePath string) client.ClientOpt {
 	}
 }
 
+// Use file storage engine which encrypts files at rest with a key returned
+// by the key provider, for example filestorage.PasswordKey
+func OptEncryptedFileStorage(cachePath string, provider filestorage.KeyProvider) client.ClientOpt {
+	return func(c *client.Client) error {
+		if v, ok := c.Parent.(*Client); !ok {
+			return ErrBadParameter.With("OptEncryptedFileStorage")
+		} else if storage, err := filestorage.NewEncrypted(cachePath, provider); err != nil {
+			return err
+		} else {
+			v.storage = storage
+		}
+		return nil
+	}
+}
+
 // Set the device, populating missing fields
 func OptDevice(device schema.Device) client.ClientOpt {
 	return func(c *client.Client) error {
@@ -82,7 +118,7 @@ func OptDevice(device schema.Device) client.ClientOpt {
 ///////////////////////////////////////////////////////////////////////////////
 // REQUEST OPTIONS
 
-// Force login by clearing the token
+// Force login by clearing the token, and force a full sync of the vault
 func OptForce() RequestOpt {
 	return func(o *opt) error {
 		o.force = true
@@ -97,3 +133,52 @@ func OptPassword(v string) RequestOpt {
 		return nil
 	}
 }
+
+// Permanently delete rather than move to the trash
+func OptPermanent() RequestOpt {
+	return func(o *opt) error {
+		o.permanent = true
+		return nil
+	}
+}
+
+// Set the callback which returns a two-factor authentication code on
+// password login
+func OptTwoFactor(fn TwoFactorFunc) RequestOpt {
+	return func(o *opt) error {
+		if fn == nil {
+			return ErrBadParameter.With("OptTwoFactor")
+		}
+		o.twofactor = fn
+		return nil
+	}
+}
+
+// Set the password which protects an encrypted export
+func OptExportPassword(v string) RequestOpt {
+	return func(o *opt) error {
+		o.exportPasswd = v
+		return nil
+	}
+}
+
+// Export or import the items in an organization rather than the personal
+// vault
+func OptOrganization(id string) RequestOpt {
+	return func(o *opt) error {
+		if id == "" {
+			return ErrBadParameter.With("OptOrganization")
+		}
+		o.organization = id
+		return nil
+	}
+}
+
+// Set the password which protects access to a Send, when creating or
+// receiving the Send
+func OptAccessPassword(v string) RequestOpt {
+	return func(o *opt) error {
+		o.accessPasswd = v
+		return nil
+	}
+}
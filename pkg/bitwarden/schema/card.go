@@ -0,0 +1,21 @@
+package schema
+
+///////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+type CipherCard struct {
+	CardholderName string `json:"CardholderName,omitempty"` // crypt
+	Brand          string `json:"Brand,omitempty"`          // crypt
+	Number         string `json:"Number,omitempty"`         // crypt
+	ExpMonth       string `json:"ExpMonth,omitempty"`       // crypt
+	ExpYear        string `json:"ExpYear,omitempty"`        // crypt
+	Code           string `json:"Code,omitempty"`           // crypt
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// Encrypt or decrypt the encrypted fields of the card in place
+func (c *CipherCard) crypt(fn func(string) (string, error)) error {
+	return cryptStrings(fn, &c.CardholderName, &c.Brand, &c.Number, &c.ExpMonth, &c.ExpYear, &c.Code)
+}
@@ -15,31 +15,35 @@ import (
 type Ciphers []*Cipher
 
 type Cipher struct {
-	Id             string       `json:"id,width:36"`
-	Name           string       `json:"name,width:30"`
-	Type           CipherType   `json:"type,width:5"`
-	FolderId       string       `json:"folderId,omitempty,width:36"`
-	OrganizationId string       `json:"organizationId,omitempty,width:36"`
-	Favorite       bool         `json:"favorite,omitempty,width:5"`
-	Edit           bool         `json:"edit,width:5"`
-	RevisionDate   time.Time    `json:"revisionDate,width:29"`
-	CollectionIds  []string     `json:"collectionIds,omitempty"`
-	ViewPassword   bool         `json:"viewPassword,width:5"`
-	Login          *CipherLogin `json:"Login,omitempty,wrap"`
-	//	Card           *CardData       `json:"Card,omitempty"`
-	//	SecureNote     *SecureNoteData `json:"SecureNote,omitempty"`
-	//	Identity       *IdentityData   `json:"Identity,omitempty"`
-	Attachments []string `json:"Attachments,omitempty"`
-	Object      string   `json:"object"`
+	Id              string                   `json:"id,width:36"`
+	Name            string                   `json:"name,width:30"`        // crypt
+	Notes           string                   `json:"notes,omitempty,wrap"` // crypt
+	Type            CipherType               `json:"type,width:5"`
+	FolderId        string                   `json:"folderId,omitempty,width:36"`
+	OrganizationId  string                   `json:"organizationId,omitempty,width:36"`
+	Favorite        bool                     `json:"favorite,omitempty,width:5"`
+	Edit            bool                     `json:"edit,width:5"`
+	RevisionDate    time.Time                `json:"revisionDate,width:29"`
+	DeletedDate     *time.Time               `json:"deletedDate,omitempty,width:29"`
+	CollectionIds   []string                 `json:"collectionIds,omitempty"`
+	ViewPassword    bool                     `json:"viewPassword,width:5"`
+	Reprompt        CipherReprompt           `json:"reprompt,omitempty,width:5"`
+	Key             string                   `json:"key,omitempty"` // Item key, encrypted with the user or organization key
+	Login           *CipherLogin             `json:"Login,omitempty,wrap"`
+	Card            *CipherCard              `json:"Card,omitempty,wrap"`
+	SecureNote      *CipherSecureNote        `json:"SecureNote,omitempty,wrap"`
+	Identity        *CipherIdentity          `json:"Identity,omitempty,wrap"`
+	SshKey          *CipherSshKey            `json:"SshKey,omitempty,wrap"`
+	Fields          []*CipherField           `json:"Fields,omitempty,wrap"`
+	PasswordHistory []*CipherPasswordHistory `json:"PasswordHistory,omitempty,wrap"`
+	Attachments     []*CipherAttachment      `json:"Attachments,omitempty"`
+	Object          string                   `json:"object"`
 }
 
 type CipherType uint
 
-type CipherLogin struct {
-	Username string `json:"Username,omitempty"` // crypt
-	Password string `json:"Password,omitempty"` // crypt
-	URI      string `json:"URI,omitempty"`      // crypt
-}
+// CipherReprompt determines if the master password is required to view the cipher
+type CipherReprompt uint
 
 ///////////////////////////////////////////////////////////////////////////////
 // CONSTANTS
@@ -50,6 +54,12 @@ const (
 	CipherTypeNote
 	CipherTypeCard
 	CipherTypeIdentity
+	CipherTypeSshKey
+)
+
+const (
+	CipherRepromptNone CipherReprompt = iota
+	CipherRepromptPassword
 )
 
 ///////////////////////////////////////////////////////////////////////////////
@@ -70,6 +80,8 @@ func (t CipherType) String() string {
 		return "Card"
 	case CipherTypeIdentity:
 		return "Identity"
+	case CipherTypeSshKey:
+		return "SshKey"
 	default:
 		return fmt.Sprint(uint(t))
 	}
@@ -90,30 +102,124 @@ func (c *Ciphers) Write(w io.Writer) error {
 	return json.NewEncoder(w).Encode(c)
 }
 
-// Decrypt a cipher
+// Encrypt a cipher and return a copy of it. If the cipher has an item key,
+// then the key is decrypted and used to encrypt the fields.
+func (c Cipher) Encrypt(k *crypto.CryptoKey) (Crypter, error) {
+	result := c.copy()
+	if key, err := c.itemKey(k); err != nil {
+		return nil, err
+	} else if err := result.crypt(key.EncryptStr); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Decrypt a cipher and return a copy of it. If the cipher has an item key,
+// then the key is decrypted and used to decrypt the fields.
 func (c Cipher) Decrypt(k *crypto.CryptoKey) (Crypter, error) {
-	result := &c
-	if value, err := k.DecryptStr(result.Name); err != nil {
+	result := c.copy()
+	if key, err := c.itemKey(k); err != nil {
+		return nil, err
+	} else if err := result.crypt(key.DecryptStr); err != nil {
 		return nil, err
-	} else {
-		result.Name = value
-	}
-	if result.Login != nil {
-		if value, err := k.DecryptStr(result.Login.Username); err != nil {
-			return nil, err
-		} else {
-			result.Login.Username = value
+	}
+	return result, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// Return the key used to encrypt the cipher fields, which is the item key
+// if it is set, or else the user or organization key
+func (c Cipher) itemKey(k *crypto.CryptoKey) (*crypto.CryptoKey, error) {
+	if c.Key == "" {
+		return k, nil
+	}
+	return k.DecryptKey(c.Key)
+}
+
+// Return a copy of the cipher which does not share any encrypted fields
+func (c Cipher) copy() *Cipher {
+	result := &c
+	if c.Login != nil {
+		result.Login = c.Login.copy()
+	}
+	if c.Card != nil {
+		card := *c.Card
+		result.Card = &card
+	}
+	if c.SecureNote != nil {
+		note := *c.SecureNote
+		result.SecureNote = &note
+	}
+	if c.Identity != nil {
+		identity := *c.Identity
+		result.Identity = &identity
+	}
+	if c.SshKey != nil {
+		key := *c.SshKey
+		result.SshKey = &key
+	}
+	result.Fields = copyValues(c.Fields)
+	result.PasswordHistory = copyValues(c.PasswordHistory)
+	result.Attachments = copyValues(c.Attachments)
+	return result
+}
+
+// Encrypt or decrypt the encrypted fields of the cipher in place
+func (c *Cipher) crypt(fn func(string) (string, error)) error {
+	if err := cryptStrings(fn, &c.Name, &c.Notes); err != nil {
+		return err
+	}
+	if c.Login != nil {
+		if err := c.Login.crypt(fn); err != nil {
+			return err
+		}
+	}
+	if c.Card != nil {
+		if err := c.Card.crypt(fn); err != nil {
+			return err
 		}
-		if value, err := k.DecryptStr(result.Login.Password); err != nil {
-			return nil, err
-		} else {
-			result.Login.Password = value
+	}
+	if c.Identity != nil {
+		if err := c.Identity.crypt(fn); err != nil {
+			return err
 		}
-		if value, err := k.DecryptStr(result.Login.URI); err != nil {
-			return nil, err
-		} else {
-			result.Login.URI = value
+	}
+	if c.SshKey != nil {
+		if err := c.SshKey.crypt(fn); err != nil {
+			return err
 		}
 	}
-	return result, nil
+	for _, field := range c.Fields {
+		if err := field.crypt(fn); err != nil {
+			return err
+		}
+	}
+	for _, history := range c.PasswordHistory {
+		if err := history.crypt(fn); err != nil {
+			return err
+		}
+	}
+	for _, attachment := range c.Attachments {
+		if err := attachment.crypt(fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Return a copy of a slice of values, where each value is also copied
+func copyValues[T any](values []*T) []*T {
+	if values == nil {
+		return nil
+	}
+	result := make([]*T, len(values))
+	for i, value := range values {
+		if value != nil {
+			v := *value
+			result[i] = &v
+		}
+	}
+	return result
 }
@@ -0,0 +1,116 @@
+package schema_test
+
+import (
+	"testing"
+
+	// Packages
+	"github.com/mutablelogic/go-client/pkg/bitwarden/crypto"
+	"github.com/mutablelogic/go-client/pkg/bitwarden/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_cipher_001(t *testing.T) {
+	assert := assert.New(t)
+	key, err := crypto.MakeInternalKey("nobody@example.com", "p4ssw0rd", 0, 5000, 0, 0)
+	assert.NoError(err)
+	k := crypto.NewKey(key, key)
+
+	cipher := &schema.Cipher{
+		Name: "name",
+		Type: schema.CipherTypeLogin,
+		Login: &schema.CipherLogin{
+			Username: "username",
+			Password: "password",
+		},
+	}
+
+	// Encrypt returns a copy and does not modify the original
+	encrypted, err := cipher.Encrypt(k)
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+	assert.NotEqual("name", encrypted.(*schema.Cipher).Name)
+	assert.NotEqual("password", encrypted.(*schema.Cipher).Login.Password)
+	assert.Empty(encrypted.(*schema.Cipher).Login.URI)
+	assert.Equal("password", cipher.Login.Password)
+
+	// Decrypt returns the original values
+	decrypted, err := encrypted.Decrypt(k)
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+	assert.Equal(cipher, decrypted)
+}
+
+func Test_cipher_002(t *testing.T) {
+	assert := assert.New(t)
+	key, err := crypto.MakeInternalKey("nobody@example.com", "p4ssw0rd", 0, 5000, 0, 0)
+	assert.NoError(err)
+	k := crypto.NewKey(key, key)
+
+	folder := &schema.Folder{Name: "folder"}
+	encrypted, err := folder.Encrypt(k)
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+	assert.NotEqual("folder", encrypted.(*schema.Folder).Name)
+
+	decrypted, err := encrypted.Decrypt(k)
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+	assert.Equal(folder, decrypted)
+}
+
+func Test_cipher_003(t *testing.T) {
+	assert := assert.New(t)
+	key, err := crypto.MakeInternalKey("nobody@example.com", "p4ssw0rd", 0, 5000, 0, 0)
+	assert.NoError(err)
+	k := crypto.NewKey(key, key)
+
+	// Create an item key, wrapped with the user key
+	itemKey, err := crypto.MakeEncKey(append(key, key...))
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+
+	cipher := &schema.Cipher{
+		Name:  "name",
+		Notes: "notes",
+		Type:  schema.CipherTypeCard,
+		Key:   itemKey.String(),
+		Card: &schema.CipherCard{
+			CardholderName: "Nobody",
+			Number:         "4111111111111111",
+		},
+		Identity: &schema.CipherIdentity{
+			FirstName: "No",
+			LastName:  "Body",
+		},
+		Fields: []*schema.CipherField{
+			{Type: schema.FieldTypeHidden, Name: "pin", Value: "1234"},
+		},
+		PasswordHistory: []*schema.CipherPasswordHistory{
+			{Password: "old"},
+		},
+	}
+
+	// Fields are encrypted with the item key
+	encrypted, err := cipher.Encrypt(k)
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+	assert.Equal(itemKey.String(), encrypted.(*schema.Cipher).Key)
+	assert.NotEqual("4111111111111111", encrypted.(*schema.Cipher).Card.Number)
+	assert.NotEqual("1234", encrypted.(*schema.Cipher).Fields[0].Value)
+	assert.Equal("1234", cipher.Fields[0].Value)
+	_, err = k.DecryptStr(encrypted.(*schema.Cipher).Card.Number)
+	assert.ErrorIs(err, crypto.ErrMacError)
+
+	// Decrypt returns the original values
+	decrypted, err := encrypted.Decrypt(k)
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+	assert.Equal(cipher, decrypted)
+}
@@ -0,0 +1,64 @@
+package schema
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/mutablelogic/go-client/pkg/bitwarden/crypto"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+type Collections []*Collection
+
+// Collection is a group of ciphers shared within an organization
+type Collection struct {
+	Id             string `json:"id,width:36"`
+	OrganizationId string `json:"organizationId,width:36"`
+	Name           string `json:"name"` // Encrypted with the organization key
+	ExternalId     string `json:"externalId,omitempty"`
+	ReadOnly       bool   `json:"readOnly,width:5"`
+	HidePasswords  bool   `json:"hidePasswords,width:5"`
+	Manage         bool   `json:"manage,width:5"`
+	Object         string `json:"object,width:10"`
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Read a list of collections
+func (c *Collections) Read(r io.Reader) error {
+	return json.NewDecoder(r).Decode(c)
+}
+
+// Write a list of collections
+func (c *Collections) Write(w io.Writer) error {
+	return json.NewEncoder(w).Encode(c)
+}
+
+// Encrypt a collection and return a copy of it
+func (c Collection) Encrypt(k *crypto.CryptoKey) (Crypter, error) {
+	result := &c
+	if err := cryptStrings(k.EncryptStr, &result.Name); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Decrypt a collection and return a copy of it
+func (c Collection) Decrypt(k *crypto.CryptoKey) (Crypter, error) {
+	result := &c
+	if err := cryptStrings(k.DecryptStr, &result.Name); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// STRINGIFY
+
+func (c Collection) String() string {
+	data, _ := json.MarshalIndent(c, "", "  ")
+	return string(data)
+}
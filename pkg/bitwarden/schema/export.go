@@ -0,0 +1,333 @@
+package schema
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+
+	// Packages
+	crypto "github.com/mutablelogic/go-client/pkg/bitwarden/crypto"
+
+	// Namespace imports
+	. "github.com/djthorpe/go-errors"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// Export is a decrypted vault export in the Bitwarden JSON format. A personal
+// export contains folders, and an organization export contains collections.
+type Export struct {
+	Encrypted   bool        `json:"encrypted"`
+	Folders     Folders     `json:"folders,omitempty"`
+	Collections Collections `json:"collections,omitempty"`
+	Items       Ciphers     `json:"items"`
+}
+
+// EncryptedExport is a password-protected export, where the export is
+// encrypted with a key derived from the password and salt
+type EncryptedExport struct {
+	Encrypted         bool   `json:"encrypted"`
+	PasswordProtected bool   `json:"passwordProtected"`
+	Salt              string `json:"salt"`
+	KdfType           int    `json:"kdfType"`
+	KdfIterations     int    `json:"kdfIterations"`
+	KdfMemory         *int   `json:"kdfMemory"`
+	KdfParallelism    *int   `json:"kdfParallelism"`
+	Validation        string `json:"encKeyValidation_DO_NOT_EDIT"`
+	Data              string `json:"data"`
+}
+
+type exportFolder struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type exportCollection struct {
+	Id             string `json:"id"`
+	OrganizationId string `json:"organizationId"`
+	Name           string `json:"name"`
+	ExternalId     string `json:"externalId,omitempty"`
+}
+
+// The nested values of an item are the cipher values with camel case keys
+type exportItem struct {
+	Id              string          `json:"id"`
+	OrganizationId  string          `json:"organizationId,omitempty"`
+	FolderId        string          `json:"folderId,omitempty"`
+	Type            CipherType      `json:"type"`
+	Reprompt        CipherReprompt  `json:"reprompt"`
+	Name            string          `json:"name"`
+	Notes           string          `json:"notes,omitempty"`
+	Favorite        bool            `json:"favorite"`
+	Login           json.RawMessage `json:"login,omitempty"`
+	Card            json.RawMessage `json:"card,omitempty"`
+	SecureNote      json.RawMessage `json:"secureNote,omitempty"`
+	Identity        json.RawMessage `json:"identity,omitempty"`
+	SshKey          json.RawMessage `json:"sshKey,omitempty"`
+	Fields          json.RawMessage `json:"fields,omitempty"`
+	PasswordHistory json.RawMessage `json:"passwordHistory,omitempty"`
+	CollectionIds   []string        `json:"collectionIds,omitempty"`
+	RevisionDate    time.Time       `json:"revisionDate"`
+	DeletedDate     *time.Time      `json:"deletedDate,omitempty"`
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// GLOBALS
+
+const (
+	exportSaltSize = 16
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Read an unencrypted export
+func (e *Export) Read(r io.Reader) error {
+	if err := json.NewDecoder(r).Decode(e); err != nil {
+		return err
+	} else if e.Encrypted {
+		return ErrBadParameter.With("export is encrypted")
+	}
+	return nil
+}
+
+// Write an unencrypted export
+func (e *Export) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(e)
+}
+
+// MarshalJSON writes the export with the item keys in camel case, as the
+// Bitwarden clients do
+func (e Export) MarshalJSON() ([]byte, error) {
+	var export struct {
+		Encrypted   bool                `json:"encrypted"`
+		Folders     *[]exportFolder     `json:"folders,omitempty"`
+		Collections *[]exportCollection `json:"collections,omitempty"`
+		Items       []exportItem        `json:"items"`
+	}
+
+	// Organization exports have collections, personal exports have folders
+	if e.Collections != nil {
+		collections := make([]exportCollection, 0, len(e.Collections))
+		for _, collection := range e.Collections {
+			collections = append(collections, exportCollection{collection.Id, collection.OrganizationId, collection.Name, collection.ExternalId})
+		}
+		export.Collections = &collections
+	} else {
+		folders := make([]exportFolder, 0, len(e.Folders))
+		for _, folder := range e.Folders {
+			folders = append(folders, exportFolder{folder.Id, folder.Name})
+		}
+		export.Folders = &folders
+	}
+
+	// Items
+	export.Items = make([]exportItem, 0, len(e.Items))
+	for _, cipher := range e.Items {
+		item := exportItem{
+			Id:             cipher.Id,
+			OrganizationId: cipher.OrganizationId,
+			FolderId:       cipher.FolderId,
+			Type:           cipher.Type,
+			Reprompt:       cipher.Reprompt,
+			Name:           cipher.Name,
+			Notes:          cipher.Notes,
+			Favorite:       cipher.Favorite,
+			CollectionIds:  cipher.CollectionIds,
+			RevisionDate:   cipher.RevisionDate,
+			DeletedDate:    cipher.DeletedDate,
+		}
+		for _, v := range []struct {
+			dest  *json.RawMessage
+			value any
+		}{
+			{&item.Login, cipher.Login},
+			{&item.Card, cipher.Card},
+			{&item.SecureNote, cipher.SecureNote},
+			{&item.Identity, cipher.Identity},
+			{&item.SshKey, cipher.SshKey},
+			{&item.Fields, cipher.Fields},
+			{&item.PasswordHistory, cipher.PasswordHistory},
+		} {
+			if data, err := camelJSON(v.value); err != nil {
+				return nil, err
+			} else {
+				*v.dest = data
+			}
+		}
+		export.Items = append(export.Items, item)
+	}
+
+	// Return the export
+	return json.Marshal(export)
+}
+
+// Encrypt the export with a password, using the key derivation parameters
+func (e *Export) Encrypt(password string, kdf Kdf) (*EncryptedExport, error) {
+	if password == "" {
+		return nil, ErrBadParameter.With("missing password")
+	}
+	salt := make([]byte, exportSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	result := &EncryptedExport{
+		Encrypted:         true,
+		PasswordProtected: true,
+		Salt:              base64.StdEncoding.EncodeToString(salt),
+		KdfType:           kdf.Type,
+		KdfIterations:     kdf.Iterations,
+	}
+	if kdf.Type == crypto.KdfArgon2id {
+		result.KdfMemory = &kdf.Memory
+		result.KdfParallelism = &kdf.Parallelism
+	}
+
+	// Make the key
+	key, err := result.key(password)
+	if err != nil {
+		return nil, err
+	}
+
+	// Encrypt the validation value and the export
+	plain := *e
+	plain.Encrypted = false
+	if data, err := json.Marshal(plain); err != nil {
+		return nil, err
+	} else if encrypted, err := key.Encrypt(data); err != nil {
+		return nil, err
+	} else {
+		result.Data = encrypted.String()
+	}
+	if validation, err := key.EncryptStr(MakeDeviceIdentifier()); err != nil {
+		return nil, err
+	} else {
+		result.Validation = validation
+	}
+
+	// Return success
+	return result, nil
+}
+
+// Read a password-protected export
+func (e *EncryptedExport) Read(r io.Reader) error {
+	if err := json.NewDecoder(r).Decode(e); err != nil {
+		return err
+	} else if !e.Encrypted || !e.PasswordProtected {
+		return ErrBadParameter.With("export is not password protected")
+	}
+	return nil
+}
+
+// Write a password-protected export
+func (e *EncryptedExport) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(e)
+}
+
+// Decrypt a password-protected export
+func (e *EncryptedExport) Decrypt(password string) (*Export, error) {
+	var result Export
+	key, err := e.key(password)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the password
+	if _, err := key.DecryptStr(e.Validation); err != nil {
+		return nil, ErrNotAuthorized.With("invalid export password")
+	}
+
+	// Decrypt the export
+	if encrypted, err := crypto.NewEncrypted(e.Data); err != nil {
+		return nil, err
+	} else if data, err := key.Decrypt(encrypted); err != nil {
+		return nil, err
+	} else if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+
+	// Return success
+	return &result, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// Return the key derived from the password and salt
+func (e *EncryptedExport) key(password string) (*crypto.CryptoKey, error) {
+	var memory, parallelism int
+	if e.KdfMemory != nil {
+		memory = *e.KdfMemory
+	}
+	if e.KdfParallelism != nil {
+		parallelism = *e.KdfParallelism
+	}
+	if key, err := crypto.MakeInternalKey(e.Salt, password, e.KdfType, e.KdfIterations, memory, parallelism); err != nil {
+		return nil, err
+	} else {
+		return crypto.StretchKey(key), nil
+	}
+}
+
+// Marshal a value with camel case keys, or return nil if the value is nil
+// or empty
+func camelJSON(v any) (json.RawMessage, error) {
+	var value any
+	if rv := reflect.ValueOf(v); !rv.IsValid() {
+		return nil, nil
+	} else if (rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Slice) && rv.IsNil() {
+		return nil, nil
+	} else if rv.Kind() == reflect.Slice && rv.Len() == 0 {
+		return nil, nil
+	}
+	if data, err := json.Marshal(v); err != nil {
+		return nil, err
+	} else if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return json.Marshal(camelKeys(value))
+}
+
+// Convert map keys to camel case
+func camelKeys(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for key, value := range v {
+			result[camelCase(key)] = camelKeys(value)
+		}
+		return result
+	case []any:
+		for i, value := range v {
+			v[i] = camelKeys(value)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// Convert a key to camel case by lowering the leading capitals, for example
+// "Username" becomes "username" and "SSN" becomes "ssn"
+func camelCase(key string) string {
+	runes := []rune(key)
+	n := 0
+	for n < len(runes) && unicode.IsUpper(runes[n]) {
+		n++
+	}
+	if n > 1 && n < len(runes) {
+		// The last capital starts the next word
+		n--
+	}
+	return strings.ToLower(string(runes[:n])) + string(runes[n:])
+}
@@ -0,0 +1,135 @@
+package schema_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	// Packages
+	crypto "github.com/mutablelogic/go-client/pkg/bitwarden/crypto"
+	schema "github.com/mutablelogic/go-client/pkg/bitwarden/schema"
+	assert "github.com/stretchr/testify/assert"
+)
+
+func Test_export_001(t *testing.T) {
+	assert := assert.New(t)
+	export := makeExport()
+
+	// Write the export with camel case keys
+	var buf bytes.Buffer
+	assert.NoError(export.Write(&buf))
+	var data map[string]any
+	assert.NoError(json.Unmarshal(buf.Bytes(), &data))
+	assert.Equal(false, data["encrypted"])
+	assert.Len(data["folders"], 1)
+	assert.NotContains(data, "collections")
+	item := data["items"].([]any)[0].(map[string]any)
+	assert.Equal("Example", item["name"])
+	login := item["login"].(map[string]any)
+	assert.Equal("user", login["username"])
+	assert.Equal("https://example.com", login["uris"].([]any)[0].(map[string]any)["uri"])
+	assert.Equal("secret", item["fields"].([]any)[0].(map[string]any)["value"])
+
+	// Read the export
+	var result schema.Export
+	assert.NoError(result.Read(&buf))
+	assert.Equal("Example", result.Items[0].Name)
+	assert.Equal("user", result.Items[0].Login.Username)
+	assert.Equal("https://example.com", result.Items[0].Login.URIs[0].URI)
+	assert.Equal("Personal", result.Folders[0].Name)
+}
+
+func Test_export_002(t *testing.T) {
+	assert := assert.New(t)
+	export := makeExport()
+
+	// Encrypt and decrypt the export
+	for _, kdf := range []schema.Kdf{
+		{Type: crypto.KdfPBKDF2, Iterations: 1000},
+		{Type: crypto.KdfArgon2id, Iterations: 1, Memory: 16, Parallelism: 1},
+	} {
+		encrypted, err := export.Encrypt("password", kdf)
+		if !assert.NoError(err) {
+			t.FailNow()
+		}
+		assert.True(encrypted.Encrypted)
+		assert.True(encrypted.PasswordProtected)
+		assert.NotContains(encrypted.Data, "Example")
+
+		// Write and read
+		var buf bytes.Buffer
+		assert.NoError(encrypted.Write(&buf))
+		var result schema.EncryptedExport
+		assert.NoError(result.Read(&buf))
+
+		// Wrong password
+		_, err = result.Decrypt("wrong")
+		assert.Error(err)
+
+		// Correct password
+		decrypted, err := result.Decrypt("password")
+		if !assert.NoError(err) {
+			t.FailNow()
+		}
+		assert.False(decrypted.Encrypted)
+		assert.Equal("pass", decrypted.Items[0].Login.Password)
+	}
+}
+
+func Test_export_003(t *testing.T) {
+	assert := assert.New(t)
+	export := makeExport()
+
+	// Write CSV
+	var buf bytes.Buffer
+	assert.NoError(export.WriteCSV(&buf))
+	lines := strings.SplitN(buf.String(), "\n", 2)
+	assert.Equal("folder,favorite,type,name,notes,fields,reprompt,login_uri,login_username,login_password,login_totp", lines[0])
+	assert.Contains(lines[1], "Personal,1,login,Example,")
+
+	// Read CSV
+	var result schema.Export
+	assert.NoError(result.ReadCSV(&buf))
+	assert.Len(result.Items, 2)
+	assert.Equal("Personal", result.Folders[0].Name)
+	assert.Equal("Personal", result.Items[0].FolderId)
+	assert.True(result.Items[0].Favorite)
+	assert.Equal("pass", result.Items[0].Login.Password)
+	assert.Equal("https://example.com", result.Items[0].Login.URIs[0].URI)
+	assert.Equal("token", result.Items[0].Fields[0].Name)
+	assert.Equal("secret", result.Items[0].Fields[0].Value)
+	assert.Equal(schema.CipherTypeNote, result.Items[1].Type)
+	assert.Equal("Some notes", result.Items[1].Notes)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+func makeExport() *schema.Export {
+	return &schema.Export{
+		Folders: schema.Folders{{Id: "folder", Name: "Personal"}},
+		Items: schema.Ciphers{
+			{
+				Id:       "login",
+				FolderId: "folder",
+				Type:     schema.CipherTypeLogin,
+				Name:     "Example",
+				Favorite: true,
+				Login: &schema.CipherLogin{
+					Username: "user",
+					Password: "pass",
+					URIs:     []*schema.CipherLoginURI{{URI: "https://example.com"}},
+				},
+				Fields: []*schema.CipherField{{Type: schema.FieldTypeText, Name: "token", Value: "secret"}},
+			},
+			{
+				Id:         "note",
+				Type:       schema.CipherTypeNote,
+				Name:       "Note",
+				Notes:      "Some notes",
+				SecureNote: &schema.CipherSecureNote{},
+			},
+		},
+	}
+}
@@ -0,0 +1,215 @@
+package schema
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+
+	// Namespace imports
+	. "github.com/djthorpe/go-errors"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// GLOBALS
+
+// CSV columns for personal and organization exports. Only login and secure
+// note items can be exported as CSV.
+var (
+	csvPersonalHeader     = []string{"folder", "favorite", "type", "name", "notes", "fields", "reprompt", "login_uri", "login_username", "login_password", "login_totp"}
+	csvOrganizationHeader = []string{"collections", "type", "name", "notes", "fields", "reprompt", "login_uri", "login_username", "login_password", "login_totp"}
+)
+
+const (
+	csvTypeLogin = "login"
+	csvTypeNote  = "note"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// WriteCSV writes the login and secure note items in the Bitwarden CSV
+// format. Other item types are skipped.
+func (e *Export) WriteCSV(w io.Writer) error {
+	enc := csv.NewWriter(w)
+	organization := e.Collections != nil
+
+	// Names of folders and collections
+	names := make(map[string]string)
+	for _, folder := range e.Folders {
+		names[folder.Id] = folder.Name
+	}
+	for _, collection := range e.Collections {
+		names[collection.Id] = collection.Name
+	}
+
+	// Write the header
+	header := csvPersonalHeader
+	if organization {
+		header = csvOrganizationHeader
+	}
+	if err := enc.Write(header); err != nil {
+		return err
+	}
+
+	// Write the items
+	for _, item := range e.Items {
+		row := make(map[string]string, len(header))
+		switch item.Type {
+		case CipherTypeLogin:
+			row["type"] = csvTypeLogin
+		case CipherTypeNote:
+			row["type"] = csvTypeNote
+		default:
+			continue
+		}
+		if organization {
+			collections := make([]string, 0, len(item.CollectionIds))
+			for _, id := range item.CollectionIds {
+				collections = append(collections, names[id])
+			}
+			row["collections"] = strings.Join(collections, ",")
+		} else {
+			row["folder"] = names[item.FolderId]
+		}
+		if item.Favorite {
+			row["favorite"] = "1"
+		}
+		row["name"] = item.Name
+		row["notes"] = item.Notes
+		fields := make([]string, 0, len(item.Fields))
+		for _, field := range item.Fields {
+			fields = append(fields, field.Name+": "+field.Value)
+		}
+		row["fields"] = strings.Join(fields, "\n")
+		if item.Reprompt != CipherRepromptNone {
+			row["reprompt"] = "1"
+		}
+		if item.Login != nil {
+			uris := make([]string, 0, len(item.Login.URIs))
+			for _, uri := range item.Login.URIs {
+				uris = append(uris, uri.URI)
+			}
+			row["login_uri"] = strings.Join(uris, ",")
+			row["login_username"] = item.Login.Username
+			row["login_password"] = item.Login.Password
+			row["login_totp"] = item.Login.TOTP
+		}
+
+		// Write the row in header order
+		record := make([]string, len(header))
+		for i, column := range header {
+			record[i] = row[column]
+		}
+		if err := enc.Write(record); err != nil {
+			return err
+		}
+	}
+
+	// Flush
+	enc.Flush()
+	return enc.Error()
+}
+
+// ReadCSV reads items in the Bitwarden CSV format. Folders and collections
+// are identified by name.
+func (e *Export) ReadCSV(r io.Reader) error {
+	dec := csv.NewReader(r)
+	dec.FieldsPerRecord = -1
+
+	// Read the header
+	header, err := dec.Read()
+	if err != nil {
+		return err
+	}
+	columns := make(map[string]int, len(header))
+	for i, column := range header {
+		columns[strings.TrimSpace(strings.TrimPrefix(column, "\ufeff"))] = i
+	}
+	if _, exists := columns["name"]; !exists {
+		return ErrBadParameter.With("missing name column")
+	}
+	_, organization := columns["collections"]
+
+	// Reset the export
+	*e = Export{}
+	if organization {
+		e.Collections = Collections{}
+	}
+	folders := make(map[string]bool)
+	collections := make(map[string]bool)
+
+	// Read the rows
+	for {
+		record, err := dec.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		value := func(column string) string {
+			if i, exists := columns[column]; exists && i < len(record) {
+				return record[i]
+			}
+			return ""
+		}
+
+		// Set the item
+		item := &Cipher{
+			Name:     value("name"),
+			Notes:    value("notes"),
+			Favorite: value("favorite") == "1",
+		}
+		if value("reprompt") == "1" {
+			item.Reprompt = CipherRepromptPassword
+		}
+		if value("type") == csvTypeNote {
+			item.Type = CipherTypeNote
+			item.SecureNote = &CipherSecureNote{}
+		} else {
+			item.Type = CipherTypeLogin
+			item.Login = &CipherLogin{
+				Username: value("login_username"),
+				Password: value("login_password"),
+				TOTP:     value("login_totp"),
+			}
+			for _, uri := range strings.Split(value("login_uri"), ",") {
+				if uri = strings.TrimSpace(uri); uri != "" {
+					item.Login.URIs = append(item.Login.URIs, &CipherLoginURI{URI: uri})
+				}
+			}
+		}
+
+		// Custom fields are "name: value" lines
+		for _, line := range strings.Split(value("fields"), "\n") {
+			if line == "" {
+				continue
+			}
+			name, text, _ := strings.Cut(line, ": ")
+			item.Fields = append(item.Fields, &CipherField{Type: FieldTypeText, Name: name, Value: text})
+		}
+
+		// Folder or collections
+		if folder := value("folder"); folder != "" {
+			if !folders[folder] {
+				folders[folder] = true
+				e.Folders = append(e.Folders, &Folder{Id: folder, Name: folder})
+			}
+			item.FolderId = folder
+		}
+		for _, collection := range strings.Split(value("collections"), ",") {
+			if collection = strings.TrimSpace(collection); collection == "" {
+				continue
+			} else if !collections[collection] {
+				collections[collection] = true
+				e.Collections = append(e.Collections, &Collection{Id: collection, Name: collection})
+			}
+			item.CollectionIds = append(item.CollectionIds, collection)
+		}
+
+		// Append the item
+		e.Items = append(e.Items, item)
+	}
+
+	// Return success
+	return nil
+}
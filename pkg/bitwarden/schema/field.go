@@ -0,0 +1,90 @@
+package schema
+
+import (
+	"time"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// CipherField is a custom field on a cipher
+type CipherField struct {
+	Type     FieldType `json:"Type"`
+	Name     string    `json:"Name,omitempty"`  // crypt
+	Value    string    `json:"Value,omitempty"` // crypt
+	LinkedId *uint     `json:"LinkedId,omitempty"`
+}
+
+type FieldType uint
+
+// CipherPasswordHistory is a previous password for a login cipher
+type CipherPasswordHistory struct {
+	Password     string    `json:"Password"` // crypt
+	LastUsedDate time.Time `json:"LastUsedDate"`
+}
+
+// CipherAttachment is a file attached to a cipher. The attachment data is
+// encrypted with the attachment key, which is itself encrypted.
+type CipherAttachment struct {
+	Id       string `json:"Id"`
+	URL      string `json:"Url,omitempty"`
+	FileName string `json:"FileName"` // crypt
+	Key      string `json:"Key,omitempty"`
+	Size     string `json:"Size,omitempty"`
+	SizeName string `json:"SizeName,omitempty"`
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	FieldTypeText FieldType = iota
+	FieldTypeHidden
+	FieldTypeBoolean
+	FieldTypeLinked
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// STRINGIFY
+
+func (t FieldType) String() string {
+	switch t {
+	case FieldTypeText:
+		return "text"
+	case FieldTypeHidden:
+		return "hidden"
+	case FieldTypeBoolean:
+		return "boolean"
+	case FieldTypeLinked:
+		return "linked"
+	default:
+		return "unknown"
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// Encrypt or decrypt the encrypted fields of the custom field in place
+func (f *CipherField) crypt(fn func(string) (string, error)) error {
+	if f == nil {
+		return nil
+	}
+	return cryptStrings(fn, &f.Name, &f.Value)
+}
+
+// Encrypt or decrypt the encrypted fields of the password history in place
+func (h *CipherPasswordHistory) crypt(fn func(string) (string, error)) error {
+	if h == nil {
+		return nil
+	}
+	return cryptStrings(fn, &h.Password)
+}
+
+// Encrypt or decrypt the encrypted fields of the attachment in place
+func (a *CipherAttachment) crypt(fn func(string) (string, error)) error {
+	if a == nil {
+		return nil
+	}
+	return cryptStrings(fn, &a.FileName)
+}
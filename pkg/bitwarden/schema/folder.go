@@ -33,13 +33,20 @@ func (f *Folders) Write(w io.Writer) error {
 	return json.NewEncoder(w).Encode(f)
 }
 
-// Decrypt a folder
+// Encrypt a folder and return a copy of it
+func (f Folder) Encrypt(k *crypto.CryptoKey) (Crypter, error) {
+	result := &f
+	if err := cryptStrings(k.EncryptStr, &result.Name); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Decrypt a folder and return a copy of it
 func (f Folder) Decrypt(k *crypto.CryptoKey) (Crypter, error) {
 	result := &f
-	if value, err := k.DecryptStr(result.Name); err != nil {
+	if err := cryptStrings(k.DecryptStr, &result.Name); err != nil {
 		return nil, err
-	} else {
-		result.Name = value
 	}
 	return result, nil
 }
@@ -0,0 +1,37 @@
+package schema
+
+///////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+type CipherIdentity struct {
+	Title          string `json:"Title,omitempty"`          // crypt
+	FirstName      string `json:"FirstName,omitempty"`      // crypt
+	MiddleName     string `json:"MiddleName,omitempty"`     // crypt
+	LastName       string `json:"LastName,omitempty"`       // crypt
+	Address1       string `json:"Address1,omitempty"`       // crypt
+	Address2       string `json:"Address2,omitempty"`       // crypt
+	Address3       string `json:"Address3,omitempty"`       // crypt
+	City           string `json:"City,omitempty"`           // crypt
+	State          string `json:"State,omitempty"`          // crypt
+	PostalCode     string `json:"PostalCode,omitempty"`     // crypt
+	Country        string `json:"Country,omitempty"`        // crypt
+	Company        string `json:"Company,omitempty"`        // crypt
+	Email          string `json:"Email,omitempty"`          // crypt
+	Phone          string `json:"Phone,omitempty"`          // crypt
+	SSN            string `json:"SSN,omitempty"`            // crypt
+	Username       string `json:"Username,omitempty"`       // crypt
+	PassportNumber string `json:"PassportNumber,omitempty"` // crypt
+	LicenseNumber  string `json:"LicenseNumber,omitempty"`  // crypt
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// Encrypt or decrypt the encrypted fields of the identity in place
+func (i *CipherIdentity) crypt(fn func(string) (string, error)) error {
+	return cryptStrings(fn,
+		&i.Title, &i.FirstName, &i.MiddleName, &i.LastName,
+		&i.Address1, &i.Address2, &i.Address3, &i.City, &i.State, &i.PostalCode, &i.Country,
+		&i.Company, &i.Email, &i.Phone, &i.SSN, &i.Username, &i.PassportNumber, &i.LicenseNumber,
+	)
+}
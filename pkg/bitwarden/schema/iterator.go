@@ -13,7 +13,7 @@ import (
 
 type Iterable interface {
 	Crypter
-	*Folder | *Cipher
+	*Folder | *Cipher | *Collection
 }
 
 // Iterate over values
@@ -22,13 +22,17 @@ type Iterator[T Iterable] interface {
 	Next() T
 
 	// Compute and return the encryption/decryption key from the profile,
-	// password and KDF parameters
+	// password and KDF parameters. The organization keys are also computed
+	// from the profile, for values which belong to an organization
 	CryptKey(*Profile, string, Kdf) (*crypto.CryptoKey, error)
 
 	// CanCrypt returns true if the iterator has a key
 	// for encryption and decryption
 	CanCrypt() bool
 
+	// Encrypt the value and return a copy of it
+	Encrypt(T) (T, error)
+
 	// Decrypt the value and return a copy of it
 	Decrypt(T) (T, error)
 }
@@ -38,6 +42,7 @@ type iterator[T Iterable] struct {
 	n        int
 	values   []T
 	cryptKey *crypto.CryptoKey
+	orgKeys  map[string]*crypto.CryptoKey
 }
 
 /////////////////////////////////////////////////////////////////////////////////
@@ -79,19 +84,39 @@ func (i *iterator[T]) CryptKey(profile *Profile, passwd string, kdf Kdf) (*crypt
 	if err != nil {
 		return nil, err
 	}
+	orgKeys, err := profile.OrganizationKeys(key)
+	if err != nil {
+		return nil, err
+	}
 
-	// Cache the key
+	// Cache the keys
 	i.cryptKey = key
+	i.orgKeys = orgKeys
 	return key, nil
 }
 
+// Encrypt T and return a new copy of T
+func (i *iterator[T]) Encrypt(v T) (T, error) {
+	if v == nil {
+		return nil, nil
+	} else if key, err := i.key(v); err != nil {
+		return nil, err
+	} else if v, err := v.Encrypt(key); err != nil {
+		return nil, err
+	} else if v, ok := v.(T); !ok {
+		panic("Unexpected type")
+	} else {
+		return v, nil
+	}
+}
+
 // Decrypt T and return a new copy of T
 func (i *iterator[T]) Decrypt(v T) (T, error) {
 	if v == nil {
 		return nil, nil
-	} else if i.cryptKey == nil {
-		return nil, ErrNotAuthorized.With("No decryption key")
-	} else if v, err := v.Decrypt(i.cryptKey); err != nil {
+	} else if key, err := i.key(v); err != nil {
+		return nil, err
+	} else if v, err := v.Decrypt(key); err != nil {
 		return nil, err
 	} else if v, ok := v.(T); !ok {
 		panic("Unexpected type")
@@ -99,3 +124,34 @@ func (i *iterator[T]) Decrypt(v T) (T, error) {
 		return v, nil
 	}
 }
+
+/////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// Return the key for a value, which is the organization key if the value
+// belongs to an organization, or the user key otherwise
+func (i *iterator[T]) key(v T) (*crypto.CryptoKey, error) {
+	if i.cryptKey == nil {
+		return nil, ErrNotAuthorized.With("No encryption key")
+	}
+	org := organizationId(v)
+	if org == "" {
+		return i.cryptKey, nil
+	} else if key, exists := i.orgKeys[org]; !exists {
+		return nil, ErrNotAuthorized.Withf("No encryption key for organization %q", org)
+	} else {
+		return key, nil
+	}
+}
+
+// Return the organization for a value, or empty string
+func organizationId(v any) string {
+	switch v := v.(type) {
+	case *Cipher:
+		return v.OrganizationId
+	case *Collection:
+		return v.OrganizationId
+	default:
+		return ""
+	}
+}
@@ -3,7 +3,11 @@ package schema
 ///////////////////////////////////////////////////////////////////////////////
 // TYPES
 
+// Kdf are the key derivation parameters for the master key. The memory
+// (in MiB) and parallelism parameters are only used for Argon2id.
 type Kdf struct {
-	Type       int `json:"kdf,right"`
-	Iterations int `json:"KdfIterations,right"`
+	Type        int `json:"kdf,right"`
+	Iterations  int `json:"KdfIterations,right"`
+	Memory      int `json:"KdfMemory,omitempty,right"`
+	Parallelism int `json:"KdfParallelism,omitempty,right"`
 }
@@ -0,0 +1,113 @@
+package schema
+
+import (
+	"time"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+type CipherLogin struct {
+	Username             string              `json:"Username,omitempty"` // crypt
+	Password             string              `json:"Password,omitempty"` // crypt
+	PasswordRevisionDate *time.Time          `json:"PasswordRevisionDate,omitempty"`
+	URI                  string              `json:"URI,omitempty"`  // crypt
+	URIs                 []*CipherLoginURI   `json:"Uris,omitempty"` // crypt
+	TOTP                 string              `json:"Totp,omitempty"` // crypt
+	AutofillOnPageLoad   *bool               `json:"AutofillOnPageLoad,omitempty"`
+	Fido2Credentials     []*CipherFido2Value `json:"Fido2Credentials,omitempty"` // crypt
+}
+
+type CipherLoginURI struct {
+	URI      string        `json:"Uri,omitempty"`         // crypt
+	Checksum string        `json:"UriChecksum,omitempty"` // crypt
+	Match    *URIMatchType `json:"Match,omitempty"`
+}
+
+// URIMatchType determines how a login URI is matched against a website
+type URIMatchType uint
+
+// CipherFido2Value is a passkey. All values except the creation date are
+// encrypted strings.
+type CipherFido2Value struct {
+	CredentialId    string    `json:"CredentialId"`              // crypt
+	KeyType         string    `json:"KeyType"`                   // crypt
+	KeyAlgorithm    string    `json:"KeyAlgorithm"`              // crypt
+	KeyCurve        string    `json:"KeyCurve"`                  // crypt
+	KeyValue        string    `json:"KeyValue"`                  // crypt
+	RpId            string    `json:"RpId"`                      // crypt
+	RpName          string    `json:"RpName,omitempty"`          // crypt
+	UserHandle      string    `json:"UserHandle,omitempty"`      // crypt
+	UserName        string    `json:"UserName,omitempty"`        // crypt
+	UserDisplayName string    `json:"UserDisplayName,omitempty"` // crypt
+	Counter         string    `json:"Counter"`                   // crypt
+	Discoverable    string    `json:"Discoverable"`              // crypt
+	CreationDate    time.Time `json:"CreationDate"`
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	URIMatchDomain URIMatchType = iota
+	URIMatchHost
+	URIMatchStartsWith
+	URIMatchExact
+	URIMatchRegularExpression
+	URIMatchNever
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// STRINGIFY
+
+func (m URIMatchType) String() string {
+	switch m {
+	case URIMatchDomain:
+		return "domain"
+	case URIMatchHost:
+		return "host"
+	case URIMatchStartsWith:
+		return "startswith"
+	case URIMatchExact:
+		return "exact"
+	case URIMatchRegularExpression:
+		return "regex"
+	case URIMatchNever:
+		return "never"
+	default:
+		return "unknown"
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// Return a copy of the login which does not share any encrypted fields
+func (l CipherLogin) copy() *CipherLogin {
+	result := &l
+	result.URIs = copyValues(l.URIs)
+	result.Fido2Credentials = copyValues(l.Fido2Credentials)
+	return result
+}
+
+// Encrypt or decrypt the encrypted fields of the login in place
+func (l *CipherLogin) crypt(fn func(string) (string, error)) error {
+	if err := cryptStrings(fn, &l.Username, &l.Password, &l.URI, &l.TOTP); err != nil {
+		return err
+	}
+	for _, uri := range l.URIs {
+		if uri == nil {
+			continue
+		} else if err := cryptStrings(fn, &uri.URI, &uri.Checksum); err != nil {
+			return err
+		}
+	}
+	for _, value := range l.Fido2Credentials {
+		if value == nil {
+			continue
+		} else if err := cryptStrings(fn, &value.CredentialId, &value.KeyType, &value.KeyAlgorithm, &value.KeyCurve, &value.KeyValue, &value.RpId, &value.RpName, &value.UserHandle, &value.UserName, &value.UserDisplayName, &value.Counter, &value.Discoverable); err != nil {
+			return err
+		}
+	}
+	return nil
+}
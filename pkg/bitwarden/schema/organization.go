@@ -13,6 +13,7 @@ type Organization struct {
 	Enabled    bool   `json:"enabled"`
 	Identifier string `json:"identifier"`
 	UserId     string `json:"userId"`
+	Key        string `json:"key,omitempty"` // Encrypted with the user public key
 	Object     string `json:"object"`
 }
 
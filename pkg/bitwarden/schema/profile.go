@@ -1,14 +1,12 @@
 package schema
 
 import (
-	"crypto/sha256"
 	"encoding/json"
 	"io"
 	"strings"
 
 	// Packages
 	crypto "github.com/mutablelogic/go-client/pkg/bitwarden/crypto"
-	hkdf "golang.org/x/crypto/hkdf"
 
 	// Nanmepsace imports
 	. "github.com/djthorpe/go-errors"
@@ -23,6 +21,7 @@ type Profile struct {
 	Email                   string          `json:"email"`
 	EmailVerified           bool            `json:"emailVerified" writer:",width:5,right,omitempty"`
 	Key                     string          `json:"key" writer:",wrap,omitempty"`
+	PrivateKey              string          `json:"privateKey,omitempty" writer:",wrap,omitempty"`
 	Premium                 bool            `json:"premium" writer:",width:5,right,omitempty"`
 	PremiumFromOrganization bool            `json:"premiumFromOrganization" writer:",width:5,right,omitempty"`
 	Culture                 string          `json:"culture" writer:",width:5,right,omitempty"`
@@ -73,35 +72,70 @@ func (p *Profile) MakeKey(kdf Kdf, passwd string) (*crypto.CryptoKey, error) {
 	// Cache the key
 	if encryptedKey, err := crypto.NewEncrypted(p.Key); err != nil {
 		return nil, err
-	} else if decryptKey := makeDecryptKey(strings.ToLower(p.Email), passwd, kdf, encryptedKey); decryptKey == nil {
+	} else if internalKey, err := crypto.MakeInternalKey(strings.ToLower(p.Email), passwd, kdf.Type, kdf.Iterations, kdf.Memory, kdf.Parallelism); err != nil {
+		return nil, err
+	} else if decryptKey := makeDecryptKey(internalKey, encryptedKey); decryptKey == nil {
 		return nil, ErrNotAuthorized.With("Failed to create crypt key")
 	} else {
 		return decryptKey, nil
 	}
 }
 
-///////////////////////////////////////////////////////////////////////////////
-// PRIVATE METHODS
+// OrganizationKeys decrypts the private key with the user key, and uses
+// it to decrypt the key for each organization. Returns a map of organization
+// id to key, which is empty if the user is not a member of any organization.
+func (p *Profile) OrganizationKeys(userKey *crypto.CryptoKey) (map[string]*crypto.CryptoKey, error) {
+	result := make(map[string]*crypto.CryptoKey, len(p.Organizations))
+	if len(p.Organizations) == 0 {
+		return result, nil
+	} else if p.PrivateKey == "" || userKey == nil {
+		return nil, ErrBadParameter.With("OrganizationKeys requires profile private key and user key")
+	}
 
-// Make a decryption key
-func makeDecryptKey(salt, passwd string, k Kdf, cipher *crypto.Encrypted) *crypto.CryptoKey {
-	// Create the internal key
-	internalKey := crypto.MakeInternalKey(salt, passwd, k.Type, k.Iterations)
-	if internalKey == nil {
-		return nil
+	// Decrypt the private key
+	privateKey, err := crypto.NewEncrypted(p.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	der, err := userKey.Decrypt(privateKey)
+	if err != nil {
+		return nil, err
 	}
+	rsaKey, err := crypto.ParsePrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decrypt each organization key
+	for _, org := range p.Organizations {
+		if org == nil || org.Key == "" {
+			continue
+		}
+		value, err := crypto.DecryptAsymmetric(rsaKey, org.Key)
+		if err != nil {
+			return nil, ErrNotAuthorized.Withf("organization %q: %v", org.Id, err)
+		} else if len(value) != 64 {
+			return nil, ErrNotAuthorized.Withf("organization %q: invalid key length %d", org.Id, len(value))
+		}
+		result[org.Id] = crypto.NewKey(value[:32], value[32:])
+	}
+
+	// Return success
+	return result, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
 
+// Make a decryption key from the internal key
+func makeDecryptKey(internalKey []byte, cipher *crypto.Encrypted) *crypto.CryptoKey {
 	// Create the (key,mac) from the internalKey
 	var key *crypto.CryptoKey
 	switch cipher.Type {
 	case 0:
 		key = crypto.NewKey(internalKey, nil)
 	case 2:
-		value := make([]byte, 32)
-		mac := make([]byte, 32)
-		hkdf.Expand(sha256.New, internalKey, []byte("enc")).Read(value)
-		hkdf.Expand(sha256.New, internalKey, []byte("mac")).Read(mac)
-		key = crypto.NewKey(value, mac)
+		key = crypto.StretchKey(internalKey)
 	default:
 		return nil
 	}
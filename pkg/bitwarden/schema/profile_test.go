@@ -0,0 +1,111 @@
+package schema_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/base64"
+	"testing"
+
+	// Packages
+	"github.com/mutablelogic/go-client/pkg/bitwarden/crypto"
+	"github.com/mutablelogic/go-client/pkg/bitwarden/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_profile_001(t *testing.T) {
+	assert := assert.New(t)
+	kdf := schema.Kdf{Type: crypto.KdfPBKDF2, Iterations: 5000}
+
+	// Make a profile with an organization, and the keys for the user and organization
+	profile, userKey, orgKey := makeProfile(t, kdf, "p4ssw0rd")
+
+	// Decrypt the organization keys
+	key, err := profile.MakeKey(kdf, "p4ssw0rd")
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+	assert.Equal(userKey, key)
+	keys, err := profile.OrganizationKeys(key)
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+	assert.Equal(map[string]*crypto.CryptoKey{"org": orgKey}, keys)
+
+	// Encrypt a personal and organization cipher
+	personal, err := schema.Cipher{Name: "personal"}.Encrypt(userKey)
+	assert.NoError(err)
+	shared, err := schema.Cipher{Name: "shared", OrganizationId: "org"}.Encrypt(orgKey)
+	assert.NoError(err)
+	other, err := schema.Cipher{Name: "other", OrganizationId: "other"}.Encrypt(orgKey)
+	assert.NoError(err)
+
+	// The iterator chooses the key for each cipher
+	iterator := schema.NewIterator(schema.Ciphers{personal.(*schema.Cipher), shared.(*schema.Cipher), other.(*schema.Cipher)})
+	_, err = iterator.CryptKey(profile, "p4ssw0rd", kdf)
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+	cipher, err := iterator.Decrypt(iterator.Next())
+	assert.NoError(err)
+	assert.Equal("personal", cipher.Name)
+	cipher, err = iterator.Decrypt(iterator.Next())
+	assert.NoError(err)
+	assert.Equal("shared", cipher.Name)
+	_, err = iterator.Decrypt(iterator.Next())
+	assert.Error(err)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// Return a profile with an encrypted user key, private key and organization key
+func makeProfile(t *testing.T, kdf schema.Kdf, passwd string) (*schema.Profile, *crypto.CryptoKey, *crypto.CryptoKey) {
+	// Master key
+	masterKey, err := crypto.MakeInternalKey("nobody@example.com", passwd, kdf.Type, kdf.Iterations, kdf.Memory, kdf.Parallelism)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// User key, encrypted with the master key
+	userKeyData := make([]byte, 64)
+	rand.Read(userKeyData)
+	encUserKey, err := crypto.NewKey(masterKey, nil).Encrypt(userKeyData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userKey := crypto.NewKey(userKeyData[:32], userKeyData[32:])
+
+	// Private key, encrypted with the user key
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encPrivateKey, err := userKey.Encrypt(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Organization key, encrypted with the public key
+	orgKeyData := make([]byte, 64)
+	rand.Read(orgKeyData)
+	encOrgKey, err := rsa.EncryptOAEP(sha1.New(), rand.Reader, &rsaKey.PublicKey, orgKeyData, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Return the profile
+	profile := schema.NewProfile()
+	profile.Email = "nobody@example.com"
+	profile.Key = encUserKey.String()
+	profile.PrivateKey = encPrivateKey.String()
+	profile.Organizations = []*schema.Organization{
+		{Id: "org", Key: "4." + base64.StdEncoding.EncodeToString(encOrgKey)},
+	}
+	return profile, userKey, crypto.NewKey(orgKeyData[:32], orgKeyData[32:])
+}
@@ -18,6 +18,25 @@ type ReaderWriter interface {
 }
 
 type Crypter interface {
+	// Encrypt the object and return a new object
+	Encrypt(*crypto.CryptoKey) (Crypter, error)
+
 	// Decrypt the object and return a new object
 	Decrypt(*crypto.CryptoKey) (Crypter, error)
 }
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// cryptStrings applies an encryption or decryption function to each
+// string value in place, stopping on the first error
+func cryptStrings(fn func(string) (string, error), values ...*string) error {
+	for _, value := range values {
+		if result, err := fn(*value); err != nil {
+			return err
+		} else {
+			*value = result
+		}
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package schema
+
+///////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// CipherSecureNote has no encrypted fields, the note is stored in the
+// cipher notes
+type CipherSecureNote struct {
+	Type SecureNoteType `json:"Type"`
+}
+
+type SecureNoteType uint
+
+///////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	SecureNoteTypeGeneric SecureNoteType = iota
+)
@@ -0,0 +1,163 @@
+package schema
+
+import (
+	"encoding/json"
+	"time"
+
+	// Packages
+	crypto "github.com/mutablelogic/go-client/pkg/bitwarden/crypto"
+
+	// Namespace imports
+	. "github.com/djthorpe/go-errors"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// Send shares text or a file with anyone who has the share URL. The name,
+// notes, text and file name are encrypted with a key derived from the key
+// material of the Send, which is itself encrypted with the user key.
+type Send struct {
+	Id             string     `json:"id,omitempty,width:36"`
+	AccessId       string     `json:"accessId,omitempty,width:22"`
+	Type           SendType   `json:"type,width:4"`
+	Name           string     `json:"name,width:30"`        // crypt
+	Notes          string     `json:"notes,omitempty,wrap"` // crypt
+	Key            string     `json:"key,omitempty"`        // Key material, encrypted with the user key
+	Text           *SendText  `json:"text,omitempty,wrap"`
+	File           *SendFile  `json:"file,omitempty,wrap"`
+	MaxAccessCount *int       `json:"maxAccessCount,omitempty,width:5"`
+	AccessCount    int        `json:"accessCount,omitempty,width:5"`
+	Password       string     `json:"password,omitempty"` // Hash of the access password
+	Disabled       bool       `json:"disabled,width:5"`
+	HideEmail      bool       `json:"hideEmail,width:5"`
+	RevisionDate   *time.Time `json:"revisionDate,omitempty,width:29"`
+	ExpirationDate *time.Time `json:"expirationDate,omitempty,width:29"`
+	DeletionDate   time.Time  `json:"deletionDate,width:29"`
+	Object         string     `json:"object,omitempty"`
+}
+
+type SendText struct {
+	Text   string `json:"text"` // crypt
+	Hidden bool   `json:"hidden"`
+}
+
+type SendFile struct {
+	Id       string      `json:"id,omitempty"`
+	FileName string      `json:"fileName"` // crypt
+	Size     json.Number `json:"size,omitempty"`
+	SizeName string      `json:"sizeName,omitempty"`
+}
+
+type SendType uint
+
+///////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	SendTypeText SendType = iota
+	SendTypeFile
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// STRINGIFY
+
+func (s Send) String() string {
+	data, _ := json.MarshalIndent(s, "", "  ")
+	return string(data)
+}
+
+func (t SendType) String() string {
+	switch t {
+	case SendTypeText:
+		return "text"
+	case SendTypeFile:
+		return "file"
+	default:
+		return "unknown"
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Encrypt a send with the key derived from its key material, and return a
+// copy of it. The key material is decrypted with the user key.
+func (s Send) Encrypt(k *crypto.CryptoKey) (Crypter, error) {
+	material, err := s.KeyMaterial(k)
+	if err != nil {
+		return nil, err
+	}
+	result := s.copy()
+	if err := result.crypt(crypto.SendKey(material).EncryptStr); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Decrypt a send with the key derived from its key material, and return a
+// copy of it. The key material is decrypted with the user key.
+func (s Send) Decrypt(k *crypto.CryptoKey) (Crypter, error) {
+	material, err := s.KeyMaterial(k)
+	if err != nil {
+		return nil, err
+	}
+	return s.Open(material)
+}
+
+// Open decrypts a send with key material from a share URL, and returns a copy
+// of it
+func (s Send) Open(material []byte) (*Send, error) {
+	result := s.copy()
+	if err := result.crypt(crypto.SendKey(material).DecryptStr); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// KeyMaterial decrypts and returns the key material of the send with the
+// user key
+func (s Send) KeyMaterial(k *crypto.CryptoKey) ([]byte, error) {
+	if s.Key == "" {
+		return nil, ErrBadParameter.With("send has no key")
+	} else if encrypted, err := crypto.NewEncrypted(s.Key); err != nil {
+		return nil, err
+	} else {
+		return k.Decrypt(encrypted)
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// Return a copy of the send which does not share any encrypted fields
+func (s Send) copy() *Send {
+	result := &s
+	if s.Text != nil {
+		text := *s.Text
+		result.Text = &text
+	}
+	if s.File != nil {
+		file := *s.File
+		result.File = &file
+	}
+	return result
+}
+
+// Encrypt or decrypt the encrypted fields of the send in place
+func (s *Send) crypt(fn func(string) (string, error)) error {
+	if err := cryptStrings(fn, &s.Name, &s.Notes); err != nil {
+		return err
+	}
+	if s.Text != nil {
+		if err := cryptStrings(fn, &s.Text.Text); err != nil {
+			return err
+		}
+	}
+	if s.File != nil {
+		if err := cryptStrings(fn, &s.File.FileName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package schema_test
+
+import (
+	"testing"
+
+	// Packages
+	"github.com/mutablelogic/go-client/pkg/bitwarden/crypto"
+	"github.com/mutablelogic/go-client/pkg/bitwarden/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_send_001(t *testing.T) {
+	assert := assert.New(t)
+	key, err := crypto.MakeInternalKey("nobody@example.com", "p4ssw0rd", 0, 5000, 0, 0)
+	assert.NoError(err)
+	k := crypto.NewKey(key, key)
+
+	// Make the key material, encrypted with the user key
+	material, err := crypto.MakeSendKey()
+	assert.NoError(err)
+	encryptedKey, err := k.Encrypt(material)
+	assert.NoError(err)
+	send := &schema.Send{
+		Type: schema.SendTypeText,
+		Name: "name",
+		Key:  encryptedKey.String(),
+		Text: &schema.SendText{Text: "secret"},
+	}
+
+	// Encrypt returns a copy and does not modify the original
+	encrypted, err := send.Encrypt(k)
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+	assert.NotEqual("name", encrypted.(*schema.Send).Name)
+	assert.NotEqual("secret", encrypted.(*schema.Send).Text.Text)
+	assert.Equal("secret", send.Text.Text)
+
+	// Decrypt with the user key
+	decrypted, err := encrypted.Decrypt(k)
+	if assert.NoError(err) {
+		assert.Equal(send, decrypted)
+	}
+
+	// Open with the key material
+	opened, err := encrypted.(*schema.Send).Open(material)
+	if assert.NoError(err) {
+		assert.Equal(send, opened)
+	}
+
+	// A send without a key cannot be encrypted
+	_, err = schema.Send{Name: "name"}.Encrypt(k)
+	assert.Error(err)
+}
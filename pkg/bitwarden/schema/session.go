@@ -16,6 +16,9 @@ type Session struct {
 	// Login Token
 	Token *Token `json:"token,omitempty,wrap"`
 
+	// Token to skip two-factor authentication on the next password login
+	TwoFactorToken string `json:"twoFactorToken,omitempty"`
+
 	// Encryption parameters
 	Kdf
 }
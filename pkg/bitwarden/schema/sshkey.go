@@ -0,0 +1,18 @@
+package schema
+
+///////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+type CipherSshKey struct {
+	PrivateKey  string `json:"PrivateKey,omitempty"`     // crypt
+	PublicKey   string `json:"PublicKey,omitempty"`      // crypt
+	Fingerprint string `json:"KeyFingerprint,omitempty"` // crypt
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// Encrypt or decrypt the encrypted fields of the SSH key in place
+func (k *CipherSshKey) crypt(fn func(string) (string, error)) error {
+	return cryptStrings(fn, &k.PrivateKey, &k.PublicKey, &k.Fingerprint)
+}
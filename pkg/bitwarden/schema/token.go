@@ -8,10 +8,11 @@ import (
 // TYPES
 
 type Token struct {
-	TokenType   string    `json:"token_type"`
-	AccessToken string    `json:"access_token"`
-	ExpiresIn   int       `json:"expires_in"`
-	CreatedAt   time.Time `json:"created_at,omitempty"`
+	TokenType    string    `json:"token_type"`
+	AccessToken  string    `json:"access_token"`
+	ExpiresIn    int       `json:"expires_in"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	CreatedAt    time.Time `json:"created_at,omitempty"`
 }
 
 ///////////////////////////////////////////////////////////////////////////////
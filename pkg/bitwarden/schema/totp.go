@@ -0,0 +1,243 @@
+package schema
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/binary"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	// Namespace imports
+	. "github.com/djthorpe/go-errors"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// TOTP contains the parameters for generating time-based one-time passwords,
+// parsed from the TOTP value of a login
+type TOTP struct {
+	Secret    []byte        `json:"-"`
+	Algorithm string        `json:"algorithm,width:6"`
+	Digits    int           `json:"digits,width:6"`
+	Period    time.Duration `json:"period,width:6"`
+	Steam     bool          `json:"steam,omitempty,width:5"`
+	Issuer    string        `json:"issuer,omitempty,width:20"`
+	Account   string        `json:"account,omitempty,width:30"`
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// GLOBALS
+
+const (
+	TOTPAlgorithmSHA1   = "SHA1"
+	TOTPAlgorithmSHA256 = "SHA256"
+	TOTPAlgorithmSHA512 = "SHA512"
+)
+
+const (
+	totpDefaultDigits = 6
+	totpDefaultPeriod = 30 * time.Second
+	totpMaxDigits     = 10
+	totpSteamDigits   = 5
+	totpSteamChars    = "23456789BCDFGHJKMNPQRTVWXY"
+	totpSchemeOTPAuth = "otpauth"
+	totpSchemeSteam   = "steam"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// LIFECYCLE
+
+// ParseTOTP parses the TOTP value of a login, which is either a base32
+// encoded secret, an otpauth:// URI or a steam:// URI
+func ParseTOTP(value string) (*TOTP, error) {
+	totp := &TOTP{
+		Algorithm: TOTPAlgorithmSHA1,
+		Digits:    totpDefaultDigits,
+		Period:    totpDefaultPeriod,
+	}
+
+	// Parse the value
+	secret := strings.TrimSpace(value)
+	if scheme, rest, ok := strings.Cut(secret, "://"); ok {
+		switch strings.ToLower(scheme) {
+		case totpSchemeSteam:
+			totp.Steam = true
+			totp.Digits = totpSteamDigits
+			secret = rest
+		case totpSchemeOTPAuth:
+			if v, err := totp.parseURI(secret); err != nil {
+				return nil, err
+			} else {
+				secret = v
+			}
+		default:
+			return nil, ErrBadParameter.Withf("unsupported TOTP scheme %q", scheme)
+		}
+	}
+
+	// Decode the secret
+	if key, err := decodeTOTPSecret(secret); err != nil {
+		return nil, err
+	} else {
+		totp.Secret = key
+	}
+
+	// Return success
+	return totp, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Code returns the one-time password at a time, and the duration for which
+// the code remains valid
+func (t *TOTP) Code(now time.Time) (string, time.Duration, error) {
+	hash, err := t.hash()
+	if err != nil {
+		return "", 0, err
+	} else if t.Period < time.Second {
+		return "", 0, ErrBadParameter.Withf("invalid TOTP period %v", t.Period)
+	} else if t.Digits <= 0 || t.Digits > totpMaxDigits {
+		return "", 0, ErrBadParameter.Withf("invalid TOTP digits %v", t.Digits)
+	}
+
+	// Compute the counter and remaining validity
+	period := int64(t.Period / time.Second)
+	seconds := now.Unix()
+	counter := seconds / period
+	remaining := time.Duration(period-(seconds-counter*period)) * time.Second
+
+	// Compute the HMAC of the counter, and use dynamic truncation (RFC 4226)
+	// to extract a 31-bit value
+	mac := hmac.New(hash, t.Secret)
+	binary.Write(mac, binary.BigEndian, uint64(counter))
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0F
+	value := uint64(binary.BigEndian.Uint32(sum[offset:]) & 0x7FFFFFFF)
+
+	// Steam codes use an alphabet of characters rather than digits
+	var code []byte
+	if t.Steam {
+		code = make([]byte, t.Digits)
+		for i := range code {
+			code[i] = totpSteamChars[value%uint64(len(totpSteamChars))]
+			value /= uint64(len(totpSteamChars))
+		}
+	} else {
+		code = []byte(strconv.FormatUint(value%pow10(t.Digits), 10))
+		for len(code) < t.Digits {
+			code = append([]byte{'0'}, code...)
+		}
+	}
+
+	// Return the code
+	return string(code), remaining, nil
+}
+
+// TOTPCode returns the current one-time password for the login and the
+// duration for which it remains valid. The login must have been decrypted
+// and have a TOTP value.
+func (l *CipherLogin) TOTPCode(now time.Time) (string, time.Duration, error) {
+	if l == nil || l.TOTP == "" {
+		return "", 0, ErrNotFound.With("login has no TOTP value")
+	}
+	totp, err := ParseTOTP(l.TOTP)
+	if err != nil {
+		return "", 0, err
+	}
+	return totp.Code(now)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// Parse the parameters from an otpauth:// URI and return the secret
+func (t *TOTP) parseURI(value string) (string, error) {
+	uri, err := url.Parse(value)
+	if err != nil {
+		return "", ErrBadParameter.Withf("invalid TOTP URI: %v", err)
+	} else if uri.Host != "" && !strings.EqualFold(uri.Host, "totp") {
+		return "", ErrNotImplemented.Withf("unsupported OTP type %q", uri.Host)
+	}
+
+	// Label is "issuer:account" or "account"
+	label := strings.TrimPrefix(uri.Path, "/")
+	if issuer, account, ok := strings.Cut(label, ":"); ok {
+		t.Issuer, t.Account = strings.TrimSpace(issuer), strings.TrimSpace(account)
+	} else {
+		t.Account = label
+	}
+
+	// Parameters
+	query := uri.Query()
+	if v := query.Get("issuer"); v != "" {
+		t.Issuer = v
+	}
+	if v := query.Get("algorithm"); v != "" {
+		t.Algorithm = strings.ToUpper(v)
+	}
+	if v := query.Get("digits"); v != "" {
+		if n, err := strconv.Atoi(v); err != nil || n <= 0 || n > totpMaxDigits {
+			return "", ErrBadParameter.Withf("invalid TOTP digits %q", v)
+		} else {
+			t.Digits = n
+		}
+	}
+	if v := query.Get("period"); v != "" {
+		if n, err := strconv.Atoi(v); err != nil || n <= 0 {
+			return "", ErrBadParameter.Withf("invalid TOTP period %q", v)
+		} else {
+			t.Period = time.Duration(n) * time.Second
+		}
+	}
+	if strings.EqualFold(query.Get("encoder"), totpSchemeSteam) {
+		t.Steam = true
+		t.Digits = totpSteamDigits
+	}
+
+	// Return the secret
+	return query.Get("secret"), nil
+}
+
+// Return the hash function for the algorithm
+func (t *TOTP) hash() (func() hash.Hash, error) {
+	switch strings.ToUpper(t.Algorithm) {
+	case TOTPAlgorithmSHA1, "":
+		return sha1.New, nil
+	case TOTPAlgorithmSHA256:
+		return sha256.New, nil
+	case TOTPAlgorithmSHA512:
+		return sha512.New, nil
+	default:
+		return nil, ErrNotImplemented.Withf("unsupported TOTP algorithm %q", t.Algorithm)
+	}
+}
+
+// Decode a base32 secret, ignoring spaces, hyphens, case and padding
+func decodeTOTPSecret(value string) ([]byte, error) {
+	value = strings.ToUpper(strings.NewReplacer(" ", "", "-", "", "=", "").Replace(value))
+	if value == "" {
+		return nil, ErrBadParameter.With("missing TOTP secret")
+	}
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(value)
+	if err != nil {
+		return nil, ErrBadParameter.Withf("invalid TOTP secret: %v", err)
+	}
+	return key, nil
+}
+
+// Return 10 to the power of n
+func pow10(n int) uint64 {
+	result := uint64(1)
+	for i := 0; i < n; i++ {
+		result *= 10
+	}
+	return result
+}
@@ -0,0 +1,103 @@
+package schema_test
+
+import (
+	"testing"
+	"time"
+
+	// Packages
+	"github.com/mutablelogic/go-client/pkg/bitwarden/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	totpSecretSHA1   = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	totpSecretSHA256 = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZA"
+	totpSecretSHA512 = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNA"
+)
+
+func Test_totp_001(t *testing.T) {
+	assert := assert.New(t)
+
+	// Test vectors from RFC 6238
+	tests := []struct {
+		value string
+		time  int64
+		code  string
+	}{
+		{"otpauth://totp/test?secret=" + totpSecretSHA1 + "&digits=8", 59, "94287082"},
+		{"otpauth://totp/test?secret=" + totpSecretSHA256 + "&digits=8&algorithm=SHA256", 59, "46119246"},
+		{"otpauth://totp/test?secret=" + totpSecretSHA512 + "&digits=8&algorithm=sha512", 59, "90693936"},
+		{"otpauth://totp/test?secret=" + totpSecretSHA1 + "&digits=8", 1111111109, "07081804"},
+		{"otpauth://totp/test?secret=" + totpSecretSHA256 + "&digits=8&algorithm=SHA256", 1234567890, "91819424"},
+		{"otpauth://totp/test?secret=" + totpSecretSHA512 + "&digits=8&algorithm=SHA512", 20000000000, "47863826"},
+	}
+	for _, test := range tests {
+		totp, err := schema.ParseTOTP(test.value)
+		if !assert.NoError(err) {
+			continue
+		}
+		code, _, err := totp.Code(time.Unix(test.time, 0))
+		assert.NoError(err)
+		assert.Equal(test.code, code)
+	}
+}
+
+func Test_totp_002(t *testing.T) {
+	assert := assert.New(t)
+
+	// Plain secrets are six digits with a 30 second period, and may contain
+	// spaces and lowercase characters
+	login := &schema.CipherLogin{TOTP: "gezd gnbv gy3t qojq gezd gnbv gy3t qojq"}
+	code, remaining, err := login.TOTPCode(time.Unix(59, 0))
+	assert.NoError(err)
+	assert.Equal("287082", code)
+	assert.Equal(time.Second, remaining)
+
+	// Custom period
+	totp, err := schema.ParseTOTP("otpauth://totp/Example:alice@example.com?secret=" + totpSecretSHA1 + "&period=60&issuer=Example")
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+	assert.Equal("Example", totp.Issuer)
+	assert.Equal("alice@example.com", totp.Account)
+	assert.Equal(time.Minute, totp.Period)
+	_, remaining, err = totp.Code(time.Unix(100, 0))
+	assert.NoError(err)
+	assert.Equal(20*time.Second, remaining)
+
+	// Steam codes
+	login = &schema.CipherLogin{TOTP: "steam://" + totpSecretSHA1}
+	code, _, err = login.TOTPCode(time.Unix(59, 0))
+	assert.NoError(err)
+	assert.Equal("PV9M4", code)
+	totp, err = schema.ParseTOTP("otpauth://totp/Steam:alice?secret=" + totpSecretSHA1 + "&encoder=steam")
+	assert.NoError(err)
+	code, _, err = totp.Code(time.Unix(59, 0))
+	assert.NoError(err)
+	assert.Equal("PV9M4", code)
+}
+
+func Test_totp_003(t *testing.T) {
+	assert := assert.New(t)
+
+	// Invalid values
+	_, _, err := (&schema.CipherLogin{}).TOTPCode(time.Now())
+	assert.Error(err)
+	for _, value := range []string{
+		"not base32!",
+		"otpauth://hotp/test?secret=" + totpSecretSHA1,
+		"otpauth://totp/test?secret=" + totpSecretSHA1 + "&digits=11",
+		"otpauth://totp/test?secret=" + totpSecretSHA1 + "&period=0",
+		"otpauth://totp/test",
+		"https://example.com/",
+	} {
+		_, err := schema.ParseTOTP(value)
+		assert.Error(err, value)
+	}
+
+	// Unsupported algorithm
+	totp, err := schema.ParseTOTP("otpauth://totp/test?secret=" + totpSecretSHA1 + "&algorithm=MD5")
+	assert.NoError(err)
+	_, _, err = totp.Code(time.Now())
+	assert.Error(err)
+}
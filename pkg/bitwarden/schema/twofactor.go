@@ -0,0 +1,85 @@
+package schema
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// TwoFactorProvider is a method of two-factor authentication
+type TwoFactorProvider int
+
+// TwoFactor is returned by the server when a login requires two-factor
+// authentication, with the providers enabled for the account and any
+// parameters for each provider (such as the obfuscated email address)
+type TwoFactor struct {
+	Providers map[string]map[string]any `json:"TwoFactorProviders2"`
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// GLOBALS
+
+const (
+	TwoFactorProviderAuthenticator TwoFactorProvider = iota
+	TwoFactorProviderEmail
+	TwoFactorProviderDuo
+	TwoFactorProviderYubiKey
+	TwoFactorProviderU2f
+	TwoFactorProviderRemember
+	TwoFactorProviderOrganizationDuo
+	TwoFactorProviderWebAuthn
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// STRINGIFY
+
+func (p TwoFactorProvider) String() string {
+	switch p {
+	case TwoFactorProviderAuthenticator:
+		return "Authenticator"
+	case TwoFactorProviderEmail:
+		return "Email"
+	case TwoFactorProviderDuo:
+		return "Duo"
+	case TwoFactorProviderYubiKey:
+		return "YubiKey"
+	case TwoFactorProviderU2f:
+		return "U2F"
+	case TwoFactorProviderRemember:
+		return "Remember"
+	case TwoFactorProviderOrganizationDuo:
+		return "OrganizationDuo"
+	case TwoFactorProviderWebAuthn:
+		return "WebAuthn"
+	default:
+		return fmt.Sprint(int(p))
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Return the enabled providers, in order
+func (t TwoFactor) Enabled() []TwoFactorProvider {
+	result := make([]TwoFactorProvider, 0, len(t.Providers))
+	for key := range t.Providers {
+		if provider, err := strconv.Atoi(key); err == nil {
+			result = append(result, TwoFactorProvider(provider))
+		}
+	}
+	slices.Sort(result)
+	return result
+}
+
+// Return a parameter for a provider, or empty string if the parameter
+// does not exist. For example, the "Email" parameter for the email provider
+// is the obfuscated email address the code is sent to.
+func (t TwoFactor) Param(provider TwoFactorProvider, key string) string {
+	if value, ok := t.Providers[fmt.Sprint(int(provider))][key].(string); ok {
+		return value
+	}
+	return ""
+}
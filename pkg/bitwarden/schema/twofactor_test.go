@@ -0,0 +1,27 @@
+package schema_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	// Packages
+	schema "github.com/mutablelogic/go-client/pkg/bitwarden/schema"
+	assert "github.com/stretchr/testify/assert"
+)
+
+func Test_twofactor_001(t *testing.T) {
+	assert := assert.New(t)
+
+	// Challenge returned by the identity server
+	var twofactor schema.TwoFactor
+	assert.NoError(json.Unmarshal([]byte(`{
+		"error": "invalid_grant",
+		"error_description": "Two factor required.",
+		"TwoFactorProviders": ["1", "0"],
+		"TwoFactorProviders2": { "1": { "Email": "t***@example.com" }, "0": null }
+	}`), &twofactor))
+	assert.Equal([]schema.TwoFactorProvider{schema.TwoFactorProviderAuthenticator, schema.TwoFactorProviderEmail}, twofactor.Enabled())
+	assert.Equal("t***@example.com", twofactor.Param(schema.TwoFactorProviderEmail, "Email"))
+	assert.Equal("", twofactor.Param(schema.TwoFactorProviderAuthenticator, "Email"))
+	assert.Equal("Authenticator", schema.TwoFactorProviderAuthenticator.String())
+}
@@ -0,0 +1,406 @@
+package bitwarden
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	// Packages
+	client "github.com/mutablelogic/go-client"
+	crypto "github.com/mutablelogic/go-client/pkg/bitwarden/crypto"
+	schema "github.com/mutablelogic/go-client/pkg/bitwarden/schema"
+	multipart "github.com/mutablelogic/go-client/pkg/multipart"
+	types "github.com/mutablelogic/go-server/pkg/types"
+
+	// Namespace imports
+	. "github.com/djthorpe/go-errors"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+type reqSend struct {
+	*schema.Send
+	FileLength int64 `json:"fileLength,omitempty"`
+}
+
+type respSends struct {
+	Data   []*schema.Send `json:"data"`
+	Object string         `json:"object"`
+}
+
+type respSendFileUpload struct {
+	FileUploadType int          `json:"fileUploadType"`
+	Url            string       `json:"url"`
+	Send           *schema.Send `json:"sendResponse"`
+}
+
+type reqSendUpload struct {
+	Data multipart.File `json:"data"`
+}
+
+type reqSendAccess struct {
+	Password string `json:"password,omitempty"`
+}
+
+type respSendFileAccess struct {
+	Id  string `json:"id"`
+	Url string `json:"url"`
+}
+
+// sendData reads the body of a response
+type sendData struct {
+	bytes.Buffer
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// GLOBALS
+
+const (
+	// Share URL for sends, followed by the access ID and key
+	sendUrl = "https://send.bitwarden.com/#"
+
+	// Default and maximum time before a send is deleted
+	defaultSendDeletion = 7 * 24 * time.Hour
+	maxSendDeletion     = 31 * 24 * time.Hour
+)
+
+const (
+	sendUploadDirect = iota
+	sendUploadAzure
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Sends returns the decrypted sends for the user. Requires the OptPassword
+// option to decrypt the sends.
+func (c *Client) Sends(opts ...RequestOpt) ([]*schema.Send, error) {
+	var response respSends
+	reqOpt, err := c.requestOpts(opts...)
+	if err != nil {
+		return nil, err
+	}
+	key, err := c.cryptKey(reqOpt, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// Request -> Response
+	if err := c.client.Do(nil, &response, client.OptPath("sends"), c.token()); err != nil {
+		return nil, err
+	}
+
+	// Decrypt the sends
+	result := make([]*schema.Send, 0, len(response.Data))
+	for _, send := range response.Data {
+		if decrypted, err := send.Decrypt(key); err != nil {
+			return nil, err
+		} else {
+			result = append(result, decrypted.(*schema.Send))
+		}
+	}
+	return result, nil
+}
+
+// Send returns a decrypted send. Requires the OptPassword option to decrypt
+// the send.
+func (c *Client) Send(id string, opts ...RequestOpt) (*schema.Send, error) {
+	var response schema.Send
+	if id == "" {
+		return nil, ErrBadParameter.With("Send")
+	}
+	reqOpt, err := c.requestOpts(opts...)
+	if err != nil {
+		return nil, err
+	}
+	key, err := c.cryptKey(reqOpt, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// Request -> Response
+	if err := c.client.Do(nil, &response, client.OptPath("sends", id), c.token()); err != nil {
+		return nil, err
+	}
+	if decrypted, err := response.Decrypt(key); err != nil {
+		return nil, err
+	} else {
+		return decrypted.(*schema.Send), nil
+	}
+}
+
+// CreateSend creates a text send, or a file send where the file contents
+// are read from r. The key material for the send is generated and the send
+// and file are encrypted. The deletion date defaults to seven days from now.
+// Requires the OptPassword option to encrypt the send, and use the
+// OptAccessPassword option to protect access to the send with a password.
+func (c *Client) CreateSend(send *schema.Send, r io.Reader, opts ...RequestOpt) (*schema.Send, error) {
+	if send == nil {
+		return nil, ErrBadParameter.With("CreateSend")
+	} else if send.Id != "" {
+		return nil, ErrBadParameter.Withf("CreateSend: send already has an id %q", send.Id)
+	}
+	switch send.Type {
+	case schema.SendTypeText:
+		if send.Text == nil {
+			return nil, ErrBadParameter.With("CreateSend: missing text")
+		}
+	case schema.SendTypeFile:
+		if send.File == nil || send.File.FileName == "" || r == nil {
+			return nil, ErrBadParameter.With("CreateSend: missing file")
+		}
+	default:
+		return nil, ErrBadParameter.Withf("CreateSend: invalid type %v", send.Type)
+	}
+
+	// Check the deletion date
+	now := time.Now()
+	if send.DeletionDate.IsZero() {
+		send.DeletionDate = now.Add(defaultSendDeletion)
+	} else if send.DeletionDate.Before(now) || send.DeletionDate.After(now.Add(maxSendDeletion)) {
+		return nil, ErrBadParameter.Withf("CreateSend: deletion date must be within %v", maxSendDeletion)
+	} else if send.ExpirationDate != nil && send.ExpirationDate.After(send.DeletionDate) {
+		return nil, ErrBadParameter.With("CreateSend: expiration date is after the deletion date")
+	}
+
+	// Apply options
+	reqOpt, err := c.requestOpts(opts...)
+	if err != nil {
+		return nil, err
+	}
+	key, err := c.cryptKey(reqOpt, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// Generate the key material and encrypt the send
+	material, err := crypto.MakeSendKey()
+	if err != nil {
+		return nil, err
+	}
+	template := *send
+	if encrypted, err := key.Encrypt(material); err != nil {
+		return nil, err
+	} else {
+		template.Key = encrypted.String()
+	}
+	if reqOpt.accessPasswd != "" {
+		template.Password = crypto.HashedSendPassword(reqOpt.accessPasswd, material)
+	}
+	encrypted, err := template.Encrypt(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the send
+	var response *schema.Send
+	if send.Type == schema.SendTypeFile {
+		response, err = c.createFileSend(encrypted.(*schema.Send), crypto.SendKey(material), r)
+	} else {
+		response, err = c.createTextSend(encrypted.(*schema.Send))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Return the decrypted send
+	if decrypted, err := response.Decrypt(key); err != nil {
+		return nil, err
+	} else {
+		return decrypted.(*schema.Send), nil
+	}
+}
+
+// DeleteSend permanently deletes a send
+func (c *Client) DeleteSend(id string, opts ...RequestOpt) error {
+	if id == "" {
+		return ErrBadParameter.With("DeleteSend")
+	} else if _, err := c.requestOpts(opts...); err != nil {
+		return err
+	}
+	return c.client.Do(client.NewRequestEx(http.MethodDelete, types.ContentTypeAny), nil, client.OptPath("sends", id), c.token())
+}
+
+// SendURL returns the share URL for a send, which contains the key material
+// to decrypt the send. Requires the OptPassword option to decrypt the key
+// material.
+func (c *Client) SendURL(send *schema.Send, opts ...RequestOpt) (string, error) {
+	if send == nil || send.AccessId == "" {
+		return "", ErrBadParameter.With("SendURL")
+	}
+	reqOpt, err := c.requestOpts(opts...)
+	if err != nil {
+		return "", err
+	}
+	key, err := c.cryptKey(reqOpt, "")
+	if err != nil {
+		return "", err
+	}
+	material, err := send.KeyMaterial(key)
+	if err != nil {
+		return "", err
+	}
+	return sendUrl + send.AccessId + "/" + base64.RawURLEncoding.EncodeToString(material), nil
+}
+
+// ReceiveSend accesses a send from its share URL and returns the decrypted
+// send. For a file send, the decrypted file is written to w. Use the
+// OptAccessPassword option when the send is protected with a password. A
+// session is not required to receive a send.
+func (c *Client) ReceiveSend(shareUrl string, w io.Writer, opts ...RequestOpt) (*schema.Send, error) {
+	var reqOpt opt
+	for _, opt := range opts {
+		if err := opt(&reqOpt); err != nil {
+			return nil, err
+		}
+	}
+
+	// Parse the share URL
+	accessId, material, err := parseSendURL(shareUrl)
+	if err != nil {
+		return nil, err
+	}
+	var request reqSendAccess
+	if reqOpt.accessPasswd != "" {
+		request.Password = crypto.HashedSendPassword(reqOpt.accessPasswd, material)
+	}
+
+	// Access the send
+	var response schema.Send
+	if payload, err := client.NewJSONRequest(request); err != nil {
+		return nil, err
+	} else if err := c.client.Do(payload, &response, client.OptPath("sends", "access", accessId)); err != nil {
+		return nil, err
+	}
+	send, err := response.Open(material)
+	if err != nil {
+		return nil, err
+	}
+
+	// Download and decrypt the file
+	if send.Type == schema.SendTypeFile && send.File != nil && w != nil {
+		var file respSendFileAccess
+		var data sendData
+		if payload, err := client.NewJSONRequest(request); err != nil {
+			return nil, err
+		} else if err := c.client.Do(payload, &file, client.OptPath("sends", send.Id, "access", "file", send.File.Id)); err != nil {
+			return nil, err
+		} else if err := c.client.Do(nil, &data, client.OptReqEndpoint(file.Url)); err != nil {
+			return nil, err
+		}
+		if encrypted, err := crypto.NewEncryptedBytes(data.Bytes()); err != nil {
+			return nil, err
+		} else if plaintext, err := crypto.SendKey(material).Decrypt(encrypted); err != nil {
+			return nil, err
+		} else if _, err := w.Write(plaintext); err != nil {
+			return nil, err
+		}
+	}
+
+	// Return the send
+	return send, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// Create an encrypted text send
+func (c *Client) createTextSend(send *schema.Send) (*schema.Send, error) {
+	var response schema.Send
+	if payload, err := client.NewJSONRequestEx(http.MethodPost, reqSend{Send: send}, types.ContentTypeJSON); err != nil {
+		return nil, err
+	} else if err := c.client.Do(payload, &response, client.OptPath("sends"), c.token()); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// Encrypt the file, create an encrypted file send and upload the file
+func (c *Client) createFileSend(send *schema.Send, key *crypto.CryptoKey, r io.Reader) (*schema.Send, error) {
+	var response respSendFileUpload
+
+	// Encrypt the file
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := key.Encrypt(plaintext)
+	if err != nil {
+		return nil, err
+	}
+	data, err := encrypted.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the send
+	if payload, err := client.NewJSONRequestEx(http.MethodPost, reqSend{Send: send, FileLength: int64(len(data))}, types.ContentTypeJSON); err != nil {
+		return nil, err
+	} else if err := c.client.Do(payload, &response, client.OptPath("sends", "file", "v2"), c.token()); err != nil {
+		return nil, err
+	} else if response.Send == nil || response.Send.File == nil {
+		return nil, ErrUnexpectedResponse.With("missing send")
+	}
+
+	// Upload the file
+	switch response.FileUploadType {
+	case sendUploadDirect:
+		if payload, err := client.NewMultipartRequest(reqSendUpload{
+			Data: multipart.File{
+				Path:        response.Send.File.Id,
+				Body:        io.NopCloser(bytes.NewReader(data)),
+				ContentType: types.ContentTypeBinary,
+			},
+		}, types.ContentTypeAny); err != nil {
+			return nil, err
+		} else if err := c.client.Do(payload, nil, client.OptPath("sends", response.Send.Id, "file", response.Send.File.Id), c.token()); err != nil {
+			return nil, err
+		}
+	case sendUploadAzure:
+		req, err := http.NewRequest(http.MethodPut, response.Url, bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("x-ms-blob-type", "BlockBlob")
+		req.Header.Set("x-ms-version", "2020-04-08")
+		req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+		if err := c.client.Request(req, nil); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrNotImplemented.Withf("file upload type %v", response.FileUploadType)
+	}
+
+	// Return the send
+	return response.Send, nil
+}
+
+// Return the access ID and key material from a share URL, which has the
+// access ID and key in the fragment
+func parseSendURL(value string) (string, []byte, error) {
+	u, err := url.Parse(value)
+	if err != nil {
+		return "", nil, ErrBadParameter.Withf("invalid send URL: %v", err)
+	}
+	fragment := strings.TrimPrefix(strings.TrimPrefix(u.Fragment, "/"), "send/")
+	accessId, key, ok := strings.Cut(fragment, "/")
+	if !ok || accessId == "" || key == "" {
+		return "", nil, ErrBadParameter.Withf("invalid send URL: %q", value)
+	}
+	material, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(key, "="))
+	if err != nil {
+		return "", nil, ErrBadParameter.Withf("invalid send URL key: %v", err)
+	}
+	return accessId, material, nil
+}
+
+// Unmarshal the response body
+func (d *sendData) Unmarshal(_ http.Header, r io.Reader) error {
+	_, err := d.ReadFrom(r)
+	return err
+}
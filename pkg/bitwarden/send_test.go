@@ -0,0 +1,72 @@
+package bitwarden_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	// Packages
+	opts "github.com/mutablelogic/go-client"
+	bitwarden "github.com/mutablelogic/go-client/pkg/bitwarden"
+	schema "github.com/mutablelogic/go-client/pkg/bitwarden/schema"
+	assert "github.com/stretchr/testify/assert"
+)
+
+func Test_send_001(t *testing.T) {
+	assert := assert.New(t)
+	client, err := bitwarden.New(opts.OptTrace(os.Stderr, true), bitwarden.OptFileStorage(t.TempDir()), bitwarden.OptCredentials(GetCredentials(t)), bitwarden.OptDevice(schema.Device{
+		Name:       "mydevice",
+		Identifier: GetIdentifier(t),
+	}))
+	assert.NoError(err)
+	password := GetPassword(t)
+
+	// Login
+	if err := client.Login(); !assert.NoError(err) {
+		t.SkipNow()
+	}
+
+	// Create a text send protected with a password
+	send, err := client.CreateSend(&schema.Send{
+		Type: schema.SendTypeText,
+		Name: t.Name(),
+		Text: &schema.SendText{Text: "secret"},
+	}, nil, bitwarden.OptPassword(password), bitwarden.OptAccessPassword("access"))
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+	defer client.DeleteSend(send.Id)
+	assert.Equal(t.Name(), send.Name)
+
+	// Receive the send from the share URL
+	url, err := client.SendURL(send, bitwarden.OptPassword(password))
+	assert.NoError(err)
+	received, err := client.ReceiveSend(url, nil, bitwarden.OptAccessPassword("access"))
+	if assert.NoError(err) {
+		assert.Equal("secret", received.Text.Text)
+	}
+
+	// Create and receive a file send
+	file, err := client.CreateSend(&schema.Send{
+		Type: schema.SendTypeFile,
+		Name: t.Name(),
+		File: &schema.SendFile{FileName: "hello.txt"},
+	}, bytes.NewReader([]byte("hello, world")), bitwarden.OptPassword(password))
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+	defer client.DeleteSend(file.Id)
+	url, err = client.SendURL(file, bitwarden.OptPassword(password))
+	assert.NoError(err)
+	var buf bytes.Buffer
+	received, err = client.ReceiveSend(url, &buf)
+	if assert.NoError(err) {
+		assert.Equal("hello.txt", received.File.FileName)
+		assert.Equal("hello, world", buf.String())
+	}
+
+	// List the sends
+	sends, err := client.Sends(bitwarden.OptPassword(password))
+	assert.NoError(err)
+	t.Logf("%d sends", len(sends))
+}
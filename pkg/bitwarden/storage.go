@@ -4,6 +4,8 @@ bitwarden implements an API client for bitwarden
 package bitwarden
 
 import (
+	"time"
+
 	// Packages
 	schema "github.com/mutablelogic/go-client/pkg/bitwarden/schema"
 )
@@ -32,9 +34,22 @@ type Storage interface {
 	// Write the ciphers to storage
 	WriteCiphers(schema.Ciphers) error
 
+	// Write the collections to storage
+	WriteCollections(schema.Collections) error
+
 	// Read all ciphers and return an iterator
 	ReadCiphers() (schema.Iterator[*schema.Cipher], error)
 
 	// Read all folders and return an iterator
 	ReadFolders() (schema.Iterator[*schema.Folder], error)
+
+	// Read all collections and return an iterator
+	ReadCollections() (schema.Iterator[*schema.Collection], error)
+
+	// Return the account revision date of the last sync, or zero time if
+	// the vault has not been synced
+	LastSync() (time.Time, error)
+
+	// Write the account revision date of the last sync
+	WriteLastSync(time.Time) error
 }
@@ -1,10 +1,11 @@
 package bitwarden
 
 import (
-	// Packages
-
 	"errors"
+	"net/url"
+	"time"
 
+	// Packages
 	client "github.com/mutablelogic/go-client"
 	schema "github.com/mutablelogic/go-client/pkg/bitwarden/schema"
 
@@ -17,22 +18,28 @@ import (
 
 type respSync struct {
 	*schema.Profile `json:"Profile,omitempty"`
-	Folders         schema.Folders `json:"Folders,omitempty"`
-	Ciphers         schema.Ciphers `json:"Ciphers,omitempty"`
-	Object          string         `json:"Object"`
+	Folders         schema.Folders     `json:"Folders,omitempty"`
+	Ciphers         schema.Ciphers     `json:"Ciphers,omitempty"`
+	Collections     schema.Collections `json:"Collections,omitempty"`
+	Object          string             `json:"Object"`
 }
 
 ///////////////////////////////////////////////////////////////////////////////
 // PUBLIC METHODS
 
-// Sync folders, cipers and folders with storage, and return the profile
+// Sync folders, ciphers and collections with storage, and return the
+// profile. When the vault is in storage and the account revision date has not
+// changed since the last sync, the stored profile is returned. Use OptForce to
+// always download the whole vault. The ciphers and folders in storage are
+// rewritten only when an identifier or revision date differs from the
+// downloaded vault.
 func (c *Client) Sync(opts ...RequestOpt) (*schema.Profile, error) {
 	var response respSync
 	var reqOpt opt
 
 	// Check session
-	if !c.session.IsValid() {
-		return nil, ErrNotAuthorized.With("session token has expired")
+	if err := c.renew(); err != nil {
+		return nil, err
 	}
 
 	// Apply options
@@ -56,8 +63,8 @@ func (c *Client) Folders(opts ...RequestOpt) (schema.Iterator[*schema.Folder], e
 	var reqOpt opt
 
 	// Check session
-	if !c.session.IsValid() {
-		return nil, ErrNotAuthorized.With("session token has expired")
+	if err := c.renew(); err != nil {
+		return nil, err
 	}
 
 	// Apply options
@@ -111,8 +118,8 @@ func (c *Client) Ciphers(opts ...RequestOpt) (schema.Iterator[*schema.Cipher], e
 	var reqOpt opt
 
 	// Check session
-	if !c.session.IsValid() {
-		return nil, ErrNotAuthorized.With("session token has expired")
+	if err := c.renew(); err != nil {
+		return nil, err
 	}
 
 	// Apply options
@@ -160,44 +167,154 @@ func (c *Client) Ciphers(opts ...RequestOpt) (schema.Iterator[*schema.Cipher], e
 	return iterator, nil
 }
 
+// Return collection iterator for the organizations the user is a member of
+func (c *Client) Collections(opts ...RequestOpt) (schema.Iterator[*schema.Collection], error) {
+	var response respSync
+
+	// Check session and apply options
+	reqOpt, err := c.requestOpts(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// If there is no storage, then always sync
+	if c.storage == nil {
+		reqOpt.force = true
+	}
+
+	// Sync and store
+	if err := c.sync(&response, reqOpt); err != nil {
+		return nil, err
+	} else if response.Profile == nil {
+		return nil, ErrInternalAppError.With("missing profile")
+	}
+
+	// The iterator comes from sync or storage
+	var iterator schema.Iterator[*schema.Collection]
+	if response.Collections != nil {
+		iterator = schema.NewIterator[*schema.Collection](response.Collections)
+	} else if c.storage != nil {
+		if v, err := c.storage.ReadCollections(); err != nil {
+			return nil, err
+		} else {
+			iterator = v
+		}
+	}
+	if iterator == nil {
+		return nil, ErrInternalAppError.With("missing collections")
+	}
+
+	// Cache the encryption keys for the collections
+	if reqOpt.passwd != "" {
+		if _, err := iterator.CryptKey(response.Profile, reqOpt.passwd, c.session.Kdf); err != nil {
+			return nil, err
+		}
+	}
+
+	// Return the iterator
+	return iterator, nil
+}
+
 ///////////////////////////////////////////////////////////////////////////////
 // PRIVATE METHODS
 
 // Perform the sync operation if necessary
 func (c *Client) sync(response *respSync, opts opt) error {
-	// Read profile from storage it no force and session is still valid
-	if !opts.force && c.storage != nil && c.session.IsValid() {
-		if profile, err := c.storage.ReadProfile(); err != nil {
+	// Read the account revision date, which is recorded as the time of the
+	// last sync. Without storage there is nothing to compare it with
+	var revisionDate time.Time
+	if c.storage != nil {
+		if date, err := c.revisionDate(); err != nil {
 			return err
-		} else if profile != nil {
-			response.Profile = profile
-			return nil
+		} else {
+			revisionDate = date
+		}
+	}
+
+	// Use the vault in storage if the account has not changed since the last
+	// sync, unless OptForce is set
+	if c.storage != nil && !opts.force {
+		if lastSync, err := c.storage.LastSync(); err != nil {
+			return err
+		} else if !lastSync.IsZero() && !revisionDate.After(lastSync) {
+			if profile, err := c.storage.ReadProfile(); err != nil {
+				return err
+			} else if profile != nil {
+				response.Profile = profile
+				return nil
+			}
 		}
 		// Profile can be nil, in which case it is read from the server
 	}
 
 	// Request -> Response
-	if err := c.client.Do(nil, &response, client.OptPath("sync"), client.OptToken(client.Token{
-		Scheme: c.session.Token.TokenType,
-		Value:  c.session.Token.AccessToken,
-	})); err != nil {
+	if err := c.client.Do(nil, &response, client.OptPath("sync"), client.OptQuery(url.Values{"excludeDomains": []string{"true"}}), c.token()); err != nil {
 		return err
 	}
 
-	// Write the session to storage
+	// Return if there is no storage
+	if c.storage == nil {
+		return nil
+	}
+
+	// The whole vault is downloaded, so the profile and collections are always
+	// written, and the ciphers and folders only when any item has changed
 	var result error
-	if c.storage != nil {
-		if err := c.storage.WriteProfile(response.Profile); err != nil {
-			result = errors.Join(result, err)
-		}
+	if err := c.storage.WriteProfile(response.Profile); err != nil {
+		result = errors.Join(result, err)
+	}
+	if iterator, err := c.storage.ReadCiphers(); err != nil || changedValues(iterator, response.Ciphers, func(v *schema.Cipher) (string, time.Time) {
+		return v.Id, v.RevisionDate
+	}) {
 		if err := c.storage.WriteCiphers(response.Ciphers); err != nil {
 			result = errors.Join(result, err)
 		}
+	}
+	if iterator, err := c.storage.ReadFolders(); err != nil || changedValues(iterator, response.Folders, func(v *schema.Folder) (string, time.Time) {
+		return v.Id, v.RevisionDate
+	}) {
 		if err := c.storage.WriteFolders(response.Folders); err != nil {
 			result = errors.Join(result, err)
 		}
 	}
+	if err := c.storage.WriteCollections(response.Collections); err != nil {
+		result = errors.Join(result, err)
+	}
+
+	// Record the account revision date as the time of the last sync
+	if result == nil {
+		result = c.storage.WriteLastSync(revisionDate)
+	}
 
 	// Return any errors
 	return result
 }
+
+// Return the date the account was last changed
+func (c *Client) revisionDate() (time.Time, error) {
+	var response int64
+	if err := c.client.Do(nil, &response, client.OptPath("accounts", "revision-date"), c.token()); err != nil {
+		return time.Time{}, err
+	}
+	return time.UnixMilli(response), nil
+}
+
+// Return true if the values differ from the stored values, by identifier and
+// revision date
+func changedValues[T schema.Iterable](iterator schema.Iterator[T], values []T, revision func(T) (string, time.Time)) bool {
+	stored := make(map[string]time.Time, len(values))
+	for v := iterator.Next(); v != nil; v = iterator.Next() {
+		id, date := revision(v)
+		stored[id] = date
+	}
+	if len(stored) != len(values) {
+		return true
+	}
+	for _, v := range values {
+		id, date := revision(v)
+		if storedDate, exists := stored[id]; !exists || !storedDate.Equal(date) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package homeassistant
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"time"
+
+	// Packages
+	"github.com/mutablelogic/go-client"
+
+	// Namespace imports
+	. "github.com/djthorpe/go-errors"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+type Calendar struct {
+	Entity string `json:"entity_id,width:40"`
+	Name   string `json:"name"`
+}
+
+type CalendarEvent struct {
+	Summary      string       `json:"summary"`
+	Description  string       `json:"description,omitempty,wrap"`
+	Location     string       `json:"location,omitempty"`
+	Start        CalendarTime `json:"start"`
+	End          CalendarTime `json:"end"`
+	Uid          string       `json:"uid,omitempty"`
+	RecurrenceId string       `json:"recurrence_id,omitempty"`
+	RRule        string       `json:"rrule,omitempty"`
+}
+
+// CalendarTime is either a date (for all-day events) or a date and time
+type CalendarTime struct {
+	Date     string    `json:"date,omitempty"`
+	DateTime time.Time `json:"dateTime,omitzero"`
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// API CALLS
+
+// Calendars returns all the calendar entities
+func (c *Client) Calendars(ctx context.Context) ([]*Calendar, error) {
+	var response []*Calendar
+	if err := c.DoWithContext(ctx, nil, &response, client.OptPath("calendars")); err != nil {
+		return nil, err
+	}
+
+	// Return success
+	return response, nil
+}
+
+// CalendarEvents returns the events for a calendar entity which occur
+// between the start and end times
+func (c *Client) CalendarEvents(ctx context.Context, entity string, start, end time.Time) ([]*CalendarEvent, error) {
+	if domainForEntity(entity) != "calendar" {
+		return nil, ErrBadParameter.Withf("Invalid calendar entity: %q", entity)
+	} else if start.IsZero() || end.IsZero() || !end.After(start) {
+		return nil, ErrBadParameter.With("Invalid time range")
+	}
+
+	// Set the time range
+	query := url.Values{}
+	query.Set("start", start.Format(time.RFC3339))
+	query.Set("end", end.Format(time.RFC3339))
+
+	// Return the response
+	var response []*CalendarEvent
+	if err := c.DoWithContext(ctx, nil, &response, client.OptPath("calendars", entity), client.OptQuery(query)); err != nil {
+		return nil, err
+	}
+
+	// Return success
+	return response, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// STRINGIFY
+
+func (v Calendar) String() string {
+	data, _ := json.MarshalIndent(v, "", "  ")
+	return string(data)
+}
+
+func (v CalendarEvent) String() string {
+	data, _ := json.MarshalIndent(v, "", "  ")
+	return string(data)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// METHODS
+
+// AllDay returns true if the event spans whole days rather than a time range
+func (e CalendarEvent) AllDay() bool {
+	return e.Start.DateTime.IsZero() && e.Start.Date != ""
+}
+
+// Time returns the time, or midnight in the local timezone for a date. It
+// returns the zero time if the value cannot be parsed.
+func (t CalendarTime) Time() time.Time {
+	if !t.DateTime.IsZero() {
+		return t.DateTime
+	} else if date, err := time.ParseInLocation(time.DateOnly, t.Date, time.Local); err == nil {
+		return date
+	} else {
+		return time.Time{}
+	}
+}
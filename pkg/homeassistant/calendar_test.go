@@ -0,0 +1,35 @@
+package homeassistant_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	// Packages
+	opts "github.com/mutablelogic/go-client"
+	homeassistant "github.com/mutablelogic/go-client/pkg/homeassistant"
+	assert "github.com/stretchr/testify/assert"
+)
+
+func Test_calendar_001(t *testing.T) {
+	assert := assert.New(t)
+	client, err := homeassistant.New(GetEndPoint(t), GetApiKey(t), opts.OptTrace(os.Stderr, true))
+	assert.NoError(err)
+	assert.NotNil(client)
+
+	calendars, err := client.Calendars(context.Background())
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+	assert.NotNil(calendars)
+
+	now := time.Now()
+	for _, calendar := range calendars {
+		events, err := client.CalendarEvents(context.Background(), calendar.Entity, now, now.AddDate(0, 0, 7))
+		assert.NoError(err)
+		for _, event := range events {
+			t.Log(calendar.Name, "=>", event.Summary, event.Start.Time(), event.AllDay())
+		}
+	}
+}
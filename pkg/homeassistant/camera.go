@@ -0,0 +1,29 @@
+package homeassistant
+
+import (
+	"context"
+	"io"
+
+	// Packages
+	"github.com/mutablelogic/go-client"
+
+	// Namespace imports
+	. "github.com/djthorpe/go-errors"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// API CALLS
+
+// CameraImage writes the current image for a camera entity to the writer.
+// The image is streamed directly from the response body, and is usually
+// in JPEG format.
+func (c *Client) CameraImage(ctx context.Context, entity string, w io.Writer) error {
+	if domainForEntity(entity) != "camera" {
+		return ErrBadParameter.Withf("Invalid camera entity: %q", entity)
+	} else if w == nil {
+		return ErrBadParameter.With("Missing writer")
+	}
+
+	// Stream the image to the writer
+	return c.DoWithContext(ctx, nil, w, client.OptPath("camera_proxy", entity))
+}
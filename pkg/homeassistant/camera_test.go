@@ -0,0 +1,47 @@
+package homeassistant_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	// Packages
+	opts "github.com/mutablelogic/go-client"
+	homeassistant "github.com/mutablelogic/go-client/pkg/homeassistant"
+	assert "github.com/stretchr/testify/assert"
+)
+
+func Test_camera_001(t *testing.T) {
+	assert := assert.New(t)
+	client, err := homeassistant.New(GetEndPoint(t), GetApiKey(t), opts.OptTrace(os.Stderr, false))
+	assert.NoError(err)
+	assert.NotNil(client)
+
+	states, err := client.States(context.Background())
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+
+	for _, state := range states {
+		if state.Domain() != "camera" {
+			continue
+		}
+		var image bytes.Buffer
+		err := client.CameraImage(context.Background(), state.Entity, &image)
+		assert.NoError(err)
+		assert.NotZero(image.Len())
+		t.Log(state.Entity, "=>", image.Len(), "bytes")
+	}
+}
+
+func Test_camera_002(t *testing.T) {
+	assert := assert.New(t)
+	client, err := homeassistant.New(GetEndPoint(t), GetApiKey(t))
+	assert.NoError(err)
+	assert.NotNil(client)
+
+	var image bytes.Buffer
+	err = client.CameraImage(context.Background(), "light.not_a_camera", &image)
+	assert.Error(err)
+}
@@ -0,0 +1,176 @@
+package homeassistant
+
+import (
+	"context"
+
+	// Namespace imports
+	. "github.com/djthorpe/go-errors"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// Climate is a typed view of an entity in the "climate" domain
+type Climate struct {
+	entity
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// LIFECYCLE
+
+// Climate returns a typed view of a climate entity state
+func (c *Client) Climate(state *State) (*Climate, error) {
+	entity, err := c.newEntity(state, "climate")
+	if err != nil {
+		return nil, err
+	}
+	return &Climate{entity}, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PROPERTIES
+
+// Mode returns the HVAC mode (for example "off", "heat", "cool" or "auto"),
+// or empty string if the entity is unavailable
+func (c *Climate) Mode() string {
+	return c.Value()
+}
+
+// Modes returns the HVAC modes supported by the entity
+func (c *Climate) Modes() []string {
+	return c.attrStrings("hvac_modes")
+}
+
+// Action returns the current HVAC action (for example "heating", "cooling"
+// or "idle"), or empty string if not known
+func (c *Climate) Action() string {
+	value, _ := c.attrString("hvac_action")
+	return value
+}
+
+// PresetMode returns the current preset mode, or empty string if not known
+func (c *Climate) PresetMode() string {
+	value, _ := c.attrString("preset_mode")
+	return value
+}
+
+// PresetModes returns the preset modes supported by the entity
+func (c *Climate) PresetModes() []string {
+	return c.attrStrings("preset_modes")
+}
+
+// FanMode returns the current fan mode, or empty string if not known
+func (c *Climate) FanMode() string {
+	value, _ := c.attrString("fan_mode")
+	return value
+}
+
+// FanModes returns the fan modes supported by the entity
+func (c *Climate) FanModes() []string {
+	return c.attrStrings("fan_modes")
+}
+
+// CurrentTemperature returns the measured temperature, or false if not known
+func (c *Climate) CurrentTemperature() (float64, bool) {
+	return c.attrFloat("current_temperature")
+}
+
+// CurrentHumidity returns the measured humidity, or false if not known
+func (c *Climate) CurrentHumidity() (float64, bool) {
+	return c.attrFloat("current_humidity")
+}
+
+// TargetTemperature returns the target temperature setpoint, or false if
+// the entity uses a temperature range or the setpoint is not known
+func (c *Climate) TargetTemperature() (float64, bool) {
+	return c.attrFloat("temperature")
+}
+
+// TargetTemperatureRange returns the low and high temperature setpoints, or
+// false if the entity does not use a temperature range
+func (c *Climate) TargetTemperatureRange() (float64, float64, bool) {
+	lo, ok := c.attrFloat("target_temp_low")
+	if !ok {
+		return 0, 0, false
+	}
+	hi, ok := c.attrFloat("target_temp_high")
+	if !ok {
+		return 0, 0, false
+	}
+	return lo, hi, true
+}
+
+// TemperatureLimits returns the minimum and maximum temperature setpoints
+// supported by the entity, or false if not known
+func (c *Climate) TemperatureLimits() (float64, float64, bool) {
+	lo, ok := c.attrFloat("min_temp")
+	if !ok {
+		return 0, 0, false
+	}
+	hi, ok := c.attrFloat("max_temp")
+	if !ok {
+		return 0, 0, false
+	}
+	return lo, hi, true
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// ACTIONS
+
+// TurnOn turns on the entity
+func (c *Climate) TurnOn(ctx context.Context) error {
+	return c.call(ctx, "turn_on", nil)
+}
+
+// TurnOff turns off the entity
+func (c *Climate) TurnOff(ctx context.Context) error {
+	return c.call(ctx, "turn_off", nil)
+}
+
+// SetTemperature sets the target temperature setpoint
+func (c *Climate) SetTemperature(ctx context.Context, value float64) error {
+	return c.call(ctx, "set_temperature", map[string]any{
+		"temperature": value,
+	})
+}
+
+// SetTemperatureRange sets the low and high temperature setpoints
+func (c *Climate) SetTemperatureRange(ctx context.Context, lo, hi float64) error {
+	if hi < lo {
+		return ErrBadParameter.Withf("Invalid temperature range: %v-%v", lo, hi)
+	}
+	return c.call(ctx, "set_temperature", map[string]any{
+		"target_temp_low":  lo,
+		"target_temp_high": hi,
+	})
+}
+
+// SetMode sets the HVAC mode
+func (c *Climate) SetMode(ctx context.Context, mode string) error {
+	if mode == "" {
+		return ErrBadParameter.With("Missing HVAC mode")
+	}
+	return c.call(ctx, "set_hvac_mode", map[string]any{
+		"hvac_mode": mode,
+	})
+}
+
+// SetPresetMode sets the preset mode
+func (c *Climate) SetPresetMode(ctx context.Context, preset string) error {
+	if preset == "" {
+		return ErrBadParameter.With("Missing preset mode")
+	}
+	return c.call(ctx, "set_preset_mode", map[string]any{
+		"preset_mode": preset,
+	})
+}
+
+// SetFanMode sets the fan mode
+func (c *Climate) SetFanMode(ctx context.Context, mode string) error {
+	if mode == "" {
+		return ErrBadParameter.With("Missing fan mode")
+	}
+	return c.call(ctx, "set_fan_mode", map[string]any{
+		"fan_mode": mode,
+	})
+}
@@ -0,0 +1,108 @@
+package homeassistant
+
+import (
+	"context"
+
+	// Namespace imports
+	. "github.com/djthorpe/go-errors"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// Cover is a typed view of an entity in the "cover" domain, such as a
+// blind, garage door or shutter
+type Cover struct {
+	entity
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// LIFECYCLE
+
+// Cover returns a typed view of a cover entity state
+func (c *Client) Cover(state *State) (*Cover, error) {
+	entity, err := c.newEntity(state, "cover")
+	if err != nil {
+		return nil, err
+	}
+	return &Cover{entity}, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PROPERTIES
+
+// IsOpen returns true if the cover is open or opening
+func (c *Cover) IsOpen() bool {
+	return c.State.State == "open" || c.State.State == "opening"
+}
+
+// IsClosed returns true if the cover is closed or closing
+func (c *Cover) IsClosed() bool {
+	return c.State.State == "closed" || c.State.State == "closing"
+}
+
+// IsMoving returns true if the cover is opening or closing
+func (c *Cover) IsMoving() bool {
+	return c.State.State == "opening" || c.State.State == "closing"
+}
+
+// Position returns the position of the cover between 0 (closed) and
+// 100 (open), or false if the position is not known
+func (c *Cover) Position() (uint, bool) {
+	return c.position("current_position")
+}
+
+// TiltPosition returns the tilt position of the cover between 0 (closed)
+// and 100 (open), or false if the tilt position is not known
+func (c *Cover) TiltPosition() (uint, bool) {
+	return c.position("current_tilt_position")
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// ACTIONS
+
+// Open opens the cover
+func (c *Cover) Open(ctx context.Context) error {
+	return c.call(ctx, "open_cover", nil)
+}
+
+// Close closes the cover
+func (c *Cover) Close(ctx context.Context) error {
+	return c.call(ctx, "close_cover", nil)
+}
+
+// Stop stops the cover if it is moving
+func (c *Cover) Stop(ctx context.Context) error {
+	return c.call(ctx, "stop_cover", nil)
+}
+
+// SetPosition moves the cover to a position between 0 (closed) and 100 (open)
+func (c *Cover) SetPosition(ctx context.Context, position uint) error {
+	if position > 100 {
+		return ErrBadParameter.Withf("Invalid position: %d", position)
+	}
+	return c.call(ctx, "set_cover_position", map[string]any{
+		"position": position,
+	})
+}
+
+// SetTiltPosition tilts the cover to a position between 0 (closed) and 100 (open)
+func (c *Cover) SetTiltPosition(ctx context.Context, position uint) error {
+	if position > 100 {
+		return ErrBadParameter.Withf("Invalid tilt position: %d", position)
+	}
+	return c.call(ctx, "set_cover_tilt_position", map[string]any{
+		"tilt_position": position,
+	})
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+func (c *Cover) position(name string) (uint, bool) {
+	if value, ok := c.attrFloat(name); !ok || value < 0 {
+		return 0, false
+	} else {
+		return uint(min(value, 100)), true
+	}
+}
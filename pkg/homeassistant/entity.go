@@ -0,0 +1,94 @@
+package homeassistant
+
+import (
+	"context"
+
+	// Namespace imports
+	. "github.com/djthorpe/go-errors"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// entity is embedded in the typed entity views, and binds a state to the
+// client used to call services on it
+type entity struct {
+	*State
+	client *Client
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// LIFECYCLE
+
+func (c *Client) newEntity(state *State, domain string) (entity, error) {
+	if state == nil {
+		return entity{}, ErrBadParameter.With("Missing state")
+	} else if state.Domain() != domain {
+		return entity{}, ErrBadParameter.Withf("Invalid %s entity: %q", domain, state.Entity)
+	}
+	return entity{state, c}, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// call a service on the entity in the entity domain
+func (e entity) call(ctx context.Context, service string, data map[string]any) error {
+	_, err := e.client.CallWithData(ctx, service, e.Entity, data)
+	return err
+}
+
+// attrString returns a string attribute, or false if the attribute does not
+// exist or is not a string
+func (e entity) attrString(name string) (string, bool) {
+	value, ok := e.Attributes[name].(string)
+	return value, ok
+}
+
+// attrFloat returns a numeric attribute, or false if the attribute does not
+// exist or is not a number
+func (e entity) attrFloat(name string) (float64, bool) {
+	value, ok := e.Attributes[name].(float64)
+	return value, ok
+}
+
+// attrBool returns a boolean attribute, or false if the attribute does not
+// exist or is not a boolean
+func (e entity) attrBool(name string) (bool, bool) {
+	value, ok := e.Attributes[name].(bool)
+	return value, ok
+}
+
+// attrStrings returns a list of strings attribute, ignoring any elements
+// which are not strings
+func (e entity) attrStrings(name string) []string {
+	values, ok := e.Attributes[name].([]any)
+	if !ok {
+		return nil
+	}
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if value, ok := value.(string); ok {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// attrFloats returns a list of numbers attribute, or nil if any element is
+// not a number
+func (e entity) attrFloats(name string) []float64 {
+	values, ok := e.Attributes[name].([]any)
+	if !ok {
+		return nil
+	}
+	result := make([]float64, 0, len(values))
+	for _, value := range values {
+		if value, ok := value.(float64); ok {
+			result = append(result, value)
+		} else {
+			return nil
+		}
+	}
+	return result
+}
@@ -0,0 +1,178 @@
+package homeassistant_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	// Packages
+	homeassistant "github.com/mutablelogic/go-client/pkg/homeassistant"
+	assert "github.com/stretchr/testify/assert"
+)
+
+func Test_entity_001(t *testing.T) {
+	assert := assert.New(t)
+	client, err := homeassistant.New("http://localhost/api", "token")
+	assert.NoError(err)
+
+	// Domain must match
+	_, err = client.Light(&homeassistant.State{Entity: "sensor.temperature"})
+	assert.Error(err)
+	_, err = client.Light(nil)
+	assert.Error(err)
+
+	// Light attributes
+	light, err := client.Light(state(t, `{
+		"entity_id": "light.kitchen", "state": "on",
+		"attributes": { "brightness": 128, "color_mode": "rgb", "rgb_color": [255, 10, 0], "supported_color_modes": ["rgb", "color_temp"] }
+	}`))
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+	assert.True(light.IsOn())
+	brightness, ok := light.Brightness()
+	assert.True(ok)
+	assert.Equal(uint8(128), brightness)
+	r, g, b, ok := light.RGBColor()
+	assert.True(ok)
+	assert.Equal([]uint8{255, 10, 0}, []uint8{r, g, b})
+	assert.Equal("rgb", light.ColorMode())
+	assert.Equal([]string{"rgb", "color_temp"}, light.ColorModes())
+	_, ok = light.ColorTemp()
+	assert.False(ok)
+}
+
+func Test_entity_002(t *testing.T) {
+	assert := assert.New(t)
+	client, err := homeassistant.New("http://localhost/api", "token")
+	assert.NoError(err)
+
+	// Climate attributes
+	climate, err := client.Climate(state(t, `{
+		"entity_id": "climate.hall", "state": "heat",
+		"attributes": { "hvac_modes": ["off", "heat"], "current_temperature": 19.5, "temperature": 21, "hvac_action": "heating" }
+	}`))
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+	assert.Equal("heat", climate.Mode())
+	assert.Equal([]string{"off", "heat"}, climate.Modes())
+	assert.Equal("heating", climate.Action())
+	current, ok := climate.CurrentTemperature()
+	assert.True(ok)
+	assert.Equal(19.5, current)
+	target, ok := climate.TargetTemperature()
+	assert.True(ok)
+	assert.Equal(21.0, target)
+	_, _, ok = climate.TargetTemperatureRange()
+	assert.False(ok)
+
+	// Sensor value
+	sensor, err := client.Sensor(state(t, `{
+		"entity_id": "sensor.power", "state": "1.25",
+		"attributes": { "unit_of_measurement": "kW", "state_class": "measurement" }
+	}`))
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+	value, ok := sensor.Float()
+	assert.True(ok)
+	assert.Equal(1.25, value)
+	assert.Equal("kW", sensor.Unit())
+	assert.Equal("measurement", sensor.StateClass())
+
+	// Unavailable sensor
+	sensor, err = client.Sensor(state(t, `{ "entity_id": "sensor.power", "state": "unavailable" }`))
+	assert.NoError(err)
+	_, ok = sensor.Float()
+	assert.False(ok)
+}
+
+func Test_entity_003(t *testing.T) {
+	assert := assert.New(t)
+	client, err := homeassistant.New("http://localhost/api", "token")
+	assert.NoError(err)
+
+	// Cover attributes
+	cover, err := client.Cover(state(t, `{
+		"entity_id": "cover.garage", "state": "opening",
+		"attributes": { "current_position": 40 }
+	}`))
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+	assert.True(cover.IsOpen())
+	assert.True(cover.IsMoving())
+	position, ok := cover.Position()
+	assert.True(ok)
+	assert.Equal(uint(40), position)
+	_, ok = cover.TiltPosition()
+	assert.False(ok)
+
+	// Media player attributes
+	player, err := client.MediaPlayer(state(t, `{
+		"entity_id": "media_player.lounge", "state": "playing",
+		"attributes": { "volume_level": 0.5, "is_volume_muted": true, "media_title": "Title", "media_duration": 90 }
+	}`))
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+	assert.True(player.IsOn())
+	assert.True(player.IsPlaying())
+	assert.True(player.Muted())
+	assert.Equal("Title", player.Title())
+	assert.Equal(90*time.Second, player.Duration())
+	volume, ok := player.Volume()
+	assert.True(ok)
+	assert.Equal(0.5, volume)
+}
+
+func Test_entity_004(t *testing.T) {
+	assert := assert.New(t)
+
+	// Record the service call
+	var path string
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, body = r.URL.Path, nil
+		assert.NoError(json.NewDecoder(r.Body).Decode(&body))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	client, err := homeassistant.New(server.URL+"/api", "token")
+	assert.NoError(err)
+	light, err := client.Light(&homeassistant.State{Entity: "light.kitchen", State: "off"})
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+
+	// Turn on with brightness
+	assert.NoError(light.TurnOn(context.Background(), 200))
+	assert.Equal("/api/services/light/turn_on", path)
+	assert.Equal(map[string]any{"entity_id": "light.kitchen", "brightness": 200.0}, body)
+
+	// Turn on with color
+	assert.NoError(light.SetRGBColor(context.Background(), 1, 2, 3))
+	assert.Equal(map[string]any{"entity_id": "light.kitchen", "rgb_color": []any{1.0, 2.0, 3.0}}, body)
+
+	// Turn off
+	assert.NoError(light.TurnOff(context.Background()))
+	assert.Equal("/api/services/light/turn_off", path)
+	assert.Equal(map[string]any{"entity_id": "light.kitchen"}, body)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+func state(t *testing.T, data string) *homeassistant.State {
+	var state homeassistant.State
+	if err := json.Unmarshal([]byte(data), &state); err != nil {
+		t.Fatal(err)
+	}
+	return &state
+}
@@ -0,0 +1,153 @@
+package homeassistant
+
+import (
+	"context"
+
+	// Namespace imports
+	. "github.com/djthorpe/go-errors"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// Light is a typed view of an entity in the "light" domain
+type Light struct {
+	entity
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// LIFECYCLE
+
+// Light returns a typed view of a light entity state
+func (c *Client) Light(state *State) (*Light, error) {
+	entity, err := c.newEntity(state, "light")
+	if err != nil {
+		return nil, err
+	}
+	return &Light{entity}, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PROPERTIES
+
+// IsOn returns true if the light is on
+func (l *Light) IsOn() bool {
+	return l.State.State == "on"
+}
+
+// Brightness returns the brightness of the light between 0 and 255, or false
+// if the brightness is not known (for example, when the light is off)
+func (l *Light) Brightness() (uint8, bool) {
+	if value, ok := l.attrFloat("brightness"); !ok {
+		return 0, false
+	} else {
+		return uint8(max(0, min(value, 255))), true
+	}
+}
+
+// ColorMode returns the current color mode (for example "rgb", "hs",
+// "color_temp" or "brightness"), or empty string if not known
+func (l *Light) ColorMode() string {
+	value, _ := l.attrString("color_mode")
+	return value
+}
+
+// ColorModes returns the color modes supported by the light
+func (l *Light) ColorModes() []string {
+	return l.attrStrings("supported_color_modes")
+}
+
+// RGBColor returns the red, green and blue components of the light color,
+// or false if the color is not known
+func (l *Light) RGBColor() (uint8, uint8, uint8, bool) {
+	rgb := l.attrFloats("rgb_color")
+	if len(rgb) != 3 {
+		return 0, 0, 0, false
+	}
+	return uint8(rgb[0]), uint8(rgb[1]), uint8(rgb[2]), true
+}
+
+// ColorTemp returns the color temperature in kelvin, or false if the color
+// temperature is not known
+func (l *Light) ColorTemp() (uint, bool) {
+	if value, ok := l.attrFloat("color_temp_kelvin"); !ok || value < 0 {
+		return 0, false
+	} else {
+		return uint(value), true
+	}
+}
+
+// ColorTempRange returns the minimum and maximum color temperature in kelvin
+// supported by the light, or false if the range is not known
+func (l *Light) ColorTempRange() (uint, uint, bool) {
+	lo, ok := l.attrFloat("min_color_temp_kelvin")
+	if !ok || lo < 0 {
+		return 0, 0, false
+	}
+	hi, ok := l.attrFloat("max_color_temp_kelvin")
+	if !ok || hi < lo {
+		return 0, 0, false
+	}
+	return uint(lo), uint(hi), true
+}
+
+// Effect returns the current effect, or empty string if there is no effect
+func (l *Light) Effect() string {
+	value, _ := l.attrString("effect")
+	return value
+}
+
+// Effects returns the effects supported by the light
+func (l *Light) Effects() []string {
+	return l.attrStrings("effect_list")
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// ACTIONS
+
+// TurnOn turns on the light with a brightness between 1 and 255. When the
+// brightness is zero, the light is turned on with the previous brightness.
+func (l *Light) TurnOn(ctx context.Context, brightness uint8) error {
+	data := map[string]any{}
+	if brightness > 0 {
+		data["brightness"] = brightness
+	}
+	return l.call(ctx, "turn_on", data)
+}
+
+// TurnOff turns off the light
+func (l *Light) TurnOff(ctx context.Context) error {
+	return l.call(ctx, "turn_off", nil)
+}
+
+// Toggle turns the light on if it is off, or off if it is on
+func (l *Light) Toggle(ctx context.Context) error {
+	return l.call(ctx, "toggle", nil)
+}
+
+// SetRGBColor turns on the light with a red, green and blue color
+func (l *Light) SetRGBColor(ctx context.Context, r, g, b uint8) error {
+	return l.call(ctx, "turn_on", map[string]any{
+		"rgb_color": []int{int(r), int(g), int(b)},
+	})
+}
+
+// SetColorTemp turns on the light with a color temperature in kelvin
+func (l *Light) SetColorTemp(ctx context.Context, kelvin uint) error {
+	if kelvin == 0 {
+		return ErrBadParameter.With("Invalid color temperature")
+	}
+	return l.call(ctx, "turn_on", map[string]any{
+		"color_temp_kelvin": kelvin,
+	})
+}
+
+// SetEffect turns on the light with an effect
+func (l *Light) SetEffect(ctx context.Context, effect string) error {
+	if effect == "" {
+		return ErrBadParameter.With("Missing effect")
+	}
+	return l.call(ctx, "turn_on", map[string]any{
+		"effect": effect,
+	})
+}
@@ -0,0 +1,178 @@
+package homeassistant
+
+import (
+	"context"
+	"time"
+
+	// Namespace imports
+	. "github.com/djthorpe/go-errors"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// MediaPlayer is a typed view of an entity in the "media_player" domain
+type MediaPlayer struct {
+	entity
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// LIFECYCLE
+
+// MediaPlayer returns a typed view of a media player entity state
+func (c *Client) MediaPlayer(state *State) (*MediaPlayer, error) {
+	entity, err := c.newEntity(state, "media_player")
+	if err != nil {
+		return nil, err
+	}
+	return &MediaPlayer{entity}, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PROPERTIES
+
+// IsOn returns true if the media player is not off, standby or unavailable
+func (m *MediaPlayer) IsOn() bool {
+	switch m.Value() {
+	case "", "off", "standby":
+		return false
+	default:
+		return true
+	}
+}
+
+// IsPlaying returns true if the media player is playing
+func (m *MediaPlayer) IsPlaying() bool {
+	return m.State.State == "playing"
+}
+
+// Volume returns the volume level between 0 and 1, or false if not known
+func (m *MediaPlayer) Volume() (float64, bool) {
+	return m.attrFloat("volume_level")
+}
+
+// Muted returns true if the volume is muted
+func (m *MediaPlayer) Muted() bool {
+	value, _ := m.attrBool("is_volume_muted")
+	return value
+}
+
+// Title returns the title of the current media, or empty string if not known
+func (m *MediaPlayer) Title() string {
+	value, _ := m.attrString("media_title")
+	return value
+}
+
+// Artist returns the artist of the current media, or empty string if not known
+func (m *MediaPlayer) Artist() string {
+	value, _ := m.attrString("media_artist")
+	return value
+}
+
+// Album returns the album name of the current media, or empty string if
+// not known
+func (m *MediaPlayer) Album() string {
+	value, _ := m.attrString("media_album_name")
+	return value
+}
+
+// ContentType returns the content type of the current media (for example
+// "music", "tvshow" or "movie"), or empty string if not known
+func (m *MediaPlayer) ContentType() string {
+	value, _ := m.attrString("media_content_type")
+	return value
+}
+
+// Duration returns the duration of the current media, or zero if not known
+func (m *MediaPlayer) Duration() time.Duration {
+	value, _ := m.attrFloat("media_duration")
+	return time.Duration(value * float64(time.Second))
+}
+
+// Position returns the playback position of the current media when the
+// state was last updated, or zero if not known
+func (m *MediaPlayer) Position() time.Duration {
+	value, _ := m.attrFloat("media_position")
+	return time.Duration(value * float64(time.Second))
+}
+
+// Source returns the current input source, or empty string if not known
+func (m *MediaPlayer) Source() string {
+	value, _ := m.attrString("source")
+	return value
+}
+
+// Sources returns the input sources supported by the media player
+func (m *MediaPlayer) Sources() []string {
+	return m.attrStrings("source_list")
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// ACTIONS
+
+// TurnOn turns on the media player
+func (m *MediaPlayer) TurnOn(ctx context.Context) error {
+	return m.call(ctx, "turn_on", nil)
+}
+
+// TurnOff turns off the media player
+func (m *MediaPlayer) TurnOff(ctx context.Context) error {
+	return m.call(ctx, "turn_off", nil)
+}
+
+// Play starts or resumes playback
+func (m *MediaPlayer) Play(ctx context.Context) error {
+	return m.call(ctx, "media_play", nil)
+}
+
+// Pause pauses playback
+func (m *MediaPlayer) Pause(ctx context.Context) error {
+	return m.call(ctx, "media_pause", nil)
+}
+
+// PlayPause toggles between playing and paused
+func (m *MediaPlayer) PlayPause(ctx context.Context) error {
+	return m.call(ctx, "media_play_pause", nil)
+}
+
+// Stop stops playback
+func (m *MediaPlayer) Stop(ctx context.Context) error {
+	return m.call(ctx, "media_stop", nil)
+}
+
+// Next skips to the next track
+func (m *MediaPlayer) Next(ctx context.Context) error {
+	return m.call(ctx, "media_next_track", nil)
+}
+
+// Previous skips to the previous track
+func (m *MediaPlayer) Previous(ctx context.Context) error {
+	return m.call(ctx, "media_previous_track", nil)
+}
+
+// SetVolume sets the volume level between 0 and 1
+func (m *MediaPlayer) SetVolume(ctx context.Context, level float64) error {
+	if level < 0 || level > 1 {
+		return ErrBadParameter.Withf("Invalid volume level: %v", level)
+	}
+	return m.call(ctx, "volume_set", map[string]any{
+		"volume_level": level,
+	})
+}
+
+// Mute mutes or unmutes the volume
+func (m *MediaPlayer) Mute(ctx context.Context, muted bool) error {
+	return m.call(ctx, "volume_mute", map[string]any{
+		"is_volume_muted": muted,
+	})
+}
+
+// SelectSource selects an input source
+func (m *MediaPlayer) SelectSource(ctx context.Context, source string) error {
+	if source == "" {
+		return ErrBadParameter.With("Missing source")
+	}
+	return m.call(ctx, "select_source", map[string]any{
+		"source": source,
+	})
+}
@@ -0,0 +1,54 @@
+package homeassistant
+
+import (
+	"strconv"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// Sensor is a typed view of an entity in the "sensor" domain
+type Sensor struct {
+	entity
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// LIFECYCLE
+
+// Sensor returns a typed view of a sensor entity state
+func (c *Client) Sensor(state *State) (*Sensor, error) {
+	entity, err := c.newEntity(state, "sensor")
+	if err != nil {
+		return nil, err
+	}
+	return &Sensor{entity}, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PROPERTIES
+
+// Float returns the numeric value of the sensor, or false if the sensor
+// is unavailable or the value is not numeric
+func (s *Sensor) Float() (float64, bool) {
+	value := s.Value()
+	if value == "" {
+		return 0, false
+	} else if f, err := strconv.ParseFloat(value, 64); err != nil {
+		return 0, false
+	} else {
+		return f, true
+	}
+}
+
+// Unit returns the unit of measurement for the sensor value, or empty
+// string if the value has no unit
+func (s *Sensor) Unit() string {
+	return s.UnitOfMeasurement()
+}
+
+// StateClass returns the state class (for example "measurement", "total"
+// or "total_increasing"), or empty string if not known
+func (s *Sensor) StateClass() string {
+	value, _ := s.attrString("state_class")
+	return value
+}
@@ -43,10 +43,6 @@ type Selector struct {
 	UnitOfMeasurement string  `json:"unit_of_measurement,omitempty"`
 }
 
-type reqCall struct {
-	Entity string `json:"entity_id"`
-}
-
 ///////////////////////////////////////////////////////////////////////////////
 // API CALLS
 
@@ -86,19 +82,27 @@ func (c *Client) Services(ctx context.Context, domain string) ([]*Service, error
 
 // Call a service for an entity. Returns a list of states that have
 // changed while the service was being executed.
-// TODO: This is a placeholder implementation, and requires fields to
-// be passed in the request
 func (c *Client) Call(ctx context.Context, service, entity string) ([]*State, error) {
+	return c.CallWithData(ctx, service, entity, nil)
+}
+
+// CallWithData calls a service for an entity with additional service data
+// fields. Returns a list of states that have changed while the service
+// was being executed.
+func (c *Client) CallWithData(ctx context.Context, service, entity string, data map[string]any) ([]*State, error) {
 	domain := domainForEntity(entity)
 	if domain == "" {
 		return nil, ErrBadParameter.Withf("Invalid entity: %q", entity)
 	}
 
+	// Set the service data, the entity_id field cannot be overridden
+	req := make(map[string]any, len(data)+1)
+	maps.Copy(req, data)
+	req["entity_id"] = entity
+
 	// Call the service
 	var response []*State
-	if payload, err := client.NewJSONRequest(reqCall{
-		Entity: entity,
-	}); err != nil {
+	if payload, err := client.NewJSONRequest(req); err != nil {
 		return nil, err
 	} else if err := c.DoWithContext(ctx, payload, &response, client.OptPath("services", domain, service)); err != nil {
 		return nil, err
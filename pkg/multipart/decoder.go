@@ -0,0 +1,471 @@
+package multipart
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"io"
+	"iter"
+	"mime"
+	"mime/multipart"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	// Packages
+	httpresponse "github.com/mutablelogic/go-server/pkg/httpresponse"
+	types "github.com/mutablelogic/go-server/pkg/types"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// Decoder is a multipart or form decoder object
+type Decoder struct {
+	r        io.Reader
+	boundary string
+	form     bool
+}
+
+// tempFile is a file body which is removed when closed
+type tempFile struct {
+	*os.File
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// GLOBALS
+
+const (
+	// Maximum size of a field or file held in memory. Larger files are written
+	// to a temporary file.
+	maxMemory = 32 << 20
+
+	// Prefix for multipart content types
+	contentTypeMultipart = "multipart/"
+)
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	durationType        = reflect.TypeOf(time.Duration(0))
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// LIFECYCLE
+
+// NewMultipartDecoder creates a new decoder object, which reads multipart
+// data with the boundary from the io.Reader
+func NewMultipartDecoder(r io.Reader, boundary string) *Decoder {
+	return &Decoder{
+		r:        r,
+		boundary: boundary,
+	}
+}
+
+// NewFormDecoder creates a new decoder object, which reads
+// application/x-www-form-urlencoded data from the io.Reader
+func NewFormDecoder(r io.Reader) *Decoder {
+	return &Decoder{
+		r:    r,
+		form: true,
+	}
+}
+
+// NewDecoder creates a new decoder object for a Content-Type header value,
+// which is either application/x-www-form-urlencoded or a multipart type
+// with a boundary parameter
+func NewDecoder(r io.Reader, contentType string) (*Decoder, error) {
+	mimetype, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, httpresponse.ErrBadRequest.Withf("NewDecoder: %v", err)
+	}
+	switch {
+	case mimetype == types.ContentTypeForm:
+		return NewFormDecoder(r), nil
+	case strings.HasPrefix(mimetype, contentTypeMultipart):
+		if params["boundary"] == "" {
+			return nil, httpresponse.ErrBadRequest.Withf("NewDecoder: missing boundary for %q", mimetype)
+		}
+		return NewMultipartDecoder(r, params["boundary"]), nil
+	default:
+		return nil, httpresponse.ErrNotImplemented.Withf("NewDecoder: unsupported content type %q", mimetype)
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Decode reads the data into a struct, using the same field names as the
+// encoder. Repeated values are appended to slices, and files are decoded into
+// File and []File fields. Parts with a JSON content type are unmarshalled
+// into fields which are not strings. Values without a matching field are
+// ignored.
+//
+// Parts of multipart data are matched to fields by their form name or, for
+// parts which are not form data such as those of multipart/mixed, by their
+// Content-ID. It is an error for a part to have neither, unless v is a
+// pointer to []File, in which case every part is decoded in order.
+//
+// The whole of the data is read before Decode returns. The body of each file
+// is held in memory, or in a temporary file when it is larger than 32MB, and
+// the caller should close the body of every file in order to remove any
+// temporary files. Use Parts to read large files without buffering them.
+func (dec *Decoder) Decode(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() && rv.Elem().Type() == fileSliceType && !dec.form {
+		return dec.decodeFiles(rv.Elem())
+	} else if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return httpresponse.ErrBadRequest.With("Decode: not a pointer to a struct")
+	}
+	rv = rv.Elem()
+	fields := decodeFields(rv.Type())
+
+	// Decode form data
+	if dec.form {
+		data, err := io.ReadAll(dec.r)
+		if err != nil {
+			return err
+		}
+		values, err := url.ParseQuery(string(data))
+		if err != nil {
+			return httpresponse.ErrBadRequest.Withf("Decode: %v", err)
+		}
+		var result error
+		for name, values := range values {
+			index, exists := fields[name]
+			if !exists {
+				continue
+			}
+			fv, err := fieldByIndex(rv, index)
+			if err != nil {
+				result = errors.Join(result, err)
+				continue
+			}
+			resetSlice(fv)
+			for _, value := range values {
+				if err := setValue(fv, value); err != nil {
+					result = errors.Join(result, httpresponse.ErrBadRequest.Withf("%q: %v", name, err))
+				}
+			}
+		}
+		return result
+	}
+
+	// Decode multipart data, closing any files on error
+	var files []io.Closer
+	if err := dec.decodeParts(rv, fields, &files); err != nil {
+		for _, file := range files {
+			file.Close()
+		}
+		return err
+	}
+
+	// Return success
+	return nil
+}
+
+// Parts returns an iterator over the parts of multipart data, which streams
+// the body of each part rather than buffering it. The Path of each file is
+// the filename of the part, and the Header holds the part headers, including
+// the Content-Disposition and Content-ID. The body of a part can only be read
+// until the next iteration, and does not need to be closed.
+func (dec *Decoder) Parts() iter.Seq2[File, error] {
+	return func(yield func(File, error) bool) {
+		if dec.form {
+			yield(File{}, httpresponse.ErrBadRequest.With("Parts: not multipart data"))
+			return
+		}
+		reader := multipart.NewReader(dec.r, dec.boundary)
+		for {
+			part, err := reader.NextPart()
+			if errors.Is(err, io.EOF) {
+				return
+			} else if err != nil {
+				yield(File{}, err)
+				return
+			}
+			if !yield(partFile(part, io.NopCloser(part)), nil) {
+				return
+			}
+		}
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// Decode every part of multipart data into a []File in order, closing any
+// files on error
+func (dec *Decoder) decodeFiles(v reflect.Value) error {
+	var files []File
+	for file, err := range dec.Parts() {
+		if err == nil {
+			file.Body, err = spool(file.Body)
+		}
+		if err != nil {
+			for _, file := range files {
+				file.Body.Close()
+			}
+			return err
+		}
+		files = append(files, file)
+	}
+	v.Set(reflect.ValueOf(files))
+	return nil
+}
+
+// Decode each part of multipart data into a field
+func (dec *Decoder) decodeParts(rv reflect.Value, fields map[string][]int, files *[]io.Closer) error {
+	reader := multipart.NewReader(dec.r, dec.boundary)
+	seen := make(map[string]bool)
+	for i := 0; ; i++ {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		// Match parts by form name or Content-ID, and ignore parts without a
+		// matching field
+		name := part.FormName()
+		if name == "" {
+			name = contentID(part)
+		}
+		if name == "" {
+			return httpresponse.ErrBadRequest.Withf("Decode: part %d has no form name or Content-ID", i)
+		}
+		index, exists := fields[name]
+		if !exists {
+			continue
+		}
+		fv, err := fieldByIndex(rv, index)
+		if err != nil {
+			return err
+		}
+		if !seen[name] {
+			resetSlice(fv)
+			seen[name] = true
+		}
+
+		// Decode the part
+		switch {
+		case isFileField(fv.Type()):
+			body, err := spool(part)
+			if err != nil {
+				return err
+			}
+			*files = append(*files, body)
+			setFile(fv, partFile(part, body))
+		case isJSONPart(part) && !isTextField(fv.Type()):
+			if err := json.NewDecoder(part).Decode(fv.Addr().Interface()); err != nil {
+				return httpresponse.ErrBadRequest.Withf("%q: %v", name, err)
+			}
+		default:
+			data, err := io.ReadAll(io.LimitReader(part, maxMemory+1))
+			if err != nil {
+				return err
+			} else if len(data) > maxMemory {
+				return httpresponse.ErrBadRequest.Withf("%q: value too large", name)
+			} else if err := setValue(fv, string(data)); err != nil {
+				return httpresponse.ErrBadRequest.Withf("%q: %v", name, err)
+			}
+		}
+	}
+}
+
+// Return the field index for each field name, using the same tags as the
+// encoder
+func decodeFields(t reflect.Type) map[string][]int {
+	result := make(map[string][]int)
+	for _, field := range reflect.VisibleFields(t) {
+		if field.Anonymous || !field.IsExported() {
+			continue
+		}
+		name, _, skip := fieldName(field)
+		if skip {
+			continue
+		}
+		if _, exists := result[name]; !exists {
+			result[name] = field.Index
+		}
+	}
+	return result
+}
+
+// Return a settable field, allocating any nil embedded struct pointers
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, httpresponse.ErrBadRequest.Withf("Decode: cannot set embedded %v", v.Type())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
+
+// Set a field from a string value. Values are appended to slices other than
+// []byte.
+func setValue(v reflect.Value, value string) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	// Use encoding.TextUnmarshaler when implemented, which includes time.Time
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == durationType {
+			if d, err := time.ParseDuration(value); err == nil {
+				v.SetInt(int64(d))
+				return nil
+			}
+		}
+		n, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			v.SetBytes([]byte(value))
+			return nil
+		}
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if err := setValue(elem, value); err != nil {
+			return err
+		}
+		v.Set(reflect.Append(v, elem))
+	default:
+		return httpresponse.ErrNotImplemented.Withf("unsupported type %v", v.Type())
+	}
+
+	// Return success
+	return nil
+}
+
+// Set a File, *File or []File field
+func setFile(v reflect.Value, file File) {
+	switch v.Type() {
+	case fileType:
+		v.Set(reflect.ValueOf(file))
+	case fileSliceType:
+		v.Set(reflect.Append(v, reflect.ValueOf(file)))
+	default:
+		v.Set(reflect.ValueOf(&file))
+	}
+}
+
+// Clear a slice before decoding values into it
+func resetSlice(v reflect.Value) {
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 {
+		v.SetLen(0)
+	}
+}
+
+// Return true if the type is File, *File or []File
+func isFileField(t reflect.Type) bool {
+	return t == fileType || t == fileSliceType || t == reflect.PointerTo(fileType)
+}
+
+// Return true if the type is decoded from text rather than JSON
+func isTextField(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+	return t.Kind() == reflect.String || (t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8)
+}
+
+// Return a file for a part with a body
+func partFile(part *multipart.Part, body io.ReadCloser) File {
+	path := part.FileName()
+	if v := part.Header.Get(types.ContentPathHeader); v != "" {
+		path = v
+	}
+	return File{
+		Path:        path,
+		Body:        body,
+		ContentType: part.Header.Get(types.ContentTypeHeader),
+		Header:      part.Header,
+	}
+}
+
+// Return the Content-ID of a part without angle brackets
+func contentID(part *multipart.Part) string {
+	return strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(part.Header.Get("Content-ID")), "<"), ">")
+}
+
+// Return true if the part has a JSON content type
+func isJSONPart(part *multipart.Part) bool {
+	mimetype, err := types.ParseContentType(part.Header.Get(types.ContentTypeHeader))
+	return err == nil && mimetype == types.ContentTypeJSON
+}
+
+// Read a file into memory, or into a temporary file when it is larger than
+// maxMemory. The temporary file is removed when the body is closed.
+func spool(r io.Reader) (io.ReadCloser, error) {
+	var buf bytes.Buffer
+	if n, err := io.CopyN(&buf, r, maxMemory+1); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	} else if n <= maxMemory {
+		return io.NopCloser(bytes.NewReader(buf.Bytes())), nil
+	}
+
+	// Write to a temporary file
+	f, err := os.CreateTemp("", "multipart-")
+	if err != nil {
+		return nil, err
+	}
+	file := &tempFile{f}
+	if _, err := buf.WriteTo(f); err != nil {
+		return nil, errors.Join(err, file.Close())
+	} else if _, err := io.Copy(f, r); err != nil {
+		return nil, errors.Join(err, file.Close())
+	} else if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, errors.Join(err, file.Close())
+	}
+	return file, nil
+}
+
+// Close and remove the temporary file
+func (f *tempFile) Close() error {
+	return errors.Join(f.File.Close(), os.Remove(f.Name()))
+}
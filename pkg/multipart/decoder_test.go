@@ -0,0 +1,290 @@
+package multipart
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// TEST TYPES
+
+type TestEmbedded struct {
+	Embedded string `json:"embedded"`
+}
+
+type testResponse struct {
+	*TestEmbedded
+	Name     string            `json:"name"`
+	Count    int               `json:"count"`
+	Ratio    float64           `json:"ratio"`
+	Enabled  *bool             `json:"enabled"`
+	Tags     []string          `json:"tags"`
+	Numbers  []uint            `json:"numbers"`
+	Data     []byte            `json:"data"`
+	Created  time.Time         `json:"created"`
+	Timeout  time.Duration     `json:"timeout"`
+	Meta     map[string]string `json:"meta"`
+	File     File              `json:"file"`
+	Files    []File            `json:"files"`
+	Optional *File             `json:"optional"`
+	Ignored  string            `json:"-"`
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// TESTS
+
+func Test_Decode_Form(t *testing.T) {
+	var resp testResponse
+	body := "name=test&count=42&ratio=0.5&enabled=true&tags=a&tags=b&numbers=1&numbers=2&created=2024-01-02T03:04:05Z&timeout=1m&embedded=yes&Ignored=x&unknown=y"
+	if err := NewFormDecoder(strings.NewReader(body)).Decode(&resp); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if resp.Name != "test" || resp.Count != 42 || resp.Ratio != 0.5 {
+		t.Errorf("unexpected scalar values: %+v", resp)
+	}
+	if resp.Enabled == nil || !*resp.Enabled {
+		t.Error("expected enabled to be true")
+	}
+	if strings.Join(resp.Tags, ",") != "a,b" || len(resp.Numbers) != 2 || resp.Numbers[1] != 2 {
+		t.Errorf("unexpected slice values: %v %v", resp.Tags, resp.Numbers)
+	}
+	if !resp.Created.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("unexpected time: %v", resp.Created)
+	}
+	if resp.Timeout != time.Minute {
+		t.Errorf("unexpected duration: %v", resp.Timeout)
+	}
+	if resp.TestEmbedded == nil || resp.Embedded != "yes" {
+		t.Error("expected embedded field to be set")
+	}
+	if resp.Ignored != "" {
+		t.Error("expected ignored field to be empty")
+	}
+
+	// Invalid values
+	if err := NewFormDecoder(strings.NewReader("count=abc")).Decode(&resp); err == nil {
+		t.Error("expected error for invalid integer")
+	}
+	if err := NewFormDecoder(strings.NewReader("name=test")).Decode(resp); err == nil {
+		t.Error("expected error for non-pointer")
+	}
+}
+
+func Test_Decode_RoundTrip(t *testing.T) {
+	req := testRequest{
+		Name:      "test",
+		Tags:      []string{"a", "b", "c"},
+		MultiTags: []string{"x"},
+		Numbers:   []int{1, 2, 3},
+		ByteData:  []byte("bytes"),
+	}
+
+	// Encode as multipart and decode
+	buf := new(bytes.Buffer)
+	enc := NewMultipartEncoder(buf)
+	if err := enc.Encode(req); err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+	enc.Close()
+	dec, err := NewDecoder(buf, enc.ContentType())
+	if err != nil {
+		t.Fatalf("decoder error: %v", err)
+	}
+	var resp testRequest
+	if err := dec.Decode(&resp); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if resp.Name != req.Name || strings.Join(resp.Tags, ",") != "a,b,c" || len(resp.Numbers) != 3 || string(resp.ByteData) != "bytes" {
+		t.Errorf("unexpected values: %+v", resp)
+	}
+
+	// Encode as a form and decode
+	buf.Reset()
+	enc = NewFormEncoder(buf)
+	if err := enc.Encode(req); err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+	enc.Close()
+	dec, err = NewDecoder(buf, enc.ContentType())
+	if err != nil {
+		t.Fatalf("decoder error: %v", err)
+	}
+	resp = testRequest{Tags: []string{"existing"}}
+	if err := dec.Decode(&resp); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if strings.Join(resp.Tags, ",") != "a,b,c" {
+		t.Errorf("unexpected tags: %v", resp.Tags)
+	}
+}
+
+func Test_Decode_Files(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w := multipart.NewWriter(buf)
+	w.WriteField("name", "test")
+
+	// JSON part
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", `form-data; name="meta"`)
+	h.Set("Content-Type", "application/json; charset=utf-8")
+	part, _ := w.CreatePart(h)
+	part.Write([]byte(`{"key":"value"}`))
+
+	// File parts
+	part, _ = w.CreateFormFile("file", "hello.txt")
+	part.Write([]byte("hello"))
+	for _, name := range []string{"a.txt", "b.txt"} {
+		part, _ = w.CreateFormFile("files", name)
+		part.Write([]byte(name))
+	}
+
+	// Large file is written to a temporary file
+	part, _ = w.CreateFormFile("optional", "large.bin")
+	part.Write(bytes.Repeat([]byte{'x'}, maxMemory+10))
+	w.Close()
+
+	var resp testResponse
+	dec, err := NewDecoder(buf, w.FormDataContentType())
+	if err != nil {
+		t.Fatalf("decoder error: %v", err)
+	}
+	if err := dec.Decode(&resp); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if resp.Name != "test" || resp.Meta["key"] != "value" {
+		t.Errorf("unexpected values: %v %v", resp.Name, resp.Meta)
+	}
+	if resp.File.Path != "hello.txt" || readAll(t, resp.File.Body) != "hello" {
+		t.Errorf("unexpected file: %v", resp.File.Path)
+	}
+	if len(resp.Files) != 2 || resp.Files[1].Path != "b.txt" || readAll(t, resp.Files[1].Body) != "b.txt" {
+		t.Errorf("unexpected files: %v", resp.Files)
+	}
+	if resp.Optional == nil {
+		t.Fatal("expected optional file")
+	}
+	if n := len(readAll(t, resp.Optional.Body)); n != maxMemory+10 {
+		t.Errorf("unexpected large file size: %v", n)
+	}
+}
+
+func Test_Decode_ContentType(t *testing.T) {
+	if _, err := NewDecoder(strings.NewReader(""), "multipart/form-data"); err == nil {
+		t.Error("expected error for missing boundary")
+	}
+	if _, err := NewDecoder(strings.NewReader(""), "application/json"); err == nil {
+		t.Error("expected error for unsupported content type")
+	}
+	if _, err := NewDecoder(strings.NewReader(""), "multipart/mixed; boundary=abc"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func Test_Decode_Mixed(t *testing.T) {
+	body, contentType := mixedBody(t, "<meta>", "file", "")
+
+	// Parts are matched by Content-ID
+	var resp testResponse
+	dec, err := NewDecoder(strings.NewReader(body), contentType)
+	if err != nil {
+		t.Fatalf("decoder error: %v", err)
+	}
+	if err := dec.Decode(&resp); err == nil {
+		t.Error("expected error for a part without a Content-ID")
+	}
+	body, contentType = mixedBody(t, "<meta>", "file")
+	dec, _ = NewDecoder(strings.NewReader(body), contentType)
+	if err := dec.Decode(&resp); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if resp.Meta["key"] != "value" || readAll(t, resp.File.Body) != "part 1" {
+		t.Errorf("unexpected values: %v", resp)
+	}
+
+	// Every part is decoded into a []File in order
+	body, contentType = mixedBody(t, "<meta>", "file", "")
+	var files []File
+	dec, _ = NewDecoder(strings.NewReader(body), contentType)
+	if err := dec.Decode(&files); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if len(files) != 3 || files[2].Header.Get("Content-ID") != "" || readAll(t, files[2].Body) != "part 2" {
+		t.Errorf("unexpected files: %v", files)
+	}
+}
+
+func Test_Decode_Parts(t *testing.T) {
+	body, contentType := mixedBody(t, "<meta>", "file", "")
+	dec, err := NewDecoder(strings.NewReader(body), contentType)
+	if err != nil {
+		t.Fatalf("decoder error: %v", err)
+	}
+
+	// Parts are streamed in order
+	var values []string
+	for file, err := range dec.Parts() {
+		if err != nil {
+			t.Fatalf("part error: %v", err)
+		}
+		data, err := io.ReadAll(file.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		values = append(values, string(data))
+	}
+	if strings.Join(values, ",") != `{"key":"value"},part 1,part 2` {
+		t.Errorf("unexpected parts: %v", values)
+	}
+
+	// Form data cannot be iterated
+	for _, err := range NewFormDecoder(strings.NewReader("a=b")).Parts() {
+		if err == nil {
+			t.Error("expected error for form data")
+		}
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// mixedBody returns a multipart/mixed body with a part for each Content-ID,
+// and its content type. The first part is JSON.
+func mixedBody(t *testing.T, ids ...string) (string, string) {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	w := multipart.NewWriter(buf)
+	for i, id := range ids {
+		h := make(textproto.MIMEHeader)
+		if id != "" {
+			h.Set("Content-ID", id)
+		}
+		value := "part " + strconv.Itoa(i)
+		if i == 0 {
+			h.Set("Content-Type", "application/json")
+			value = `{"key":"value"}`
+		}
+		part, err := w.CreatePart(h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write([]byte(value))
+	}
+	w.Close()
+	return buf.String(), "multipart/mixed; boundary=" + w.Boundary()
+}
+
+func readAll(t *testing.T, r io.ReadCloser) string {
+	t.Helper()
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
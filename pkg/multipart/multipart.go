@@ -1,6 +1,7 @@
 package multipart
 
 import (
+	"encoding"
 	"errors"
 	"fmt"
 	"io"
@@ -10,7 +11,9 @@ import (
 	"path/filepath"
 	"reflect"
 	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	// Packages
 	httpresponse "github.com/mutablelogic/go-server/pkg/httpresponse"
@@ -22,13 +25,20 @@ import (
 
 // Encoder is a multipart encoder object
 type Encoder struct {
-	w io.Writer
-	m *multipart.Writer
-	v url.Values
+	w        io.Writer
+	m        *multipart.Writer
+	v        url.Values
+	dot      bool
+	brackets bool
 }
 
-// File is a file object, which is used to encode a file in a multipart request.
-// The definition lives in go-server/pkg/types; this alias keeps the go-client
+// EncoderOpt is an option which changes how an encoder names fields
+type EncoderOpt func(*Encoder)
+
+// File is a file object, which is used to encode a file in a multipart request
+// and which files are decoded into. A File returned by Decoder.Decode has a
+// Body held in memory or in a temporary file, which the caller must close to
+// remove. A File returned by Decoder.Parts streams its Body. The definition lives in go-server/pkg/types; this alias keeps the go-client
 // API stable.
 type File = types.File
 
@@ -37,12 +47,15 @@ type File = types.File
 
 const (
 	defaultTag     = "json"
+	formTag        = "form"
 	omitemptyValue = "omitempty"
 )
 
 var (
-	fileType      = reflect.TypeOf(types.File{})
-	fileSliceType = reflect.TypeOf([]types.File{})
+	fileType          = reflect.TypeOf(types.File{})
+	fileSliceType     = reflect.TypeOf([]types.File{})
+	timeType          = reflect.TypeOf(time.Time{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
 )
 
 ///////////////////////////////////////////////////////////////////////////////
@@ -50,26 +63,57 @@ var (
 
 // NewMultipartEncoder creates a new encoder object, which writes
 // multipart/form-data to the io.Writer
-func NewMultipartEncoder(w io.Writer) *Encoder {
-	return &Encoder{
+func NewMultipartEncoder(w io.Writer, opts ...EncoderOpt) *Encoder {
+	enc := &Encoder{
 		m: multipart.NewWriter(w),
 	}
+	for _, opt := range opts {
+		opt(enc)
+	}
+	return enc
 }
 
 // NewFormEncoder creates a new encoder object, which writes
 // application/x-www-form-urlencoded to the io.Writer
-func NewFormEncoder(w io.Writer) *Encoder {
-	return &Encoder{
+func NewFormEncoder(w io.Writer, opts ...EncoderOpt) *Encoder {
+	enc := &Encoder{
 		w: w,
 		v: make(url.Values),
 	}
+	for _, opt := range opts {
+		opt(enc)
+	}
+	return enc
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// OPTIONS
+
+// OptDotNotation names nested fields with dots (a.b=1) rather than
+// brackets (a[b]=1)
+func OptDotNotation() EncoderOpt {
+	return func(enc *Encoder) {
+		enc.dot = true
+	}
+}
+
+// OptArrayBrackets names the values of a slice with a bracket suffix
+// (items[]=1&items[]=2) rather than repeating the name (items=1&items=2)
+func OptArrayBrackets() EncoderOpt {
+	return func(enc *Encoder) {
+		enc.brackets = true
+	}
 }
 
 ///////////////////////////////////////////////////////////////////////////////
 // PUBLIC METHODS
 
 // Encode writes the struct to the multipart writer, including any File objects
-// which are added as form data and excluding any fields with a tag of json:"-"
+// which are added as form data and excluding any fields with a tag of json:"-".
+// The form tag is used in preference to the json tag when set. Nested structs
+// and maps are written with bracket or dot notation, times are written in
+// RFC 3339 format and fields which implement encoding.TextMarshaler are
+// written as text.
 func (enc *Encoder) Encode(v any) error {
 	rv := reflect.ValueOf(v)
 	if rv.Kind() == reflect.Pointer {
@@ -78,82 +122,7 @@ func (enc *Encoder) Encode(v any) error {
 	if rv.Kind() != reflect.Struct {
 		return httpresponse.ErrBadRequest.With("Encode: not a struct")
 	}
-
-	// Iterate over visible fields
-	var result error
-	var ignore [][]int
-	for _, field := range reflect.VisibleFields(rv.Type()) {
-		if field.Type.Kind() == reflect.Ptr {
-			if fv := rv.FieldByIndex(field.Index); fv.IsNil() {
-				// If the field is a pointer and the value is nil, we need to ignore children
-				ignore = append(ignore, field.Index)
-			}
-		}
-
-		if field.Anonymous {
-			// Don't process anonymous fields
-			continue
-		}
-
-		// Set the field name
-		name := field.Name
-		omitempty := false
-
-		// Modify the field name if there is a tag
-		if tag := field.Tag.Get(defaultTag); tag != "" {
-			// Ignore field if tag is "-"
-			if tag == "-" {
-				continue
-			}
-
-			// Set name if first tuple is not empty
-			tuples := strings.Split(tag, ",")
-			if tuples[0] != "" {
-				name = tuples[0]
-			}
-
-			// Check for omitempty tag
-			if slices.Contains(tuples, omitemptyValue) {
-				omitempty = true
-			}
-		}
-
-		// Skip ignored children
-		if hasParentIndex(ignore, field.Index) {
-			continue
-		}
-
-		// Skip invalid or empty fields
-		fv := rv.FieldByIndex(field.Index)
-		if omitempty && fv.IsZero() {
-			continue
-		}
-
-		// Write field
-		if fv.Kind() == reflect.Ptr {
-			if fv.IsNil() {
-				continue
-			}
-			fv = fv.Elem()
-		}
-		if fv.Type() == fileType {
-			if err := enc.writeFileField(name, fv.Interface().(File)); err != nil {
-				result = errors.Join(result, err)
-			}
-		} else if fv.Type() == fileSliceType {
-			// Write each file in the slice as a separate part under the same field name.
-			for _, f := range fv.Interface().([]File) {
-				if err := enc.writeFileField(name, f); err != nil {
-					result = errors.Join(result, err)
-				}
-			}
-		} else if err := enc.writeField(name, fv.Interface()); err != nil {
-			result = errors.Join(result, err)
-		}
-	}
-
-	// Return success
-	return result
+	return enc.writeStruct("", rv)
 }
 
 // Return the MIME content type of the data
@@ -240,66 +209,201 @@ func (enc *Encoder) writeFileField(name string, value File) error {
 	return err
 }
 
-// Write a field as a string
-func (enc *Encoder) writeField(name string, value any) error {
-	rv := reflect.ValueOf(value)
+// Write the fields of a struct, with names prefixed by the parent name
+func (enc *Encoder) writeStruct(prefix string, rv reflect.Value) error {
+	var result error
+	var ignore [][]int
+	for _, field := range reflect.VisibleFields(rv.Type()) {
+		if field.Type.Kind() == reflect.Ptr {
+			if fv := rv.FieldByIndex(field.Index); fv.IsNil() {
+				// If the field is a pointer and the value is nil, we need to ignore children
+				ignore = append(ignore, field.Index)
+			}
+		}
+
+		// Don't process anonymous or unexported fields
+		if field.Anonymous || !field.IsExported() {
+			continue
+		}
+
+		// Get the field name
+		name, omitempty, skip := fieldName(field)
+		if skip {
+			continue
+		}
 
-	// Dereference pointers
-	if rv.Kind() == reflect.Ptr {
+		// Skip ignored children
+		if hasParentIndex(ignore, field.Index) {
+			continue
+		}
+
+		// Skip empty fields
+		fv := rv.FieldByIndex(field.Index)
+		if omitempty && fv.IsZero() {
+			continue
+		}
+
+		// Write field
+		if err := enc.writeValue(enc.key(prefix, name), fv); err != nil {
+			result = errors.Join(result, err)
+		}
+	}
+
+	// Return any errors
+	return result
+}
+
+// Write a value, which may be a file, slice, struct or map
+func (enc *Encoder) writeValue(name string, rv reflect.Value) error {
+	// Dereference pointers and interfaces
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
 		if rv.IsNil() {
-			return nil // Ignore nil pointers
+			return nil // Ignore nil values
 		}
 		rv = rv.Elem()
 	}
 
-	// Write slices/arrays as repeated form fields (except []byte which should
-	// remain a single value).
-	if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+	// Files, times and text values
+	switch {
+	case rv.Type() == fileType:
+		return enc.writeFileField(name, rv.Interface().(File))
+	case rv.Type() == fileSliceType:
+		// Write each file in the slice as a separate part under the same field name.
+		var result error
+		for _, f := range rv.Interface().([]File) {
+			if err := enc.writeFileField(name, f); err != nil {
+				result = errors.Join(result, err)
+			}
+		}
+		return result
+	case rv.Type() == timeType:
+		return enc.writeField(name, rv.Interface().(time.Time).Format(time.RFC3339))
+	}
+	if text, ok, err := marshalText(rv); err != nil {
+		return err
+	} else if ok {
+		return enc.writeField(name, text)
+	}
+
+	switch rv.Kind() {
+	case reflect.Struct:
+		return enc.writeStruct(name, rv)
+	case reflect.Map:
+		// Write map values in key order
+		keys := rv.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int {
+			return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+		})
+		var result error
+		for _, key := range keys {
+			if err := enc.writeValue(enc.key(name, fmt.Sprint(key)), rv.MapIndex(key)); err != nil {
+				result = errors.Join(result, err)
+			}
+		}
+		return result
+	case reflect.Slice, reflect.Array:
+		// Treat []byte and [N]byte as a single scalar value
 		if rv.Type().Elem().Kind() == reflect.Uint8 {
-			// Treat []byte and [N]byte as a single scalar value (convert to string)
-			// Use efficient conversion: directly get bytes or convert via interface
-			var byteStr string
 			if rv.Kind() == reflect.Slice {
-				byteStr = string(rv.Bytes())
-			} else {
-				// For byte arrays, manually iterate (arrays can't be sliced in reflect)
-				var byteSlice []byte
-				for i := 0; i < rv.Len(); i++ {
-					byteSlice = append(byteSlice, rv.Index(i).Interface().(byte))
-				}
-				byteStr = string(byteSlice)
+				return enc.writeField(name, string(rv.Bytes()))
 			}
-			rv = reflect.ValueOf(byteStr)
-		} else {
-			// Empty slices don't produce form fields
-			if rv.Len() == 0 {
-				return nil
+			data := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(data), rv)
+			return enc.writeField(name, string(data))
+		}
+
+		// Structs and maps in slices are indexed, other values use the same
+		// name for each value. Empty slices don't produce form fields.
+		var result error
+		for i := 0; i < rv.Len(); i++ {
+			key := name
+			if isNested(rv.Type().Elem()) {
+				key = enc.key(name, strconv.Itoa(i))
+			} else if enc.brackets {
+				key = name + "[]"
 			}
-			// Iterate over all elements and write each as a separate form field.
-			var result error
-			for i := 0; i < rv.Len(); i++ {
-				if err := enc.writeField(name, rv.Index(i).Interface()); err != nil {
-					result = errors.Join(result, err)
-				}
+			if err := enc.writeValue(key, rv.Index(i)); err != nil {
+				result = errors.Join(result, err)
 			}
-			return result
 		}
+		return result
+	default:
+		return enc.writeField(name, fmt.Sprint(rv))
 	}
+}
 
-	// Write the field value as a string
+// Write a field as a string
+func (enc *Encoder) writeField(name, value string) error {
 	switch {
 	case enc.m != nil:
-		if err := enc.m.WriteField(name, fmt.Sprint(rv)); err != nil {
-			return err
-		}
+		return enc.m.WriteField(name, value)
 	case enc.v != nil:
-		enc.v.Add(name, fmt.Sprint(rv))
+		enc.v.Add(name, value)
+		return nil
 	default:
 		return httpresponse.ErrNotImplemented
 	}
+}
 
-	// Return success
-	return nil
+// Return the name of a nested field
+func (enc *Encoder) key(prefix, name string) string {
+	switch {
+	case prefix == "":
+		return name
+	case enc.dot:
+		return prefix + "." + name
+	default:
+		return prefix + "[" + name + "]"
+	}
+}
+
+// Return the name of a field from the form or json tag, whether the field
+// is omitted when empty, and whether the field should be skipped
+func fieldName(field reflect.StructField) (string, bool, bool) {
+	tag, exists := field.Tag.Lookup(formTag)
+	if !exists {
+		tag = field.Tag.Get(defaultTag)
+	}
+	if tag == "-" {
+		return "", false, true
+	}
+	tuples := strings.Split(tag, ",")
+	name := field.Name
+	if tuples[0] != "" {
+		name = tuples[0]
+	}
+	return name, slices.Contains(tuples[1:], omitemptyValue), false
+}
+
+// Return the text for a value which implements encoding.TextMarshaler, and
+// false if the value does not
+func marshalText(rv reflect.Value) (string, bool, error) {
+	if !rv.CanAddr() {
+		v := reflect.New(rv.Type()).Elem()
+		v.Set(rv)
+		rv = v
+	}
+	ptr := rv.Addr()
+	if ptr.Type().Implements(textMarshalerType) {
+		text, err := ptr.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), true, err
+	}
+	return "", false, nil
+}
+
+// Return true if values of the type are written as nested fields
+func isNested(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Map:
+		return true
+	case reflect.Struct:
+		return t != fileType && t != timeType && !reflect.PointerTo(t).Implements(textMarshalerType)
+	default:
+		return false
+	}
 }
 
 // Check field index for a parent, which should be ignored
@@ -2,12 +2,17 @@ package multipart
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
 	"io"
 	"mime"
 	"mime/multipart"
 	"net/textproto"
+	"net/url"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 ///////////////////////////////////////////////////////////////////////////////
@@ -434,3 +439,125 @@ func parseMultipart(t *testing.T, buf *bytes.Buffer, contentType string) []parse
 	}
 	return parts
 }
+
+///////////////////////////////////////////////////////////////////////////////
+// NESTED FIELDS
+
+type testAddress struct {
+	Line1 string `json:"line1"`
+	City  string `json:"city,omitempty"`
+}
+
+// String returns indented JSON, like the schema types in this repository,
+// and does not change how the address is encoded
+func (a testAddress) String() string {
+	data, _ := json.MarshalIndent(a, "", "  ")
+	return string(data)
+}
+
+type testLevel int
+
+func (l testLevel) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("level-%d", int(l))), nil
+}
+
+type testNested struct {
+	Name     string            `form:"customer_name" json:"name"`
+	Skip     string            `form:"-" json:"skip"`
+	Address  testAddress       `json:"address"`
+	Optional *testAddress      `json:"optional,omitempty"`
+	Metadata map[string]string `json:"metadata"`
+	Items    []testAddress     `json:"items"`
+	Tags     []string          `json:"tags"`
+	Created  time.Time         `json:"created"`
+	Expires  *time.Time        `json:"expires,omitempty"`
+	Level    testLevel         `json:"level"`
+}
+
+func Test_Form_Nested(t *testing.T) {
+	req := testNested{
+		Name:     "test",
+		Skip:     "skip",
+		Address:  testAddress{Line1: "1 Main St"},
+		Metadata: map[string]string{"b": "2", "a": "1"},
+		Items:    []testAddress{{Line1: "x", City: "y"}},
+		Tags:     []string{"a", "b"},
+		Created:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Level:    3,
+	}
+
+	// Bracket notation with repeated keys
+	buf := new(bytes.Buffer)
+	enc := NewFormEncoder(buf)
+	if err := enc.Encode(req); err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+	enc.Close()
+	values, err := url.ParseQuery(buf.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := url.Values{
+		"customer_name":   {"test"},
+		"address[line1]":  {"1 Main St"},
+		"metadata[a]":     {"1"},
+		"metadata[b]":     {"2"},
+		"items[0][line1]": {"x"},
+		"items[0][city]":  {"y"},
+		"tags":            {"a", "b"},
+		"created":         {"2024-01-02T03:04:05Z"},
+		"level":           {"level-3"},
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("unexpected values:\n got %v\nwant %v", values, expected)
+	}
+
+	// Dot notation with array brackets
+	buf.Reset()
+	enc = NewFormEncoder(buf, OptDotNotation(), OptArrayBrackets())
+	if err := enc.Encode(req); err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+	enc.Close()
+	values, err = url.ParseQuery(buf.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"address.line1", "metadata.a", "items.0.line1", "tags[]"} {
+		if !values.Has(key) {
+			t.Errorf("missing key %q in %v", key, values)
+		}
+	}
+	if strings.Join(values["tags[]"], ",") != "a,b" {
+		t.Errorf("unexpected tags: %v", values["tags[]"])
+	}
+}
+
+func Test_Multipart_Nested(t *testing.T) {
+	req := testNested{
+		Name:    "test",
+		Address: testAddress{Line1: "1 Main St", City: "Town"},
+	}
+	buf := new(bytes.Buffer)
+	enc := NewMultipartEncoder(buf)
+	if err := enc.Encode(req); err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+	enc.Close()
+
+	parts := parseMultipart(t, buf, enc.ContentType())
+	names := make(map[string]string)
+	for _, part := range parts {
+		_, params, err := mime.ParseMediaType(part.Header.Get("Content-Disposition"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		names[params["name"]] = string(part.Body)
+	}
+	if names["customer_name"] != "test" || names["address[line1]"] != "1 Main St" || names["address[city]"] != "Town" {
+		t.Errorf("unexpected parts: %v", names)
+	}
+	if _, exists := names["optional[line1]"]; exists {
+		t.Error("nil nested struct should not produce fields")
+	}
+}
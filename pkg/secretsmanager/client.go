@@ -0,0 +1,171 @@
+/*
+secretsmanager implements an API client for Bitwarden Secrets Manager,
+which authenticates with a machine account access token.
+
+References:
+
+	https://bitwarden.com/help/secrets-manager-overview/
+	https://github.com/bitwarden/sdk-sm
+*/
+package secretsmanager
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	// Packages
+	client "github.com/mutablelogic/go-client"
+	crypto "github.com/mutablelogic/go-client/pkg/bitwarden/crypto"
+	schema "github.com/mutablelogic/go-client/pkg/bitwarden/schema"
+	types "github.com/mutablelogic/go-server/pkg/types"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+type Client struct {
+	sync.Mutex
+	client   *client.Client
+	identity string
+	token    *accessToken
+
+	// Session, set on login
+	session      *schema.Token
+	organization string
+	key          *crypto.CryptoKey
+}
+
+type reqToken struct {
+	GrantType    string `json:"grant_type"`
+	Scope        string `json:"scope"`
+	ClientId     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+}
+
+type respToken struct {
+	schema.Token
+	Scope            string `json:"scope"`
+	EncryptedPayload string `json:"encrypted_payload"`
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// GLOBALS
+
+const (
+	baseUrl     = "https://api.bitwarden.com"
+	identityUrl = "https://identity.bitwarden.com"
+)
+
+const (
+	defaultScope               = "api.secrets"
+	defaultGrantType           = "client_credentials"
+	defaultClientVersionHeader = "2026.4.0"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// LIFECYCLE
+
+// New creates a Secrets Manager client with a machine account access token.
+// Use client.OptEndpoint and OptIdentity to use a self-hosted or regional
+// server.
+func New(accessToken string, opts ...client.ClientOpt) (*Client, error) {
+	parent := new(Client)
+	parent.identity = identityUrl
+
+	// Parse the access token
+	if token, err := parseAccessToken(accessToken); err != nil {
+		return nil, err
+	} else {
+		parent.token = token
+	}
+
+	// Create client
+	opts_ := []client.ClientOpt{
+		client.OptEndpoint(baseUrl),
+		client.OptParent(parent),
+		client.OptHeader("Bitwarden-Client-Version", defaultClientVersionHeader),
+	}
+	if client, err := client.New(append(opts_, opts...)...); err != nil {
+		return nil, err
+	} else {
+		parent.client = client
+	}
+
+	// Return the client
+	return parent, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Login requests a session token and decrypts the organization key. It is
+// called automatically when the session has expired, but can be called to
+// check the access token is valid.
+func (c *Client) Login(ctx context.Context) error {
+	c.Lock()
+	defer c.Unlock()
+	return c.login(ctx)
+}
+
+// Organization returns the organization for the machine account, or empty
+// string if not logged in
+func (c *Client) Organization() string {
+	c.Lock()
+	defer c.Unlock()
+	return c.organization
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+func (c *Client) login(ctx context.Context) error {
+	var response respToken
+
+	// Request -> Response
+	if payload, err := client.NewFormRequest(reqToken{
+		GrantType:    defaultGrantType,
+		Scope:        defaultScope,
+		ClientId:     c.token.ClientId,
+		ClientSecret: c.token.ClientSecret,
+	}, types.ContentTypeJSON); err != nil {
+		return err
+	} else if err := c.client.DoWithContext(ctx, payload, &response, client.OptReqEndpoint(c.identity), client.OptPath("connect", "token")); err != nil {
+		return err
+	}
+
+	// Decrypt the organization key
+	key, err := c.token.decryptPayload(response.EncryptedPayload)
+	if err != nil {
+		return err
+	}
+	organization, err := organizationClaim(response.AccessToken)
+	if err != nil {
+		return err
+	}
+
+	// Set the session
+	c.session = &response.Token
+	c.session.CreatedAt = time.Now()
+	c.organization = organization
+	c.key = key
+
+	// Return success
+	return nil
+}
+
+// Login if the session is not valid, and return the options for the request
+// token, the organization and key
+func (c *Client) auth(ctx context.Context) (client.RequestOpt, string, *crypto.CryptoKey, error) {
+	c.Lock()
+	defer c.Unlock()
+	if c.session == nil || !c.session.IsValid() {
+		if err := c.login(ctx); err != nil {
+			return nil, "", nil, err
+		}
+	}
+	return client.OptToken(client.Token{
+		Scheme: c.session.TokenType,
+		Value:  c.session.AccessToken,
+	}), c.organization, c.key, nil
+}
@@ -0,0 +1,132 @@
+package secretsmanager_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	// Packages
+	goclient "github.com/mutablelogic/go-client"
+	crypto "github.com/mutablelogic/go-client/pkg/bitwarden/crypto"
+	secretsmanager "github.com/mutablelogic/go-client/pkg/secretsmanager"
+	assert "github.com/stretchr/testify/assert"
+)
+
+const (
+	// Access token and derived key from the Bitwarden SDK test vectors
+	accessToken = "0.ec2c1d46-6a4b-4751-a310-af9601317f2d.C2IgxjjLF7qSshsbwe8JGcbM075YXw:X8vbvA0bduihIDe/qrzIQQ=="
+	derivedKey  = "H9/oIRLtL9nGCQOVDjSMoEbJsjWXSOCb3qeyDt6ckzS3FhyboEDWyTP/CQfbIszNmAVg2ExFganG1FVFGXO/Jg=="
+)
+
+func Test_client_001(t *testing.T) {
+	assert := assert.New(t)
+
+	// Invalid access tokens
+	for _, token := range []string{
+		"",
+		"0.ec2c1d46-6a4b-4751-a310-af9601317f2d.C2IgxjjLF7qSshsbwe8JGcbM075YXw",
+		"1.ec2c1d46-6a4b-4751-a310-af9601317f2d.C2IgxjjLF7qSshsbwe8JGcbM075YXw:X8vbvA0bduihIDe/qrzIQQ==",
+		"0.ec2c1d46-6a4b-4751-a310-af9601317f2d:X8vbvA0bduihIDe/qrzIQQ==",
+		"0.ec2c1d46-6a4b-4751-a310-af9601317f2d.C2IgxjjLF7qSshsbwe8JGcbM075YXw:X8vbvA0bduihIDe",
+	} {
+		_, err := secretsmanager.New(token)
+		assert.Error(err, token)
+	}
+
+	// Valid access token
+	client, err := secretsmanager.New(accessToken)
+	assert.NoError(err)
+	assert.NotNil(client)
+}
+
+func Test_client_002(t *testing.T) {
+	assert := assert.New(t)
+
+	// Keys for the access token and the organization
+	data, err := base64.StdEncoding.DecodeString(derivedKey)
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+	tokenKey := crypto.NewKey(data[:32], data[32:])
+	orgKey := make([]byte, 64)
+	for i := range orgKey {
+		orgKey[i] = byte(i)
+	}
+	key := crypto.NewKey(orgKey[:32], orgKey[32:])
+
+	// Mock the identity and api servers
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/identity/connect/token":
+			assert.NoError(r.ParseForm())
+			assert.Equal("client_credentials", r.Form.Get("grant_type"))
+			assert.Equal("api.secrets", r.Form.Get("scope"))
+			assert.Equal("ec2c1d46-6a4b-4751-a310-af9601317f2d", r.Form.Get("client_id"))
+			assert.Equal("C2IgxjjLF7qSshsbwe8JGcbM075YXw", r.Form.Get("client_secret"))
+			payload, _ := json.Marshal(map[string]string{"encryptionKey": base64.StdEncoding.EncodeToString(orgKey)})
+			encrypted, err := tokenKey.Encrypt(payload)
+			assert.NoError(err)
+			claims := base64.RawURLEncoding.EncodeToString([]byte(`{"organization":"org1"}`))
+			json.NewEncoder(w).Encode(map[string]any{
+				"access_token":      "header." + claims + ".signature",
+				"expires_in":        3600,
+				"token_type":        "Bearer",
+				"encrypted_payload": encrypted.String(),
+			})
+		case "/api/secrets/secret1":
+			assert.Equal("Bearer header.eyJvcmdhbml6YXRpb24iOiJvcmcxIn0.signature", r.Header.Get("Authorization"))
+			name, _ := key.EncryptStr("DATABASE_URL")
+			value, _ := key.EncryptStr("postgres://localhost")
+			json.NewEncoder(w).Encode(map[string]any{
+				"id":             "secret1",
+				"organizationId": "org1",
+				"key":            name,
+				"value":          value,
+				"projects":       []any{map[string]string{"id": "project1"}},
+			})
+		case "/api/organizations/org1/secrets":
+			// Every field is encrypted, including the empty note
+			var request map[string]any
+			assert.NoError(json.NewDecoder(r.Body).Decode(&request))
+			for _, field := range []string{"key", "value", "note"} {
+				value, _ := request[field].(string)
+				assert.NotEmpty(value, field)
+				_, err := crypto.NewEncrypted(value)
+				assert.NoError(err, field)
+			}
+			request["id"] = "secret2"
+			request["organizationId"] = "org1"
+			json.NewEncoder(w).Encode(request)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	// Login and get a secret
+	client, err := secretsmanager.New(accessToken, secretsmanager.OptIdentity(server.URL+"/identity"), goclient.OptEndpoint(server.URL+"/api"))
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+	secret, err := client.Secret(context.Background(), "secret1")
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+	assert.Equal("org1", client.Organization())
+	assert.Equal("DATABASE_URL", secret.Key)
+	assert.Equal("postgres://localhost", secret.Value)
+	assert.Equal("project1", secret.ProjectId)
+
+	// Create a secret without a note
+	secret, err = client.CreateSecret(context.Background(), "project1", "API_KEY", "value", "")
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+	assert.Equal("API_KEY", secret.Key)
+	assert.Equal("value", secret.Value)
+	assert.Equal("", secret.Note)
+}
@@ -0,0 +1,30 @@
+package secretsmanager
+
+import (
+	"net/url"
+
+	// Packages
+	client "github.com/mutablelogic/go-client"
+
+	// Namespace imports
+	. "github.com/djthorpe/go-errors"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// CLIENT OPTIONS
+
+// Set the identity server endpoint, for self-hosted or regional servers
+func OptIdentity(endpoint string) client.ClientOpt {
+	return func(c *client.Client) error {
+		if u, err := url.Parse(endpoint); err != nil {
+			return err
+		} else if u.Scheme == "" || u.Host == "" {
+			return ErrBadParameter.Withf("OptIdentity: %q", endpoint)
+		} else if c, ok := c.Parent.(*Client); !ok {
+			return ErrBadParameter.With("OptIdentity")
+		} else {
+			c.identity = endpoint
+		}
+		return nil
+	}
+}
@@ -0,0 +1,184 @@
+package secretsmanager
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	// Packages
+	client "github.com/mutablelogic/go-client"
+	types "github.com/mutablelogic/go-server/pkg/types"
+
+	// Namespace imports
+	. "github.com/djthorpe/go-errors"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+type Project struct {
+	Id             string    `json:"id,width:36"`
+	OrganizationId string    `json:"organizationId,width:36"`
+	Name           string    `json:"name,width:30"` // crypt
+	CreationDate   time.Time `json:"creationDate,width:29"`
+	RevisionDate   time.Time `json:"revisionDate,width:29"`
+}
+
+type reqProject struct {
+	Name string `json:"name"`
+}
+
+type respProjects struct {
+	Data []*Project `json:"data"`
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// STRINGIFY
+
+func (p Project) String() string {
+	data, _ := json.MarshalIndent(p, "", "  ")
+	return string(data)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Projects returns the projects the machine account has access to
+func (c *Client) Projects(ctx context.Context) ([]*Project, error) {
+	var response respProjects
+	token, organization, key, err := c.auth(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Request -> Response
+	if err := c.client.DoWithContext(ctx, nil, &response, client.OptPath("organizations", organization, "projects"), token); err != nil {
+		return nil, err
+	}
+
+	// Decrypt the projects
+	for _, project := range response.Data {
+		if err := project.crypt(key.DecryptStr); err != nil {
+			return nil, err
+		}
+	}
+
+	// Return success
+	return response.Data, nil
+}
+
+// Project returns a project by identifier
+func (c *Client) Project(ctx context.Context, id string) (*Project, error) {
+	var response Project
+	if id == "" {
+		return nil, ErrBadParameter.With("Project")
+	}
+	token, _, key, err := c.auth(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Request -> Response
+	if err := c.client.DoWithContext(ctx, nil, &response, client.OptPath("projects", id), token); err != nil {
+		return nil, err
+	} else if err := response.crypt(key.DecryptStr); err != nil {
+		return nil, err
+	}
+
+	// Return success
+	return &response, nil
+}
+
+// CreateProject creates a new project in the organization
+func (c *Client) CreateProject(ctx context.Context, name string) (*Project, error) {
+	if name == "" {
+		return nil, ErrBadParameter.With("CreateProject")
+	}
+	return c.writeProject(ctx, http.MethodPost, "", name)
+}
+
+// UpdateProject renames a project
+func (c *Client) UpdateProject(ctx context.Context, id, name string) (*Project, error) {
+	if id == "" || name == "" {
+		return nil, ErrBadParameter.With("UpdateProject")
+	}
+	return c.writeProject(ctx, http.MethodPut, id, name)
+}
+
+// DeleteProjects deletes one or more projects
+func (c *Client) DeleteProjects(ctx context.Context, ids ...string) error {
+	return c.delete(ctx, "projects", ids...)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// Create a project when id is empty, or else update a project
+func (c *Client) writeProject(ctx context.Context, method, id, name string) (*Project, error) {
+	var response Project
+	token, organization, key, err := c.auth(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Encrypt the name
+	name, err = key.EncryptStr(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Request -> Response
+	path := []any{"projects", id}
+	if method == http.MethodPost {
+		path = []any{"organizations", organization, "projects"}
+	}
+	if payload, err := client.NewJSONRequestEx(method, reqProject{name}, types.ContentTypeJSON); err != nil {
+		return nil, err
+	} else if err := c.client.DoWithContext(ctx, payload, &response, client.OptPath(path...), token); err != nil {
+		return nil, err
+	} else if err := response.crypt(key.DecryptStr); err != nil {
+		return nil, err
+	}
+
+	// Return success
+	return &response, nil
+}
+
+// Bulk delete projects or secrets, returning the first error reported by the
+// server
+func (c *Client) delete(ctx context.Context, kind string, ids ...string) error {
+	var response struct {
+		Data []struct {
+			Id    string `json:"id"`
+			Error string `json:"error"`
+		} `json:"data"`
+	}
+	if len(ids) == 0 {
+		return ErrBadParameter.With("missing identifiers")
+	}
+	token, _, _, err := c.auth(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Request -> Response
+	if payload, err := client.NewJSONRequest(ids); err != nil {
+		return err
+	} else if err := c.client.DoWithContext(ctx, payload, &response, client.OptPath(kind, "delete"), token); err != nil {
+		return err
+	}
+	for _, result := range response.Data {
+		if result.Error != "" {
+			return ErrBadParameter.Withf("%s: %s", result.Id, result.Error)
+		}
+	}
+
+	// Return success
+	return nil
+}
+
+// Encrypt or decrypt the project name in place
+func (p *Project) crypt(fn func(string) (string, error)) error {
+	return cryptStrings(fn, &p.Name)
+}
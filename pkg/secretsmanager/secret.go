@@ -0,0 +1,246 @@
+package secretsmanager
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	// Packages
+	client "github.com/mutablelogic/go-client"
+	crypto "github.com/mutablelogic/go-client/pkg/bitwarden/crypto"
+	types "github.com/mutablelogic/go-server/pkg/types"
+
+	// Namespace imports
+	. "github.com/djthorpe/go-errors"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+type Secret struct {
+	Id             string    `json:"id,width:36"`
+	OrganizationId string    `json:"organizationId,width:36"`
+	ProjectId      string    `json:"projectId,omitempty,width:36"`
+	Key            string    `json:"key,width:30"`         // crypt
+	Value          string    `json:"value,omitempty,wrap"` // crypt
+	Note           string    `json:"note,omitempty,wrap"`  // crypt
+	CreationDate   time.Time `json:"creationDate,width:29"`
+	RevisionDate   time.Time `json:"revisionDate,width:29"`
+}
+
+type reqSecret struct {
+	Key        string   `json:"key"`
+	Value      string   `json:"value"`
+	Note       string   `json:"note"`
+	ProjectIds []string `json:"projectIds,omitempty"`
+}
+
+type reqSecretIds struct {
+	Ids []string `json:"ids"`
+}
+
+type respSecret struct {
+	Secret
+	Projects []struct {
+		Id string `json:"id"`
+	} `json:"projects,omitempty"`
+}
+
+type respSecrets struct {
+	Data []*respSecret `json:"data"`
+}
+
+type respSecretIdentifiers struct {
+	Secrets []struct {
+		Id string `json:"id"`
+	} `json:"secrets"`
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// STRINGIFY
+
+func (s Secret) String() string {
+	data, _ := json.MarshalIndent(s, "", "  ")
+	return string(data)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Secrets returns the decrypted secrets in a project, or all secrets the
+// machine account has access to when the project is empty
+func (c *Client) Secrets(ctx context.Context, project string) ([]*Secret, error) {
+	var identifiers respSecretIdentifiers
+	token, organization, _, err := c.auth(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// List the secret identifiers
+	path := []any{"organizations", organization, "secrets"}
+	if project != "" {
+		path = []any{"projects", project, "secrets"}
+	}
+	if err := c.client.DoWithContext(ctx, nil, &identifiers, client.OptPath(path...), token); err != nil {
+		return nil, err
+	} else if len(identifiers.Secrets) == 0 {
+		return []*Secret{}, nil
+	}
+
+	// Get the secrets
+	ids := make([]string, 0, len(identifiers.Secrets))
+	for _, secret := range identifiers.Secrets {
+		ids = append(ids, secret.Id)
+	}
+	return c.SecretsByIds(ctx, ids...)
+}
+
+// SecretsByIds returns decrypted secrets by identifier
+func (c *Client) SecretsByIds(ctx context.Context, ids ...string) ([]*Secret, error) {
+	var response respSecrets
+	if len(ids) == 0 {
+		return nil, ErrBadParameter.With("SecretsByIds")
+	}
+	token, _, key, err := c.auth(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Request -> Response
+	if payload, err := client.NewJSONRequest(reqSecretIds{ids}); err != nil {
+		return nil, err
+	} else if err := c.client.DoWithContext(ctx, payload, &response, client.OptPath("secrets", "get-by-ids"), token); err != nil {
+		return nil, err
+	}
+
+	// Decrypt the secrets
+	result := make([]*Secret, 0, len(response.Data))
+	for _, secret := range response.Data {
+		if err := secret.decrypt(key.DecryptStr); err != nil {
+			return nil, err
+		}
+		result = append(result, &secret.Secret)
+	}
+
+	// Return success
+	return result, nil
+}
+
+// Secret returns a decrypted secret by identifier
+func (c *Client) Secret(ctx context.Context, id string) (*Secret, error) {
+	var response respSecret
+	if id == "" {
+		return nil, ErrBadParameter.With("Secret")
+	}
+	token, _, key, err := c.auth(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Request -> Response
+	if err := c.client.DoWithContext(ctx, nil, &response, client.OptPath("secrets", id), token); err != nil {
+		return nil, err
+	} else if err := response.decrypt(key.DecryptStr); err != nil {
+		return nil, err
+	}
+
+	// Return success
+	return &response.Secret, nil
+}
+
+// CreateSecret encrypts and creates a secret in a project, returning the
+// decrypted secret
+func (c *Client) CreateSecret(ctx context.Context, project, key, value, note string) (*Secret, error) {
+	if project == "" || key == "" {
+		return nil, ErrBadParameter.With("CreateSecret")
+	}
+	return c.writeSecret(ctx, http.MethodPost, &Secret{ProjectId: project, Key: key, Value: value, Note: note})
+}
+
+// UpdateSecret encrypts and updates the key, value, note and project of a
+// secret, returning the decrypted secret
+func (c *Client) UpdateSecret(ctx context.Context, secret *Secret) (*Secret, error) {
+	if secret == nil || secret.Id == "" || secret.Key == "" {
+		return nil, ErrBadParameter.With("UpdateSecret")
+	}
+	return c.writeSecret(ctx, http.MethodPut, secret)
+}
+
+// DeleteSecrets deletes one or more secrets
+func (c *Client) DeleteSecrets(ctx context.Context, ids ...string) error {
+	return c.delete(ctx, "secrets", ids...)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// Encrypt and write a secret, and return the decrypted response
+func (c *Client) writeSecret(ctx context.Context, method string, secret *Secret) (*Secret, error) {
+	var response respSecret
+	token, organization, key, err := c.auth(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Encrypt the secret. The key, value and note are always encrypted, even
+	// when empty, as the server requires encrypted strings for all three.
+	request := reqSecret{Key: secret.Key, Value: secret.Value, Note: secret.Note}
+	if err := encryptStrings(key, &request.Key, &request.Value, &request.Note); err != nil {
+		return nil, err
+	}
+	if secret.ProjectId != "" {
+		request.ProjectIds = []string{secret.ProjectId}
+	}
+
+	// Request -> Response
+	path := []any{"secrets", secret.Id}
+	if method == http.MethodPost {
+		path = []any{"organizations", organization, "secrets"}
+	}
+	if payload, err := client.NewJSONRequestEx(method, request, types.ContentTypeJSON); err != nil {
+		return nil, err
+	} else if err := c.client.DoWithContext(ctx, payload, &response, client.OptPath(path...), token); err != nil {
+		return nil, err
+	} else if err := response.decrypt(key.DecryptStr); err != nil {
+		return nil, err
+	}
+
+	// Return success
+	return &response.Secret, nil
+}
+
+// Decrypt the secret fields in place, and set the project identifier
+func (s *respSecret) decrypt(fn func(string) (string, error)) error {
+	if s.ProjectId == "" && len(s.Projects) > 0 {
+		s.ProjectId = s.Projects[0].Id
+	}
+	return cryptStrings(fn, &s.Key, &s.Value, &s.Note)
+}
+
+// Encrypt strings in place, including empty strings
+func encryptStrings(key *crypto.CryptoKey, values ...*string) error {
+	for _, value := range values {
+		encrypted, err := key.Encrypt([]byte(*value))
+		if err != nil {
+			return err
+		}
+		*value = encrypted.String()
+	}
+	return nil
+}
+
+// Encrypt or decrypt non-empty strings in place
+func cryptStrings(fn func(string) (string, error), values ...*string) error {
+	for _, value := range values {
+		if *value == "" {
+			continue
+		}
+		result, err := fn(*value)
+		if err != nil {
+			return err
+		}
+		*value = result
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package secretsmanager
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	// Packages
+	crypto "github.com/mutablelogic/go-client/pkg/bitwarden/crypto"
+	hkdf "golang.org/x/crypto/hkdf"
+
+	// Namespace imports
+	. "github.com/djthorpe/go-errors"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// accessToken is a machine account access token, which is in the form
+// "0.<client_id>.<client_secret>:<encryption_key>"
+type accessToken struct {
+	ClientId     string
+	ClientSecret string
+	Key          *crypto.CryptoKey
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// GLOBALS
+
+const (
+	accessTokenVersion = "0"
+	accessTokenName    = "bitwarden-accesstoken"
+	accessTokenInfo    = "sm-access-token"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// LIFECYCLE
+
+// parseAccessToken parses an access token and derives the key used to
+// decrypt the encrypted payload returned on login
+func parseAccessToken(value string) (*accessToken, error) {
+	credentials, secret, ok := strings.Cut(value, ":")
+	if !ok {
+		return nil, ErrBadParameter.With("invalid access token")
+	}
+	parts := strings.Split(credentials, ".")
+	if len(parts) != 3 || parts[0] != accessTokenVersion || parts[1] == "" || parts[2] == "" {
+		return nil, ErrBadParameter.With("invalid access token")
+	}
+	data, err := base64.StdEncoding.DecodeString(secret)
+	if err != nil || len(data) != 16 {
+		return nil, ErrBadParameter.With("invalid access token encryption key")
+	}
+
+	// Derive the key using HMAC as the HKDF extract step
+	mac := hmac.New(sha256.New, []byte(accessTokenName))
+	mac.Write(data)
+	key := make([]byte, 64)
+	if _, err := hkdf.Expand(sha256.New, mac.Sum(nil), []byte(accessTokenInfo)).Read(key); err != nil {
+		return nil, err
+	}
+
+	// Return success
+	return &accessToken{
+		ClientId:     parts[1],
+		ClientSecret: parts[2],
+		Key:          crypto.NewKey(key[:32], key[32:]),
+	}, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// decryptPayload decrypts the encrypted payload returned on login, which
+// contains the organization encryption key
+func (t *accessToken) decryptPayload(payload string) (*crypto.CryptoKey, error) {
+	var response struct {
+		EncryptionKey string `json:"encryptionKey"`
+	}
+	if encrypted, err := crypto.NewEncrypted(payload); err != nil {
+		return nil, err
+	} else if data, err := t.Key.Decrypt(encrypted); err != nil {
+		return nil, ErrNotAuthorized.With("unable to decrypt payload")
+	} else if err := json.Unmarshal(data, &response); err != nil {
+		return nil, err
+	}
+	if key, err := base64.StdEncoding.DecodeString(response.EncryptionKey); err != nil {
+		return nil, err
+	} else if len(key) != 64 {
+		return nil, ErrNotAuthorized.Withf("invalid encryption key length: %d", len(key))
+	} else {
+		return crypto.NewKey(key[:32], key[32:]), nil
+	}
+}
+
+// organizationClaim returns the organization claim from a JWT access token,
+// without verifying the token signature
+func organizationClaim(token string) (string, error) {
+	var claims struct {
+		Organization string `json:"organization"`
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", ErrBadParameter.With("invalid JWT")
+	} else if data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "=")); err != nil {
+		return "", err
+	} else if err := json.Unmarshal(data, &claims); err != nil {
+		return "", err
+	} else if claims.Organization == "" {
+		return "", ErrNotAuthorized.With("missing organization claim")
+	}
+	return claims.Organization, nil
+}
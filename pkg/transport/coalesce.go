@@ -0,0 +1,236 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	// Packages
+	types "github.com/mutablelogic/go-server/pkg/types"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// CoalesceTransport is an http.RoundTripper middleware that deduplicates
+// identical in-flight GET requests. While a request is in flight, identical
+// requests wait for its response rather than making another round-trip, and
+// each caller receives its own copy of the response and body.
+//
+// Requests are identical when they have the same URL and the same values for
+// the Authorization header and the other headers which select a
+// representation, such as Accept. As the shared response body is read into
+// memory, requests with a body, range requests and event streams are never
+// coalesced, and a request can opt out with WithSkipCoalesce.
+type CoalesceTransport struct {
+	http.RoundTripper
+	headers []string
+	mu      sync.Mutex
+	calls   map[string]*coalesceCall
+}
+
+// coalesceCall is a round-trip shared by one or more callers
+type coalesceCall struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters int
+	resp    *http.Response
+	body    []byte
+	err     error
+}
+
+// skipCoalesceKey is an unexported context key used to signal that
+// CoalesceTransport should not share a round-trip for this request.
+type skipCoalesceKey struct{}
+
+///////////////////////////////////////////////////////////////////////////////
+// GLOBALS
+
+// coalesceHeaders are the request headers which distinguish requests for the
+// same URL
+var coalesceHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Accept",
+	"Accept-Encoding",
+	"Accept-Language",
+	"If-Range",
+	"If-Match",
+	"If-None-Match",
+	"If-Modified-Since",
+	"If-Unmodified-Since",
+	"Cache-Control",
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// LIFECYCLE
+
+// NewCoalesce wraps parent in a CoalesceTransport. The headers are added to
+// the set of request headers which must match for requests to be coalesced.
+// If parent is nil, http.DefaultTransport is used.
+func NewCoalesce(parent http.RoundTripper, headers ...string) *CoalesceTransport {
+	if parent == nil {
+		parent = http.DefaultTransport
+	}
+	keys := slices.Clone(coalesceHeaders)
+	for _, header := range headers {
+		keys = append(keys, http.CanonicalHeaderKey(header))
+	}
+	slices.Sort(keys)
+	return &CoalesceTransport{
+		RoundTripper: parent,
+		headers:      slices.Compact(keys),
+		calls:        make(map[string]*coalesceCall),
+	}
+}
+
+// WithSkipCoalesce returns a copy of ctx that instructs CoalesceTransport to
+// make its own round-trip for the request carrying this context. Use this for
+// large responses which should not be read into memory, such as downloads.
+func WithSkipCoalesce(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipCoalesceKey{}, true)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// RoundTrip implements http.RoundTripper. The first caller starts the shared
+// round-trip, which has no deadline of its own. Each caller waits until its
+// own context is done, and the round-trip is cancelled once every caller
+// waiting for it has given up.
+func (t *CoalesceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req, ok := coalescable(req)
+	if !ok {
+		return t.roundTrip(req)
+	}
+
+	// Join an identical call in flight, or else start one
+	key := t.key(req)
+	t.mu.Lock()
+	c, exists := t.calls[key]
+	if !exists {
+		ctx, cancel := context.WithCancel(context.WithoutCancel(req.Context()))
+		c = &coalesceCall{done: make(chan struct{}), cancel: cancel}
+		t.calls[key] = c
+		go t.do(key, c, req.WithContext(ctx))
+	}
+	c.waiters++
+	t.mu.Unlock()
+
+	// Wait for the response, or for the caller to give up
+	select {
+	case <-c.done:
+		if c.err != nil {
+			return nil, c.err
+		}
+		return c.response(req), nil
+	case <-req.Context().Done():
+		t.mu.Lock()
+		if c.waiters--; c.waiters == 0 {
+			c.cancel()
+			if t.calls[key] == c {
+				delete(t.calls, key)
+			}
+		}
+		t.mu.Unlock()
+		return nil, req.Context().Err()
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+func (t *CoalesceTransport) roundTrip(req *http.Request) (*http.Response, error) {
+	rt := t.RoundTripper
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	return rt.RoundTrip(req)
+}
+
+// do makes the shared round-trip and reads the whole body, so that it can be
+// copied to each caller. Once complete, identical requests start a new call.
+func (t *CoalesceTransport) do(key string, c *coalesceCall, req *http.Request) {
+	defer c.cancel()
+	resp, err := t.roundTrip(req)
+	if err == nil {
+		c.body, err = io.ReadAll(resp.Body)
+		resp.Body.Close()
+	}
+	c.resp, c.err = resp, err
+
+	t.mu.Lock()
+	if t.calls[key] == c {
+		delete(t.calls, key)
+	}
+	t.mu.Unlock()
+	close(c.done)
+}
+
+// coalescable returns true if the request can share a round-trip. A body of
+// unknown length is probed, as the client sends an empty body with a GET, and
+// the request returned should be used in place of the original.
+func coalescable(req *http.Request) (*http.Request, bool) {
+	switch {
+	case req.Context().Value(skipCoalesceKey{}) != nil:
+		return req, false
+	case req.Method != http.MethodGet:
+		return req, false
+	case req.Header.Get("Range") != "":
+		return req, false
+	}
+
+	// Streams are not read to the end
+	accept := req.Header.Get("Accept")
+	if strings.Contains(accept, types.ContentTypeTextStream) || strings.Contains(accept, types.ContentTypeJSONStream) {
+		return req, false
+	}
+
+	// Requests with a body are not coalesced
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, true
+	} else if req.ContentLength != 0 {
+		return req, false
+	}
+	var buf [1]byte
+	n, err := io.ReadFull(req.Body, buf[:])
+	r := req.Clone(req.Context())
+	if err == io.EOF {
+		req.Body.Close()
+		r.Body, r.GetBody = http.NoBody, nil
+		return r, true
+	}
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(buf[:n]), req.Body), req.Body}
+	return r, false
+}
+
+// key returns the method, URL and distinguishing headers of the request
+func (t *CoalesceTransport) key(req *http.Request) string {
+	var key strings.Builder
+	key.WriteString(req.Method + " " + req.URL.String())
+	for _, header := range t.headers {
+		if values := req.Header.Values(header); len(values) > 0 {
+			key.WriteString("\n" + header + ": " + strings.Join(values, ", "))
+		}
+	}
+	return key.String()
+}
+
+// response returns a copy of the shared response for a caller
+func (c *coalesceCall) response(req *http.Request) *http.Response {
+	resp := new(http.Response)
+	*resp = *c.resp
+	resp.Header = c.resp.Header.Clone()
+	resp.Trailer = c.resp.Trailer.Clone()
+	resp.Body = io.NopCloser(bytes.NewReader(c.body))
+	resp.Request = req
+	return resp
+}
@@ -0,0 +1,222 @@
+package transport_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	transport "github.com/mutablelogic/go-client/pkg/transport"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+)
+
+// newCoalesceServer returns a server which blocks each request until release
+// is closed, and counts the requests received
+func newCoalesceServer(t *testing.T) (*httptest.Server, *atomic.Int32, chan struct{}) {
+	t.Helper()
+	var count atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count.Add(1)
+		<-release
+		w.Header().Set("X-Path", r.URL.Path)
+		io.WriteString(w, "body "+r.URL.Path)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &count, release
+}
+
+// coalesceGet makes n concurrent requests with the transport once the modify function
+// has been applied to each request, and returns the response bodies
+func coalesceGet(t *testing.T, rt http.RoundTripper, n int, release chan struct{}, count *atomic.Int32, want int32, modify func(int, *http.Request)) []string {
+	t.Helper()
+	var wg sync.WaitGroup
+	bodies := make([]string, n)
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, err := http.NewRequest(http.MethodGet, "", nil)
+			require.NoError(t, err)
+			modify(i, req)
+			resp, err := rt.RoundTrip(req)
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer resp.Body.Close()
+			data, err := io.ReadAll(resp.Body)
+			assert.NoError(t, err)
+			bodies[i] = string(data)
+		}()
+	}
+
+	// Release the server once the expected requests have been received, and
+	// every caller has had a chance to join
+	assert.Eventually(t, func() bool { return count.Load() >= want }, time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	return bodies
+}
+
+func TestCoalesce_IdenticalRequests(t *testing.T) {
+	srv, count, release := newCoalesceServer(t)
+	rt := transport.NewCoalesce(nil)
+
+	bodies := coalesceGet(t, rt, 10, release, count, 1, func(_ int, req *http.Request) {
+		req.URL, _ = req.URL.Parse(srv.URL + "/states")
+		req.Header.Set("Authorization", "Bearer token")
+	})
+	assert.Equal(t, int32(1), count.Load())
+	for _, body := range bodies {
+		assert.Equal(t, "body /states", body)
+	}
+
+	// Once complete, the next request makes a new round-trip
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/states", nil)
+	require.NoError(t, err)
+	resp, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, int32(2), count.Load())
+}
+
+func TestCoalesce_DifferentRequests(t *testing.T) {
+	srv, count, release := newCoalesceServer(t)
+	rt := transport.NewCoalesce(nil, "X-Tenant")
+
+	// Each of the URL, the token and the additional header distinguish requests
+	bodies := coalesceGet(t, rt, 4, release, count, 4, func(i int, req *http.Request) {
+		path := "/a"
+		if i == 1 {
+			path = "/b"
+		}
+		req.URL, _ = req.URL.Parse(srv.URL + path)
+		if i == 2 {
+			req.Header.Set("Authorization", "Bearer other")
+		}
+		if i == 3 {
+			req.Header.Set("X-Tenant", "other")
+		}
+	})
+	assert.Equal(t, int32(4), count.Load())
+	assert.Equal(t, []string{"body /a", "body /b", "body /a", "body /a"}, bodies)
+}
+
+func TestCoalesce_NotCoalesced(t *testing.T) {
+	srv, count, release := newCoalesceServer(t)
+	rt := transport.NewCoalesce(nil)
+
+	// Range requests and requests with other methods are passed through
+	coalesceGet(t, rt, 4, release, count, 4, func(i int, req *http.Request) {
+		req.URL, _ = req.URL.Parse(srv.URL + "/file")
+		if i < 2 {
+			req.Header.Set("Range", "bytes=0-9")
+		} else {
+			req.Method = http.MethodDelete
+		}
+	})
+	assert.Equal(t, int32(4), count.Load())
+}
+
+func TestCoalesce_Skip(t *testing.T) {
+	srv, count, release := newCoalesceServer(t)
+	rt := transport.NewCoalesce(nil)
+
+	// Requests which opt out make their own round-trip
+	bodies := coalesceGet(t, rt, 3, release, count, 3, func(_ int, req *http.Request) {
+		req.URL, _ = req.URL.Parse(srv.URL + "/large")
+		*req = *req.WithContext(transport.WithSkipCoalesce(req.Context()))
+	})
+	assert.Equal(t, int32(3), count.Load())
+	for _, body := range bodies {
+		assert.Equal(t, "body /large", body)
+	}
+}
+
+func TestCoalesce_Deadline(t *testing.T) {
+	srv, count, release := newCoalesceServer(t)
+	rt := transport.NewCoalesce(nil)
+
+	// The first caller has a short deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/slow", nil)
+	require.NoError(t, err)
+	first := make(chan error)
+	go func() {
+		_, err := rt.RoundTrip(req)
+		first <- err
+	}()
+	assert.Eventually(t, func() bool { return count.Load() == 1 }, time.Second, time.Millisecond)
+
+	// A caller without a deadline joins the round-trip
+	req, err = http.NewRequest(http.MethodGet, srv.URL+"/slow", nil)
+	require.NoError(t, err)
+	second := make(chan string)
+	go func() {
+		resp, err := rt.RoundTrip(req)
+		if !assert.NoError(t, err) {
+			second <- ""
+			return
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		second <- string(data)
+	}()
+
+	// The first caller times out, and the round-trip continues for the second
+	assert.ErrorIs(t, <-first, context.DeadlineExceeded)
+	close(release)
+	assert.Equal(t, "body /slow", <-second)
+	assert.Equal(t, int32(1), count.Load())
+}
+
+func TestCoalesce_Cancel(t *testing.T) {
+	srv, count, release := newCoalesceServer(t)
+	defer close(release)
+	rt := transport.NewCoalesce(nil)
+
+	// A caller which gives up returns the context error, and the round-trip
+	// is cancelled once every caller has given up
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/slow", nil)
+	require.NoError(t, err)
+	done := make(chan error)
+	go func() {
+		_, err := rt.RoundTrip(req)
+		done <- err
+	}()
+	assert.Eventually(t, func() bool { return count.Load() == 1 }, time.Second, time.Millisecond)
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestCoalesce_Error(t *testing.T) {
+	var count atomic.Int32
+	rt := transport.NewCoalesce(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		count.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		return nil, io.ErrUnexpectedEOF
+	}))
+
+	// Every caller receives the error of the shared round-trip
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, err := http.NewRequest(http.MethodGet, "http://example.com/", nil)
+			require.NoError(t, err)
+			_, err = rt.RoundTrip(req)
+			assert.Error(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Less(t, count.Load(), int32(5))
+}
@@ -0,0 +1,251 @@
+package transport
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	// Packages
+	brotli "github.com/andybalholm/brotli"
+	zstd "github.com/klauspost/compress/zstd"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// CompressionTransport is an http.RoundTripper middleware that compresses
+// request bodies with a Content-Encoding, advertises the encodings it can
+// decode with Accept-Encoding, and transparently decompresses responses.
+type CompressionTransport struct {
+	http.RoundTripper
+	encoding  string
+	threshold int64
+}
+
+// decoder closes both the decompressor and the underlying response body
+type decoder struct {
+	io.Reader
+	body  io.Closer
+	close func()
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// GLOBALS
+
+const (
+	EncodingGzip   = "gzip"
+	EncodingBrotli = "br"
+	EncodingZstd   = "zstd"
+)
+
+// AcceptEncoding is the value of the Accept-Encoding header sent on requests
+const AcceptEncoding = EncodingGzip + ", " + EncodingBrotli + ", " + EncodingZstd
+
+///////////////////////////////////////////////////////////////////////////////
+// LIFECYCLE
+
+// NewCompression wraps parent in a CompressionTransport. Request bodies of at
+// least threshold bytes, or of unknown length, are compressed with encoding
+// which is one of "gzip", "br" or "zstd". An empty encoding disables request
+// compression, so only responses are decompressed.
+// If parent is nil, http.DefaultTransport is used.
+func NewCompression(parent http.RoundTripper, encoding string, threshold int64) *CompressionTransport {
+	if parent == nil {
+		parent = http.DefaultTransport
+	}
+	return &CompressionTransport{RoundTripper: parent, encoding: encoding, threshold: threshold}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// IsEncoding returns true if the encoding can be used to compress requests
+func IsEncoding(encoding string) bool {
+	switch encoding {
+	case EncodingGzip, EncodingBrotli, EncodingZstd:
+		return true
+	default:
+		return false
+	}
+}
+
+// RoundTrip implements http.RoundTripper. The request is cloned before it is
+// modified. Responses are only decompressed when the caller did not set an
+// Accept-Encoding header, in which case the body is returned as received.
+// Range and HEAD requests are sent without an Accept-Encoding header, as a
+// range of compressed content can't be decompressed, and a HEAD response
+// should report the length of the uncompressed content.
+func (t *CompressionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt := t.RoundTripper
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+
+	r := req.Clone(req.Context())
+	decompress := r.Header.Get("Accept-Encoding") == "" && r.Header.Get("Range") == "" && r.Method != http.MethodHead
+	if decompress {
+		r.Header.Set("Accept-Encoding", AcceptEncoding)
+	}
+	if t.compress(r) {
+		if err := t.encode(r); err != nil {
+			return nil, err
+		}
+	}
+
+	// Perform the request
+	resp, err := rt.RoundTrip(r)
+	if err != nil || !decompress {
+		return resp, err
+	}
+
+	// Decompress the response
+	return decode(resp)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// compress returns true if the request body should be compressed
+func (t *CompressionTransport) compress(req *http.Request) bool {
+	if t.encoding == "" || req.Body == nil || req.Body == http.NoBody {
+		return false
+	}
+	if req.Header.Get("Content-Encoding") != "" {
+		return false
+	}
+	return req.ContentLength <= 0 || req.ContentLength >= t.threshold
+}
+
+// encode replaces the request body with a compressed body. Bodies of known
+// length are compressed into memory so the Content-Length can be set, and
+// other bodies are compressed as they are read. A zero Content-Length with a
+// body means the length is unknown.
+func (t *CompressionTransport) encode(req *http.Request) error {
+	body := req.Body
+	req.Header.Set("Content-Encoding", t.encoding)
+	req.Header.Del("Content-Length")
+	if req.ContentLength <= 0 {
+		req.Body = t.stream(body)
+		req.ContentLength = -1
+		req.GetBody = nil
+		return nil
+	}
+
+	// Compress into a buffer
+	data, err := t.buffer(body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = int64(len(data))
+	req.Body = io.NopCloser(bytes.NewReader(data))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	return nil
+}
+
+// buffer compresses and closes the body, returning the compressed data
+func (t *CompressionTransport) buffer(body io.ReadCloser) ([]byte, error) {
+	defer body.Close()
+	var buf bytes.Buffer
+	w, err := newEncoder(&buf, t.encoding)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(w, body); err != nil {
+		w.Close()
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// stream compresses the body in a goroutine as it is read. Closing the
+// returned body terminates the goroutine and closes the original body.
+func (t *CompressionTransport) stream(body io.ReadCloser) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		defer body.Close()
+		w, err := newEncoder(pw, t.encoding)
+		if err == nil {
+			if _, err = io.Copy(w, body); err != nil {
+				w.Close()
+			} else {
+				err = w.Close()
+			}
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr
+}
+
+// newEncoder returns a compressor for the encoding
+func newEncoder(w io.Writer, encoding string) (io.WriteCloser, error) {
+	switch encoding {
+	case EncodingGzip:
+		return gzip.NewWriter(w), nil
+	case EncodingBrotli:
+		return brotli.NewWriter(w), nil
+	case EncodingZstd:
+		return zstd.NewWriter(w)
+	default:
+		return nil, fmt.Errorf("compression: unsupported encoding %q", encoding)
+	}
+}
+
+// decode replaces the response body with a decompressed body when the
+// response has a Content-Encoding which is understood
+func decode(resp *http.Response) (*http.Response, error) {
+	if resp.Body == nil || resp.Body == http.NoBody {
+		return resp, nil
+	}
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+
+	var d *decoder
+	switch encoding {
+	case EncodingGzip, "x-gzip":
+		r, err := gzip.NewReader(resp.Body)
+		switch {
+		case err == io.EOF:
+			// An empty body has no gzip header
+			d = &decoder{Reader: http.NoBody, body: resp.Body}
+		case err != nil:
+			resp.Body.Close()
+			return nil, fmt.Errorf("compression: %w", err)
+		default:
+			d = &decoder{Reader: r, body: resp.Body}
+		}
+	case EncodingBrotli:
+		d = &decoder{Reader: brotli.NewReader(resp.Body), body: resp.Body}
+	case EncodingZstd:
+		r, err := zstd.NewReader(resp.Body)
+		if err != nil {
+			resp.Body.Close()
+			return nil, fmt.Errorf("compression: %w", err)
+		}
+		d = &decoder{Reader: r, body: resp.Body, close: r.Close}
+	default:
+		return resp, nil
+	}
+
+	// Replace the body and remove the headers which no longer apply
+	resp.Body = d
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return resp, nil
+}
+
+// Close closes the decompressor and the response body
+func (d *decoder) Close() error {
+	if d.close != nil {
+		d.close()
+	}
+	return d.body.Close()
+}
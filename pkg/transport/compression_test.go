@@ -0,0 +1,266 @@
+package transport_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	brotli "github.com/andybalholm/brotli"
+	zstd "github.com/klauspost/compress/zstd"
+	transport "github.com/mutablelogic/go-client/pkg/transport"
+	assert "github.com/stretchr/testify/assert"
+)
+
+// compressString returns the value compressed with the encoding
+func compressString(t *testing.T, encoding, value string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case transport.EncodingGzip:
+		w = gzip.NewWriter(&buf)
+	case transport.EncodingBrotli:
+		w = brotli.NewWriter(&buf)
+	case transport.EncodingZstd:
+		zw, err := zstd.NewWriter(&buf)
+		assert.NoError(t, err)
+		w = zw
+	}
+	_, err := w.Write([]byte(value))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+// decompressBody returns the request body decompressed with the encoding
+func decompressBody(t *testing.T, encoding string, r io.Reader) string {
+	t.Helper()
+	var reader io.Reader
+	switch encoding {
+	case transport.EncodingGzip:
+		zr, err := gzip.NewReader(r)
+		assert.NoError(t, err)
+		reader = zr
+	case transport.EncodingBrotli:
+		reader = brotli.NewReader(r)
+	case transport.EncodingZstd:
+		zr, err := zstd.NewReader(r)
+		assert.NoError(t, err)
+		defer zr.Close()
+		reader = zr
+	default:
+		reader = r
+	}
+	data, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	return string(data)
+}
+
+func TestNewCompression_NilParentUsesDefault(t *testing.T) {
+	assert := assert.New(t)
+	c := transport.NewCompression(nil, transport.EncodingGzip, 0)
+	assert.NotNil(c)
+	var _ http.RoundTripper = c
+}
+
+func TestIsEncoding(t *testing.T) {
+	assert := assert.New(t)
+	assert.True(transport.IsEncoding(transport.EncodingGzip))
+	assert.True(transport.IsEncoding(transport.EncodingBrotli))
+	assert.True(transport.IsEncoding(transport.EncodingZstd))
+	assert.False(transport.IsEncoding("deflate"))
+	assert.False(transport.IsEncoding(""))
+}
+
+func TestCompression_RequestBody(t *testing.T) {
+	body := strings.Repeat("hello, world\n", 100)
+	for _, encoding := range []string{transport.EncodingGzip, transport.EncodingBrotli, transport.EncodingZstd} {
+		t.Run(encoding, func(t *testing.T) {
+			assert := assert.New(t)
+			var got, gotEncoding string
+			var gotLength int64
+			inner := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				gotEncoding = req.Header.Get("Content-Encoding")
+				gotLength = req.ContentLength
+				got = decompressBody(t, gotEncoding, req.Body)
+				return stubResp(200, "text/plain", "ok"), nil
+			})
+			c := transport.NewCompression(inner, encoding, 64)
+			req := httptest.NewRequest(http.MethodPost, "http://example.com/", strings.NewReader(body))
+			resp, err := c.RoundTrip(req)
+			assert.NoError(err)
+			resp.Body.Close()
+			assert.Equal(encoding, gotEncoding)
+			assert.Equal(body, got)
+			assert.Less(gotLength, int64(len(body)))
+			assert.Empty(req.Header.Get("Content-Encoding"), "original request must not be modified")
+		})
+	}
+}
+
+func TestCompression_BelowThreshold(t *testing.T) {
+	assert := assert.New(t)
+	var gotEncoding, got string
+	inner := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		gotEncoding = req.Header.Get("Content-Encoding")
+		data, _ := io.ReadAll(req.Body)
+		got = string(data)
+		return stubResp(200, "text/plain", "ok"), nil
+	})
+	c := transport.NewCompression(inner, transport.EncodingGzip, 1024)
+	req := httptest.NewRequest(http.MethodPost, "http://example.com/", strings.NewReader("small"))
+	resp, err := c.RoundTrip(req)
+	assert.NoError(err)
+	resp.Body.Close()
+	assert.Empty(gotEncoding)
+	assert.Equal("small", got)
+}
+
+func TestCompression_StreamingBody(t *testing.T) {
+	assert := assert.New(t)
+	body := strings.Repeat("streaming\n", 1000)
+	var gotEncoding, got string
+	var gotLength int64
+	inner := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		gotEncoding = req.Header.Get("Content-Encoding")
+		gotLength = req.ContentLength
+		got = decompressBody(t, gotEncoding, req.Body)
+		req.Body.Close()
+		return stubResp(200, "text/plain", "ok"), nil
+	})
+	c := transport.NewCompression(inner, transport.EncodingZstd, 1<<20)
+	req := httptest.NewRequest(http.MethodPost, "http://example.com/", io.NopCloser(strings.NewReader(body)))
+	req.ContentLength = -1
+	resp, err := c.RoundTrip(req)
+	assert.NoError(err)
+	resp.Body.Close()
+	assert.Equal(transport.EncodingZstd, gotEncoding)
+	assert.Equal(int64(-1), gotLength)
+	assert.Equal(body, got)
+}
+
+func TestCompression_UnknownLengthBody(t *testing.T) {
+	assert := assert.New(t)
+	var gotEncoding, got string
+	inner := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		gotEncoding = req.Header.Get("Content-Encoding")
+		got = decompressBody(t, gotEncoding, req.Body)
+		req.Body.Close()
+		return stubResp(200, "text/plain", "ok"), nil
+	})
+
+	// A request built from a reader of unknown length has a zero ContentLength
+	c := transport.NewCompression(inner, transport.EncodingGzip, 1024)
+	req, err := http.NewRequest(http.MethodPost, "http://example.com/", io.MultiReader(strings.NewReader("unknown")))
+	assert.NoError(err)
+	assert.Equal(int64(0), req.ContentLength)
+	resp, err := c.RoundTrip(req)
+	assert.NoError(err)
+	resp.Body.Close()
+	assert.Equal(transport.EncodingGzip, gotEncoding)
+	assert.Equal("unknown", got)
+}
+
+func TestCompression_ResponseBody(t *testing.T) {
+	for _, encoding := range []string{transport.EncodingGzip, transport.EncodingBrotli, transport.EncodingZstd} {
+		t.Run(encoding, func(t *testing.T) {
+			assert := assert.New(t)
+			var gotAccept string
+			inner := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				gotAccept = req.Header.Get("Accept-Encoding")
+				resp := stubResp(200, "text/plain", "")
+				resp.Header.Set("Content-Encoding", encoding)
+				resp.Body = io.NopCloser(bytes.NewReader(compressString(t, encoding, "compressed response")))
+				return resp, nil
+			})
+			c := transport.NewCompression(inner, "", 0)
+			req := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+			resp, err := c.RoundTrip(req)
+			assert.NoError(err)
+			defer resp.Body.Close()
+			assert.Equal(transport.AcceptEncoding, gotAccept)
+			assert.Empty(resp.Header.Get("Content-Encoding"))
+			assert.True(resp.Uncompressed)
+			data, err := io.ReadAll(resp.Body)
+			assert.NoError(err)
+			assert.Equal("compressed response", string(data))
+		})
+	}
+}
+
+func TestCompression_CallerAcceptEncoding(t *testing.T) {
+	assert := assert.New(t)
+	compressed := compressString(t, transport.EncodingGzip, "raw")
+	inner := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		assert.Equal("gzip", req.Header.Get("Accept-Encoding"))
+		resp := stubResp(200, "text/plain", "")
+		resp.Header.Set("Content-Encoding", transport.EncodingGzip)
+		resp.Body = io.NopCloser(bytes.NewReader(compressed))
+		return resp, nil
+	})
+	c := transport.NewCompression(inner, "", 0)
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := c.RoundTrip(req)
+	assert.NoError(err)
+	defer resp.Body.Close()
+
+	// The body is returned as received
+	assert.Equal(transport.EncodingGzip, resp.Header.Get("Content-Encoding"))
+	data, err := io.ReadAll(resp.Body)
+	assert.NoError(err)
+	assert.Equal(compressed, data)
+}
+
+func TestCompression_RangeAndHead(t *testing.T) {
+	assert := assert.New(t)
+	inner := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		assert.Empty(req.Header.Get("Accept-Encoding"))
+		resp := stubResp(200, "text/plain", "raw")
+		resp.Header.Set("Content-Encoding", transport.EncodingGzip)
+		return resp, nil
+	})
+	c := transport.NewCompression(inner, "", 0)
+
+	// Range requests are not compressed, and the body is returned as received
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+	req.Header.Set("Range", "bytes=0-9")
+	resp, err := c.RoundTrip(req)
+	assert.NoError(err)
+	defer resp.Body.Close()
+	assert.Equal(transport.EncodingGzip, resp.Header.Get("Content-Encoding"))
+	data, err := io.ReadAll(resp.Body)
+	assert.NoError(err)
+	assert.Equal("raw", string(data))
+
+	// HEAD requests are not compressed
+	req = httptest.NewRequest(http.MethodHead, "http://example.com/", nil)
+	resp, err = c.RoundTrip(req)
+	assert.NoError(err)
+	defer resp.Body.Close()
+	assert.Equal(transport.EncodingGzip, resp.Header.Get("Content-Encoding"))
+}
+
+func TestCompression_Server(t *testing.T) {
+	assert := assert.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := decompressBody(t, r.Header.Get("Content-Encoding"), r.Body)
+		w.Header().Set("Content-Encoding", transport.EncodingZstd)
+		w.Write(compressString(t, transport.EncodingZstd, strings.ToUpper(body)))
+	}))
+	defer srv.Close()
+
+	c := transport.NewCompression(nil, transport.EncodingGzip, 0)
+	req, err := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader("echo"))
+	assert.NoError(err)
+	resp, err := c.RoundTrip(req)
+	assert.NoError(err)
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	assert.NoError(err)
+	assert.Equal("ECHO", string(data))
+}
@@ -0,0 +1,341 @@
+/*
+tus implements a client for resumable uploads using the tus protocol, so that
+an interrupted upload continues from the last byte the server received rather
+than restarting from the beginning.
+
+References:
+
+	https://tus.io/protocols/resumable-upload
+*/
+package tus
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	// Packages
+	client "github.com/mutablelogic/go-client"
+	httpresponse "github.com/mutablelogic/go-server/pkg/httpresponse"
+
+	// Namespace imports
+	. "github.com/djthorpe/go-errors"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+type Client struct {
+	*client.Client
+	endpoint *url.URL
+}
+
+// Upload is an upload on the server. The URL can be stored and passed to
+// Get in order to resume the upload later, for example after a restart.
+type Upload struct {
+	URL    string `json:"url"`
+	Offset int64  `json:"offset"` // Number of bytes received by the server
+	Length int64  `json:"length"` // Total number of bytes in the upload
+}
+
+// response captures the headers of a response
+type response struct {
+	header http.Header
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// GLOBALS
+
+const (
+	Version     = "1.0.0"
+	ContentType = "application/offset+octet-stream"
+)
+
+const (
+	headerResumable = "Tus-Resumable"
+	headerOffset    = "Upload-Offset"
+	headerLength    = "Upload-Length"
+	headerMetadata  = "Upload-Metadata"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// LIFECYCLE
+
+// New returns a client which creates uploads at the endpoint. Client options
+// such as tokens, rate limits and tracing apply to every upload request.
+func New(endpoint string, opts ...client.ClientOpt) (*Client, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create client
+	client, err := client.New(append(opts, client.OptEndpoint(endpoint))...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Return the client
+	return &Client{Client: client, endpoint: u}, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Create creates an upload of length bytes on the server, with optional
+// metadata such as the filename
+func (c *Client) Create(ctx context.Context, length int64, metadata map[string]string) (*Upload, error) {
+	if length < 0 {
+		return nil, ErrBadParameter.Withf("Create: invalid length %d", length)
+	}
+
+	header := http.Header{}
+	header.Set(headerLength, strconv.FormatInt(length, 10))
+	if len(metadata) > 0 {
+		value, err := encodeMetadata(metadata)
+		if err != nil {
+			return nil, err
+		}
+		header.Set(headerMetadata, value)
+	}
+
+	// The location of the upload is returned in the response
+	resp, err := c.do(ctx, http.MethodPost, c.endpoint.String(), header, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	location := resp.Get("Location")
+	if location == "" {
+		return nil, ErrUnexpectedResponse.With("Create: missing Location header")
+	}
+	u, err := c.endpoint.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+
+	// Return the upload
+	return &Upload{URL: u.String(), Length: length}, nil
+}
+
+// Get returns an existing upload, with the offset of the next byte the
+// server expects
+func (c *Client) Get(ctx context.Context, url string) (*Upload, error) {
+	upload := &Upload{URL: url}
+	if err := c.offset(ctx, upload); err != nil {
+		return nil, err
+	}
+	return upload, nil
+}
+
+// Upload sends the remainder of the upload from r, starting at the offset of
+// the upload. When a request fails, the offset is fetched from the server and
+// the upload continues from there, up to the number of retries set with
+// OptRetry. A request which succeeds without advancing the offset counts as a
+// failed request.
+func (c *Client) Upload(ctx context.Context, upload *Upload, r io.ReaderAt, opts ...Opt) error {
+	o, err := applyOpts(opts...)
+	if err != nil {
+		return err
+	}
+	if upload == nil || r == nil {
+		return ErrBadParameter.With("Upload")
+	}
+
+	start, offset := time.Now(), upload.Offset
+	var failures int
+	for upload.Offset < upload.Length {
+		size := upload.Length - upload.Offset
+		if o.chunkSize > 0 {
+			size = min(size, o.chunkSize)
+		}
+
+		// Report progress from the start of the upload
+		var reqopts []client.RequestOpt
+		if o.progress != nil {
+			base := upload.Offset
+			reqopts = append(reqopts, client.OptProgress(func(p client.Progress) {
+				o.progress(client.Progress{
+					Sent:    base + p.Sent,
+					Total:   upload.Length,
+					Offset:  offset,
+					Elapsed: time.Since(start),
+				})
+			}))
+		}
+
+		// Send a chunk, which advances the offset
+		err := c.patch(ctx, upload, io.NewSectionReader(r, upload.Offset, size), size, reqopts...)
+		if err == nil {
+			failures = 0
+			continue
+		}
+
+		// Retry from the offset the server has received
+		for {
+			if !retryable(err) || failures >= o.retries {
+				return err
+			}
+			failures++
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(o.delay * time.Duration(failures)):
+			}
+			if err = c.offset(ctx, upload); err == nil {
+				break
+			}
+		}
+	}
+
+	// Return success
+	return nil
+}
+
+// Delete terminates an upload, so the server can free any resources
+func (c *Client) Delete(ctx context.Context, upload *Upload) error {
+	if upload == nil {
+		return ErrBadParameter.With("Delete")
+	}
+	_, err := c.do(ctx, http.MethodDelete, upload.URL, nil, nil, 0)
+	return err
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// offset updates the upload with the offset and length from the server
+func (c *Client) offset(ctx context.Context, upload *Upload) error {
+	resp, err := c.do(ctx, http.MethodHead, upload.URL, nil, nil, 0)
+	if err != nil {
+		return err
+	}
+	offset, err := parseHeader(resp, headerOffset)
+	if err != nil {
+		return err
+	}
+	if resp.Get(headerLength) != "" {
+		if upload.Length, err = parseHeader(resp, headerLength); err != nil {
+			return err
+		}
+	}
+	upload.Offset = offset
+	return nil
+}
+
+// patch sends size bytes from r at the offset of the upload, and updates the
+// offset from the response. An offset which does not advance is returned as
+// an error, so it counts as a failure. The client timeout does not apply, as a chunk can
+// take a long time to send.
+func (c *Client) patch(ctx context.Context, upload *Upload, r io.Reader, size int64, opts ...client.RequestOpt) error {
+	header := http.Header{}
+	header.Set("Content-Type", ContentType)
+	header.Set(headerOffset, strconv.FormatInt(upload.Offset, 10))
+	resp, err := c.do(ctx, http.MethodPatch, upload.URL, header, r, size, append(opts, client.OptNoTimeout())...)
+	if err != nil {
+		return err
+	}
+	offset, err := parseHeader(resp, headerOffset)
+	if err != nil {
+		return err
+	}
+	if size > 0 && offset <= upload.Offset {
+		return ErrUnexpectedResponse.Withf("PATCH did not advance the offset: %d", offset)
+	}
+	upload.Offset = offset
+	return nil
+}
+
+// do performs a request and returns the response headers
+func (c *Client) do(ctx context.Context, method, url string, header http.Header, body io.Reader, size int64, opts ...client.RequestOpt) (http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set(headerResumable, Version)
+	if body != nil {
+		req.ContentLength = size
+	}
+
+	// Perform the request
+	var resp response
+	if err := c.Request(req, &resp, opts...); err != nil {
+		return nil, err
+	}
+
+	// Return the response headers
+	return resp.header, nil
+}
+
+// Unmarshal captures the response headers and discards the body
+func (r *response) Unmarshal(header http.Header, body io.Reader) error {
+	r.header = header.Clone()
+	_, err := io.Copy(io.Discard, body)
+	return err
+}
+
+// encodeMetadata returns the Upload-Metadata header value, which is a comma
+// separated list of keys and base64-encoded values
+func encodeMetadata(metadata map[string]string) (string, error) {
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		if key == "" || strings.ContainsAny(key, " ,") {
+			return "", ErrBadParameter.Withf("invalid metadata key: %q", key)
+		}
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if value := metadata[key]; value == "" {
+			pairs = append(pairs, key)
+		} else {
+			pairs = append(pairs, key+" "+base64.StdEncoding.EncodeToString([]byte(value)))
+		}
+	}
+	return strings.Join(pairs, ","), nil
+}
+
+// parseHeader returns a non-negative integer header value
+func parseHeader(header http.Header, key string) (int64, error) {
+	value, err := strconv.ParseInt(header.Get(key), 10, 64)
+	if err != nil || value < 0 {
+		return 0, ErrUnexpectedResponse.Withf("invalid %s header: %q", key, header.Get(key))
+	}
+	return value, nil
+}
+
+// retryable returns true if a request can be retried after fetching the
+// offset from the server. Client errors other than an offset conflict,
+// a locked upload, a timeout or too many requests are not retried.
+func retryable(err error) bool {
+	var code httpresponse.Err
+	var resp httpresponse.ErrResponse
+	var status int
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return false
+	case errors.As(err, &code):
+		status = int(code)
+	case errors.As(err, &resp):
+		status = resp.Code
+	default:
+		return true
+	}
+	switch status {
+	case http.StatusConflict, http.StatusLocked, http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return true
+	default:
+		return status >= 500
+	}
+}
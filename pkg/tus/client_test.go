@@ -0,0 +1,224 @@
+package tus_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	// Packages
+	client "github.com/mutablelogic/go-client"
+	tus "github.com/mutablelogic/go-client/pkg/tus"
+	assert "github.com/stretchr/testify/assert"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// SERVER
+
+// server is a minimal tus server which stores a single upload in memory.
+// When fail is set, the next PATCH stores half of the body and then fails.
+// When stall is set, every PATCH succeeds without storing the body.
+type server struct {
+	sync.Mutex
+	length   int64
+	data     bytes.Buffer
+	metadata string
+	fail     bool
+	stall    bool
+	patches  int
+	deleted  bool
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	if r.Header.Get("Tus-Resumable") != tus.Version {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/files/":
+		s.length, _ = strconv.ParseInt(r.Header.Get("Upload-Length"), 10, 64)
+		s.metadata = r.Header.Get("Upload-Metadata")
+		w.Header().Set("Location", "/files/1")
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodHead && r.URL.Path == "/files/1":
+		w.Header().Set("Upload-Offset", strconv.Itoa(s.data.Len()))
+		w.Header().Set("Upload-Length", strconv.FormatInt(s.length, 10))
+		w.WriteHeader(http.StatusOK)
+	case r.Method == http.MethodPatch && r.URL.Path == "/files/1":
+		s.patches++
+		if r.Header.Get("Content-Type") != tus.ContentType {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		if offset, _ := strconv.Atoi(r.Header.Get("Upload-Offset")); offset != s.data.Len() {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		if s.fail {
+			s.fail = false
+			io.CopyN(&s.data, r.Body, r.ContentLength/2)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if s.stall {
+			io.Copy(io.Discard, r.Body)
+			w.Header().Set("Upload-Offset", strconv.Itoa(s.data.Len()))
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		io.Copy(&s.data, r.Body)
+		w.Header().Set("Upload-Offset", strconv.Itoa(s.data.Len()))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodDelete && r.URL.Path == "/files/1":
+		s.deleted = true
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// TESTS
+
+func Test_tus_001(t *testing.T) {
+	assert := assert.New(t)
+	srv := httptest.NewServer(new(server))
+	defer srv.Close()
+
+	c, err := tus.New(srv.URL + "/files/")
+	assert.NoError(err)
+	assert.NotNil(c)
+
+	// Invalid options
+	upload := &tus.Upload{}
+	assert.Error(c.Upload(context.Background(), upload, strings.NewReader(""), tus.OptChunkSize(0)))
+	assert.Error(c.Upload(context.Background(), upload, strings.NewReader(""), tus.OptRetry(-1, 0)))
+	assert.Error(c.Upload(context.Background(), upload, strings.NewReader(""), tus.OptProgress(nil)))
+}
+
+func Test_tus_002(t *testing.T) {
+	assert := assert.New(t)
+	s := new(server)
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	c, err := tus.New(srv.URL + "/files/")
+	assert.NoError(err)
+
+	// Create and upload in chunks
+	data := bytes.Repeat([]byte("0123456789"), 1000)
+	upload, err := c.Create(context.Background(), int64(len(data)), map[string]string{"filename": "test.txt"})
+	assert.NoError(err)
+	assert.Equal(srv.URL+"/files/1", upload.URL)
+	assert.Equal("filename "+base64.StdEncoding.EncodeToString([]byte("test.txt")), s.metadata)
+
+	var last client.Progress
+	assert.NoError(c.Upload(context.Background(), upload, bytes.NewReader(data), tus.OptChunkSize(4096), tus.OptProgress(func(p client.Progress) {
+		assert.GreaterOrEqual(p.Sent, last.Sent)
+		last = p
+	})))
+	assert.Equal(int64(len(data)), upload.Offset)
+	assert.Equal(data, s.data.Bytes())
+	assert.Equal(3, s.patches)
+	assert.Equal(int64(len(data)), last.Sent)
+	assert.Equal(int64(len(data)), last.Total)
+
+	// Delete
+	assert.NoError(c.Delete(context.Background(), upload))
+	assert.True(s.deleted)
+}
+
+func Test_tus_003(t *testing.T) {
+	assert := assert.New(t)
+	s := &server{fail: true}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	c, err := tus.New(srv.URL + "/files/")
+	assert.NoError(err)
+
+	// The first request fails half way, and the upload resumes from the
+	// offset the server received
+	data := bytes.Repeat([]byte("abcdefgh"), 1000)
+	upload, err := c.Create(context.Background(), int64(len(data)), nil)
+	assert.NoError(err)
+	assert.NoError(c.Upload(context.Background(), upload, bytes.NewReader(data), tus.OptRetry(1, time.Millisecond)))
+	assert.Equal(data, s.data.Bytes())
+	assert.Equal(2, s.patches)
+}
+
+func Test_tus_004(t *testing.T) {
+	assert := assert.New(t)
+	s := new(server)
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	c, err := tus.New(srv.URL + "/files/")
+	assert.NoError(err)
+
+	// Upload part of the data, then resume from the URL
+	data := bytes.Repeat([]byte("x"), 1000)
+	upload, err := c.Create(context.Background(), int64(len(data)), nil)
+	assert.NoError(err)
+	s.data.Write(data[:400])
+
+	resumed, err := c.Get(context.Background(), upload.URL)
+	assert.NoError(err)
+	assert.Equal(int64(400), resumed.Offset)
+	assert.Equal(int64(1000), resumed.Length)
+
+	var last client.Progress
+	assert.NoError(c.Upload(context.Background(), resumed, bytes.NewReader(data), tus.OptProgress(func(p client.Progress) {
+		last = p
+	})))
+	assert.Equal(data, s.data.Bytes())
+	assert.Equal(int64(1000), last.Sent)
+	assert.Equal(int64(400), last.Offset)
+}
+
+func Test_tus_005(t *testing.T) {
+	assert := assert.New(t)
+	s := &server{fail: true}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	c, err := tus.New(srv.URL + "/files/")
+	assert.NoError(err)
+
+	// Without retries the failure is returned
+	data := bytes.Repeat([]byte("x"), 1000)
+	upload, err := c.Create(context.Background(), int64(len(data)), nil)
+	assert.NoError(err)
+	assert.Error(c.Upload(context.Background(), upload, bytes.NewReader(data), tus.OptRetry(0, 0)))
+
+	// Missing upload is not retried
+	_, err = c.Get(context.Background(), srv.URL+"/files/2")
+	assert.Error(err)
+}
+
+func Test_tus_006(t *testing.T) {
+	assert := assert.New(t)
+	s := &server{stall: true}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	c, err := tus.New(srv.URL + "/files/")
+	assert.NoError(err)
+
+	// A PATCH which does not advance the offset is retried, and then returned
+	data := bytes.Repeat([]byte("x"), 1000)
+	upload, err := c.Create(context.Background(), int64(len(data)), nil)
+	assert.NoError(err)
+	assert.Error(c.Upload(context.Background(), upload, bytes.NewReader(data), tus.OptRetry(2, 0)))
+	assert.Equal(3, s.patches)
+	assert.Equal(int64(0), upload.Offset)
+}
@@ -0,0 +1,87 @@
+package tus
+
+import (
+	"time"
+
+	// Packages
+	client "github.com/mutablelogic/go-client"
+
+	// Namespace imports
+	. "github.com/djthorpe/go-errors"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+type uploadOpts struct {
+	chunkSize int64
+	retries   int
+	delay     time.Duration
+	progress  client.ProgressCallback
+}
+
+// Opt is an option for an upload
+type Opt func(*uploadOpts) error
+
+///////////////////////////////////////////////////////////////////////////////
+// GLOBALS
+
+const (
+	defaultRetries = 3
+	defaultDelay   = time.Second
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// LIFECYCLE
+
+func applyOpts(opts ...Opt) (*uploadOpts, error) {
+	o := &uploadOpts{retries: defaultRetries, delay: defaultDelay}
+	for _, opt := range opts {
+		if err := opt(o); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// OptChunkSize sends the upload in requests of at most size bytes, for servers
+// or proxies which limit the size of a request body. By default the remainder
+// of the upload is sent in a single request.
+func OptChunkSize(size int64) Opt {
+	return func(o *uploadOpts) error {
+		if size <= 0 {
+			return ErrBadParameter.Withf("OptChunkSize: %d", size)
+		}
+		o.chunkSize = size
+		return nil
+	}
+}
+
+// OptRetry sets the number of consecutive failed requests which are retried,
+// and the delay before the first retry, which increases with each failure.
+// The default is three retries with a delay of one second.
+func OptRetry(retries int, delay time.Duration) Opt {
+	return func(o *uploadOpts) error {
+		if retries < 0 || delay < 0 {
+			return ErrBadParameter.With("OptRetry")
+		}
+		o.retries = retries
+		o.delay = delay
+		return nil
+	}
+}
+
+// OptProgress calls fn as the upload is sent, with the number of bytes sent
+// including any sent before the upload was resumed
+func OptProgress(fn client.ProgressCallback) Opt {
+	return func(o *uploadOpts) error {
+		if fn == nil {
+			return ErrBadParameter.With("OptProgress")
+		}
+		o.progress = fn
+		return nil
+	}
+}
@@ -0,0 +1,63 @@
+package client
+
+import (
+	"io"
+	"time"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// Progress reports how much of a request body has been sent, or how much of
+// a download has been received
+type Progress struct {
+	Sent    int64         // Number of bytes sent or received
+	Total   int64         // Total number of bytes, or -1 when the length is unknown
+	Offset  int64         // Number of bytes transferred before this transfer, when resuming
+	Elapsed time.Duration // Time since the transfer started
+}
+
+// ProgressCallback is called as a request body is sent or a download is received
+type ProgressCallback func(Progress)
+
+// progressReader counts the bytes read from a request body
+type progressReader struct {
+	io.ReadCloser
+	fn    ProgressCallback
+	sent  int64
+	total int64
+	start time.Time
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// LIFECYCLE
+
+func newProgressReader(r io.ReadCloser, total int64, fn ProgressCallback) *progressReader {
+	return &progressReader{ReadCloser: r, fn: fn, total: total}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Rate returns the average number of bytes transferred per second, excluding
+// any bytes transferred before a transfer was resumed
+func (p Progress) Rate() float64 {
+	if p.Elapsed <= 0 {
+		return 0
+	}
+	return float64(p.Sent-p.Offset) / p.Elapsed.Seconds()
+}
+
+// Read implements io.Reader, calling the callback after each read which
+// returns data
+func (r *progressReader) Read(b []byte) (int, error) {
+	if r.start.IsZero() {
+		r.start = time.Now()
+	}
+	n, err := r.ReadCloser.Read(b)
+	if n > 0 {
+		r.sent += int64(n)
+		r.fn(Progress{Sent: r.sent, Total: r.total, Elapsed: time.Since(r.start)})
+	}
+	return n, err
+}
@@ -1,12 +1,16 @@
 package client
 
 import (
+	"bytes"
+	"encoding/hex"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strings"
 
 	// Package imports
+	multipart "github.com/mutablelogic/go-client/pkg/multipart"
+	transport "github.com/mutablelogic/go-client/pkg/transport"
 	httpresponse "github.com/mutablelogic/go-server/pkg/httpresponse"
 )
 
@@ -19,6 +23,9 @@ type requestOpts struct {
 	textStreamCallback TextStreamCallback                          // OptTextStreamCallback
 	jsonStreamCallback JsonStreamCallback                          // OptJsonStreamCallback
 	transports         []func(http.RoundTripper) http.RoundTripper // OptReqTransport
+	progress           ProgressCallback                            // OptProgress
+	checksum           []byte                                      // OptChecksum
+	parallel           int                                         // OptParallel
 }
 
 type RequestOpt func(*requestOpts) error
@@ -114,6 +121,38 @@ func OptQuery(value url.Values) RequestOpt {
 	}
 }
 
+// OptQueryStruct adds query parameters to a request from the fields of a
+// struct, using the same tags as form encoding. Parameters are merged with
+// any existing query parameters, replacing parameters with the same name.
+func OptQueryStruct(v any, opts ...multipart.EncoderOpt) RequestOpt {
+	return func(r *requestOpts) error {
+		// Encode the struct
+		var buf bytes.Buffer
+		enc := multipart.NewFormEncoder(&buf, opts...)
+		if err := enc.Encode(v); err != nil {
+			return err
+		} else if err := enc.Close(); err != nil {
+			return err
+		}
+		values, err := url.ParseQuery(buf.String())
+		if err != nil {
+			return err
+		}
+
+		// Merge with the existing query
+		url := *r.URL
+		query := url.Query()
+		for key, value := range values {
+			query[key] = value
+		}
+		url.RawQuery = query.Encode()
+
+		// Set new query
+		r.URL = &url
+		return nil
+	}
+}
+
 // OptReqHeader sets a header value to the request
 func OptReqHeader(name, value string) RequestOpt {
 	return func(r *requestOpts) error {
@@ -122,6 +161,19 @@ func OptReqHeader(name, value string) RequestOpt {
 	}
 }
 
+// OptIfMatch makes the request conditional on the ETag of the resource, so
+// that an update fails with 412 Precondition Failed when the resource has
+// changed since it was fetched
+func OptIfMatch(etag string) RequestOpt {
+	return func(r *requestOpts) error {
+		if etag == "" {
+			return httpresponse.ErrBadRequest.With("OptIfMatch: empty etag")
+		}
+		r.Header.Set("If-Match", etag)
+		return nil
+	}
+}
+
 // OptNoTimeout disables the timeout for this request, useful for long-running
 // requests. The context can be used instead for cancelling requests
 func OptNoTimeout() RequestOpt {
@@ -131,6 +183,15 @@ func OptNoTimeout() RequestOpt {
 	}
 }
 
+// OptNoCoalesce makes a round-trip for this request, rather than sharing the
+// response of an identical request in flight when OptCoalesce is set
+func OptNoCoalesce() RequestOpt {
+	return func(r *requestOpts) error {
+		r.Request = r.Request.WithContext(transport.WithSkipCoalesce(r.Context()))
+		return nil
+	}
+}
+
 // OptTextStreamCallback is called for each event in a text stream
 func OptTextStreamCallback(fn TextStreamCallback) RequestOpt {
 	return func(r *requestOpts) error {
@@ -147,6 +208,52 @@ func OptJsonStreamCallback(fn JsonStreamCallback) RequestOpt {
 	}
 }
 
+// OptProgress calls fn as the request body is sent, or as a download is
+// received, with the number of bytes transferred and the total, which is -1
+// when the length is unknown
+func OptProgress(fn ProgressCallback) RequestOpt {
+	return func(r *requestOpts) error {
+		if fn == nil {
+			return httpresponse.ErrBadRequest.With("OptProgress: nil callback")
+		}
+		r.progress = fn
+		if r.Body == nil || r.Body == http.NoBody {
+			return nil
+		}
+		total := r.ContentLength
+		if total <= 0 {
+			total = -1
+		}
+		r.Body = newProgressReader(r.Body, total, fn)
+		return nil
+	}
+}
+
+// OptChecksum verifies a download against a hex-encoded SHA-256 checksum, in
+// addition to any digest returned by the server
+func OptChecksum(sha256 string) RequestOpt {
+	return func(r *requestOpts) error {
+		value, err := hex.DecodeString(sha256)
+		if err != nil || len(value) != 32 {
+			return httpresponse.ErrBadRequest.Withf("OptChecksum: %q", sha256)
+		}
+		r.checksum = value
+		return nil
+	}
+}
+
+// OptParallel splits a download into at most n ranged requests, which are
+// made concurrently when the server supports ranges
+func OptParallel(n int) RequestOpt {
+	return func(r *requestOpts) error {
+		if n < 1 {
+			return httpresponse.ErrBadRequest.Withf("OptParallel: %d", n)
+		}
+		r.parallel = n
+		return nil
+	}
+}
+
 // OptReqTransport inserts a transport middleware for this request only.
 // Multiple calls stack in order; the first call becomes the outermost layer.
 func OptReqTransport(fn func(http.RoundTripper) http.RoundTripper) RequestOpt {
@@ -6,11 +6,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	client "github.com/mutablelogic/go-client"
+	types "github.com/mutablelogic/go-server/pkg/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -298,6 +300,49 @@ func Test_OptQuery_SetsQueryParameters(t *testing.T) {
 	assert.Equal(t, "1", parsed.Get("n"))
 }
 
+func Test_OptQueryStruct_MergesQueryParameters(t *testing.T) {
+	var capturedQuery string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		capturedQuery = r.URL.RawQuery
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c, err := client.New(client.OptEndpoint(srv.URL))
+	require.NoError(t, err)
+
+	type query struct {
+		Name    string     `json:"name"`
+		Limit   *int       `json:"limit,omitempty"`
+		Offset  *int       `json:"offset,omitempty"`
+		Active  bool       `json:"active"`
+		Tags    []string   `json:"tag,omitempty"`
+		Since   time.Time  `json:"since"`
+		Until   *time.Time `json:"until,omitempty"`
+		Ignored string     `json:"-"`
+	}
+	limit := 10
+	since := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, c.Do(client.MethodGet, nil,
+		client.OptQuery(url.Values{"name": {"old"}, "page": {"2"}}),
+		client.OptQueryStruct(query{Name: "test", Limit: &limit, Active: true, Tags: []string{"a", "b"}, Since: since, Ignored: "x"}),
+	))
+
+	parsed, err := url.ParseQuery(capturedQuery)
+	require.NoError(t, err)
+	assert.Equal(t, url.Values{
+		"name":   {"test"},
+		"page":   {"2"},
+		"limit":  {"10"},
+		"active": {"true"},
+		"tag":    {"a", "b"},
+		"since":  {"2024-01-02T03:04:05Z"},
+	}, parsed)
+
+	// Structs are required
+	assert.Error(t, c.Do(client.MethodGet, nil, client.OptQueryStruct("not a struct")))
+}
+
 ///////////////////////////////////////////////////////////////////////////////
 // OptReqHeader
 
@@ -379,6 +424,58 @@ func Test_OptReqTransport_PerRequestMiddlewareCalled(t *testing.T) {
 	assert.True(t, called.Load(), "middleware should fire when passed via OptReqTransport")
 }
 
+///////////////////////////////////////////////////////////////////////////////
+// OptProgress
+
+func Test_OptProgress_NilErrors(t *testing.T) {
+	srv, _ := newTestServer(t)
+	defer srv.Close()
+
+	c, err := client.New(client.OptEndpoint(srv.URL))
+	require.NoError(t, err)
+	err = c.Do(client.MethodGet, nil, client.OptProgress(nil))
+	assert.Error(t, err)
+}
+
+func Test_OptProgress_KnownLength(t *testing.T) {
+	srv, _ := newTestServer(t)
+	defer srv.Close()
+
+	c, err := client.New(client.OptEndpoint(srv.URL))
+	require.NoError(t, err)
+
+	body := strings.Repeat("x", 100*1024)
+	req, err := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader(body))
+	require.NoError(t, err)
+
+	var last client.Progress
+	require.NoError(t, c.Request(req, nil, client.OptProgress(func(p client.Progress) {
+		assert.GreaterOrEqual(t, p.Sent, last.Sent)
+		last = p
+	})))
+	assert.Equal(t, int64(len(body)), last.Sent)
+	assert.Equal(t, int64(len(body)), last.Total)
+	assert.GreaterOrEqual(t, last.Rate(), float64(0))
+}
+
+func Test_OptProgress_UnknownLength(t *testing.T) {
+	srv, _ := newTestServer(t)
+	defer srv.Close()
+
+	c, err := client.New(client.OptEndpoint(srv.URL))
+	require.NoError(t, err)
+
+	payload, err := client.NewJSONRequest(map[string]string{"name": "value"})
+	require.NoError(t, err)
+
+	var last client.Progress
+	require.NoError(t, c.Do(payload, nil, client.OptProgress(func(p client.Progress) {
+		last = p
+	})))
+	assert.Equal(t, int64(len(`{"name":"value"}`)+1), last.Sent)
+	assert.Equal(t, int64(-1), last.Total)
+}
+
 ///////////////////////////////////////////////////////////////////////////////
 // OptTextStreamCallback
 
@@ -429,7 +526,7 @@ func Test_OptJsonStreamCallback_EventsDelivered(t *testing.T) {
 	out := new(jsonStreamEvent)
 	var values []int
 	err = c.Do(
-		client.NewRequestEx(http.MethodGet, client.ContentTypeJsonStream),
+		client.NewRequestEx(http.MethodGet, types.ContentTypeJSONStream),
 		out,
 		client.OptJsonStreamCallback(func(v json.RawMessage) error {
 			var event jsonStreamEvent
@@ -458,7 +555,7 @@ func Test_OptJsonStreamCallback_EOFStopsCleanly(t *testing.T) {
 	out := new(jsonStreamEvent)
 	var count int
 	err = c.Do(
-		client.NewRequestEx(http.MethodGet, client.ContentTypeJsonStream),
+		client.NewRequestEx(http.MethodGet, types.ContentTypeJSONStream),
 		out,
 		client.OptJsonStreamCallback(func(v json.RawMessage) error {
 			count++
@@ -1,12 +1,16 @@
 package client
 
 import (
+	"encoding/json"
 	"io"
+	"iter"
+	"net/http"
 	"sync"
 
 	// Packages
 	"github.com/mutablelogic/go-client/pkg/multipart"
-	"github.com/mutablelogic/go-server/pkg/types"
+	httpresponse "github.com/mutablelogic/go-server/pkg/httpresponse"
+	types "github.com/mutablelogic/go-server/pkg/types"
 )
 
 ///////////////////////////////////////////////////////////////////////////////
@@ -19,6 +23,8 @@ type streamingRequest struct {
 	accept   string
 	mimetype string
 	reader   *io.PipeReader
+	done     chan struct{}
+	once     sync.Once
 	wg       sync.WaitGroup
 }
 
@@ -31,41 +37,75 @@ type streamingRequest struct {
 //
 // The encoding happens in a background goroutine that writes to a pipe while the HTTP
 // client reads from the other end. Encoding errors are propagated via the pipe.
-func NewStreamingMultipartRequest(payload any, accept string) (Payload, error) {
+func NewStreamingMultipartRequest(payload any, accept string, opts ...multipart.EncoderOpt) (Payload, error) {
+	// Create the encoder - we need the content type before starting the goroutine
 	pr, pw := io.Pipe()
+	enc := multipart.NewMultipartEncoder(pw, opts...)
+	return newStreamingRequest(http.MethodPost, accept, enc.ContentType(), pr, pw, func(<-chan struct{}) error {
+		// Close the encoder to write the final boundary
+		err := enc.Encode(payload)
+		if closeErr := enc.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+		return err
+	}), nil
+}
 
-	// Create the encoder - we need the content type before starting the goroutine
-	enc := multipart.NewMultipartEncoder(pw)
-	mimetype := enc.ContentType()
+// NewStreamingJSONRequest returns a new request with a JSON payload which
+// defaults to POST, and which streams the encoded data rather than buffering
+// it in memory. The accept parameter is the accepted mime-type of the response.
+func NewStreamingJSONRequest(payload any, accept string) (Payload, error) {
+	return NewStreamingJSONRequestEx(http.MethodPost, payload, accept)
+}
 
-	req := &streamingRequest{
-		method:   "POST",
-		accept:   accept,
-		mimetype: mimetype,
-		reader:   pr,
+// NewStreamingJSONRequestEx returns a new request with a JSON payload and
+// method, which streams the encoded data rather than buffering it in memory.
+// The accept parameter is the accepted mime-type of the response.
+func NewStreamingJSONRequestEx(method string, payload any, accept string) (Payload, error) {
+	pr, pw := io.Pipe()
+	return newStreamingRequest(method, accept, types.ContentTypeJSON, pr, pw, func(<-chan struct{}) error {
+		return json.NewEncoder(pw).Encode(payload)
+	}), nil
+}
+
+// NewNDJSONRequest returns a new request which defaults to POST, with a
+// newline-delimited JSON payload of the values from an iterator. Each value
+// is encoded as it is read from the iterator, so the payload is never held
+// in memory. Iteration stops if the request fails. The accept parameter is
+// the accepted mime-type of the response.
+func NewNDJSONRequest[T any](seq iter.Seq[T], accept string) (Payload, error) {
+	if seq == nil {
+		return nil, httpresponse.ErrBadRequest.With("NewNDJSONRequest: missing iterator")
 	}
+	return newNDJSONRequest(accept, func(<-chan struct{}) iter.Seq[T] {
+		return seq
+	}), nil
+}
 
-	// Encode in a goroutine - writes to pipe while HTTP client reads
-	req.wg.Add(1)
-	go func() {
-		defer req.wg.Done()
-		var err error
-		defer func() {
-			// Close the encoder first to write the final boundary
-			if closeErr := enc.Close(); closeErr != nil && err == nil {
-				err = closeErr
-			}
-			// Close the pipe writer, propagating any error to the reader
-			if err != nil {
-				pw.CloseWithError(err)
-			} else {
-				pw.Close()
+// NewNDJSONChannelRequest returns a new request which defaults to POST, with
+// a newline-delimited JSON payload of the values received from a channel.
+// The payload ends when the channel is closed, or when the payload is closed
+// by the client, so a failed request does not wait for the channel. The
+// channel is not drained, so the sender should also stop on a cancelled
+// context.
+func NewNDJSONChannelRequest[T any](ch <-chan T, accept string) (Payload, error) {
+	if ch == nil {
+		return nil, httpresponse.ErrBadRequest.With("NewNDJSONChannelRequest: missing channel")
+	}
+	return newNDJSONRequest(accept, func(done <-chan struct{}) iter.Seq[T] {
+		return func(yield func(T) bool) {
+			for {
+				select {
+				case <-done:
+					return
+				case value, ok := <-ch:
+					if !ok || !yield(value) {
+						return
+					}
+				}
 			}
-		}()
-		err = enc.Encode(payload)
-	}()
-
-	return req, nil
+		}
+	}), nil
 }
 
 ///////////////////////////////////////////////////////////////////////////////
@@ -100,6 +140,53 @@ func (req *streamingRequest) Read(b []byte) (n int, err error) {
 // goroutine leaks.
 func (req *streamingRequest) Close() error {
 	err := req.reader.Close()
+	req.once.Do(func() { close(req.done) })
 	req.wg.Wait()
 	return err
 }
+
+///////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// newStreamingRequest returns a payload which reads from the pipe, while the
+// encode function writes to the pipe in a background goroutine. Encoding errors
+// are propagated to the reader via the pipe. The done channel passed to the
+// encode function is closed when the payload is closed.
+func newStreamingRequest(method, accept, mimetype string, pr *io.PipeReader, pw *io.PipeWriter, encode func(done <-chan struct{}) error) *streamingRequest {
+	req := &streamingRequest{
+		method:   method,
+		accept:   accept,
+		mimetype: mimetype,
+		reader:   pr,
+		done:     make(chan struct{}),
+	}
+
+	// Encode in a goroutine - writes to pipe while HTTP client reads
+	req.wg.Add(1)
+	go func() {
+		defer req.wg.Done()
+		if err := encode(req.done); err != nil {
+			pw.CloseWithError(err)
+		} else {
+			pw.Close()
+		}
+	}()
+
+	return req
+}
+
+// newNDJSONRequest returns a payload which encodes each value from the
+// sequence as a line of JSON. The sequence is passed the done channel of the
+// payload.
+func newNDJSONRequest[T any](accept string, values func(done <-chan struct{}) iter.Seq[T]) *streamingRequest {
+	pr, pw := io.Pipe()
+	return newStreamingRequest(http.MethodPost, accept, types.ContentTypeJSONStream, pr, pw, func(done <-chan struct{}) error {
+		enc := json.NewEncoder(pw)
+		for value := range values(done) {
+			if err := enc.Encode(value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}